@@ -0,0 +1,36 @@
+package jsonobj
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// aliasesTagKey is the struct tag listing former names of a known field,
+// which FromJSON decodes into the field, so renamed fields can still be
+// decoded from old objects:
+//
+//	Path string `json:"path" jsonobj_aliases:"slug,url"`
+//
+// Matched aliases are consumed rather than retained, so ToJSON only outputs
+// the field's current name. If the input has multiple matching keys, the
+// current name is preferred, followed by the last alias in the input.
+// Aliases must match exactly, and must not conflict with other fields.
+const aliasesTagKey = "jsonobj_aliases"
+
+// fieldAliases returns the aliases of the struct field ft.
+func fieldAliases(ft reflect.StructField) []string {
+	tag := ft.Tag.Get(aliasesTagKey)
+	if tag == "" {
+		return nil
+	}
+
+	return slices.DeleteFunc(strings.Split(tag, ","), func(alias string) bool {
+		return alias == ""
+	})
+}
+
+// isAlias returns whether key is an alias of the field t.
+func (t jsonTag) isAlias(key string) bool {
+	return slices.Contains(t.aliases, key)
+}
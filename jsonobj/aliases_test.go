@@ -0,0 +1,102 @@
+package jsonobj
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type aliasS struct {
+	raw Retain
+
+	Path  string `json:"path" jsonobj_aliases:"slug,url"`
+	Title string `json:"title"`
+}
+
+func (s *aliasS) UnmarshalJSON(data []byte) error {
+	return s.raw.FromJSON(data, s)
+}
+
+func (s aliasS) MarshalJSON() ([]byte, error) {
+	return s.raw.ToJSON(s)
+}
+
+var _ = MustRetainable(&aliasS{})
+
+func TestAliases(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantPath string
+		want     string
+	}{
+		{
+			name:     "current name",
+			input:    `{"path":"/a","title":"t"}`,
+			wantPath: "/a",
+			want:     `{"path":"/a","title":"t"}`,
+		},
+		{
+			name:     "alias",
+			input:    `{"slug":"/a","title":"t","x":1}`,
+			wantPath: "/a",
+			want:     `{"title":"t","x":1,"path":"/a"}`,
+		},
+		{
+			name:     "current name preferred",
+			input:    `{"path":"/a","slug":"/b","url":"/c"}`,
+			wantPath: "/a",
+			want:     `{"path":"/a","title":""}`,
+		},
+		{
+			name:     "last alias preferred",
+			input:    `{"url":"/c","slug":"/b"}`,
+			wantPath: "/b",
+			want:     `{"path":"/b","title":""}`,
+		},
+		{
+			name:     "alias preferred over case-insensitive match",
+			input:    `{"slug":"/b","Path":"/c"}`,
+			wantPath: "/b",
+			want:     `{"path":"/b","title":""}`,
+		},
+		{
+			name:  "aliases match exactly",
+			input: `{"Slug":"/b"}`,
+			want:  `{"Slug":"/b","path":"","title":""}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s aliasS
+			require.NoError(t, json.Unmarshal([]byte(tt.input), &s))
+			assert.Equal(t, tt.wantPath, s.Path)
+			assert.Equal(t, tt.want, mustMarshal(t, s))
+		})
+	}
+}
+
+func TestAliases_Trace(t *testing.T) {
+	var (
+		buf bytes.Buffer
+		s   aliasS
+	)
+	require.NoError(t, s.raw.FromJSON([]byte(`{"slug":"/a","url":"/b"}`), &s, WithDecodeTrace(&buf)))
+	assert.Equal(t, `jsonobj: key "slug": known field Path (alias)
+jsonobj: key "url": known field Path (alias)
+`, buf.String())
+}
+
+func TestAliases_Retainable(t *testing.T) {
+	type conflict struct {
+		S
+
+		Path string `json:"path" jsonobj_aliases:"name"`
+	}
+
+	assert.EqualError(t, Retainable(&conflict{}), `*jsonobj.conflict not Retainable: duplicate JSON field "name"`)
+}
@@ -0,0 +1,128 @@
+package jsonobj
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Cache memoizes decoded objects by the content of their input, so repeated
+// decodes of identical JSON avoid parsing. Cached objects implement Cloner,
+// and objects returned by the cache are copies made by Clone, so they can be
+// modified independently.
+//
+// A Cache is safe for concurrent use.
+type Cache struct {
+	maxEntries int
+
+	mu      sync.Mutex
+	lru     *list.List // of *cacheEntry, most recently used first
+	entries map[cacheKey]*list.Element
+}
+
+// Cloner is implemented by objects that can be cached by Cache.
+type Cloner interface {
+	RetainHolder
+
+	// Clone returns a deep copy of the object, including its retained
+	// fields, that shares no mutable state with the object, such as:
+	//
+	//	func (o *Obj) Clone() jsonobj.RetainHolder {
+	//		c := *o
+	//		c.raw = o.raw.Clone()
+	//		c.Tags = slices.Clone(o.Tags)
+	//		return &c
+	//	}
+	Clone() RetainHolder
+}
+
+type cacheKey struct {
+	hash [sha256.Size]byte
+	typ  reflect.Type
+}
+
+type cacheEntry struct {
+	key cacheKey
+	obj Cloner
+}
+
+// NewCache returns a Cache that holds at most maxEntries objects, evicting the
+// least recently used object when full. maxEntries must be positive.
+func NewCache(maxEntries int) *Cache {
+	if maxEntries <= 0 {
+		panic(fmt.Sprintf("jsonobj: cache must have positive max entries, got %v", maxEntries))
+	}
+
+	return &Cache{
+		maxEntries: maxEntries,
+		lru:        list.New(),
+		entries:    make(map[cacheKey]*list.Element),
+	}
+}
+
+// Unmarshal returns a copy of the object decoded from data into a new object
+// from newObj. Objects are cached by the SHA-256 hash of data and the type of
+// the object, and decode errors are not cached.
+//
+// newObj must return objects that decode the same way for the same input,
+// since only the first decode for each input is used.
+func (c *Cache) Unmarshal(data []byte, newObj func() Cloner) (RetainHolder, error) {
+	obj := newObj()
+	key := cacheKey{
+		hash: sha256.Sum256(data),
+		typ:  reflect.TypeOf(obj),
+	}
+
+	if cached, ok := c.get(key); ok {
+		return cached.Clone(), nil
+	}
+
+	if err := obj.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+
+	// The caller gets a copy, so it can't modify the cached object.
+	c.add(key, obj)
+	return obj.Clone(), nil
+}
+
+func (c *Cache) get(key cacheKey) (Cloner, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*cacheEntry).obj, true
+}
+
+func (c *Cache) add(key cacheKey, obj Cloner) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		// Decoded concurrently by another caller.
+		c.lru.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, obj: obj})
+	if c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// Len returns the number of cached objects.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lru.Len()
+}
@@ -0,0 +1,116 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingS struct {
+	S
+
+	decodes *int
+}
+
+func (s *countingS) UnmarshalJSON(data []byte) error {
+	*s.decodes++
+	return s.S.UnmarshalJSON(data)
+}
+
+func (s *countingS) Clone() RetainHolder {
+	c := *s
+	c.raw = s.raw.Clone()
+	return &c
+}
+
+type cloneS struct {
+	S
+}
+
+func (s *cloneS) Clone() RetainHolder {
+	c := *s
+	c.raw = s.raw.Clone()
+	return &c
+}
+
+func TestCache(t *testing.T) {
+	var decodes int
+	newObj := func() Cloner {
+		return &countingS{decodes: &decodes}
+	}
+
+	c := NewCache(2)
+	first, err := c.Unmarshal([]byte(`{"name": "a", "x": 1}`), newObj)
+	require.NoError(t, err)
+	assert.Equal(t, 1, decodes)
+
+	// Modifying a returned object doesn't affect the cache.
+	first.(*countingS).Name = "modified"
+	first.(*countingS).raw.SetUnknown("x", json.RawMessage(`2`))
+
+	second, err := c.Unmarshal([]byte(`{"name": "a", "x": 1}`), newObj)
+	require.NoError(t, err)
+	assert.Equal(t, 1, decodes, "identical input should be cached")
+	assert.Equal(t, `{"name":"a","x":1}`, mustMarshal(t, &second.(*countingS).S))
+	assert.Equal(t, `{"name":"modified","x":2}`, mustMarshal(t, &first.(*countingS).S))
+
+	_, err = c.Unmarshal([]byte(`{"name": "b"}`), newObj)
+	require.NoError(t, err)
+	_, err = c.Unmarshal([]byte(`{"name": "c"}`), newObj)
+	require.NoError(t, err)
+	assert.Equal(t, 3, decodes)
+	assert.Equal(t, 2, c.Len())
+
+	// "a" was least recently used, so it's evicted.
+	_, err = c.Unmarshal([]byte(`{"name": "a", "x": 1}`), newObj)
+	require.NoError(t, err)
+	assert.Equal(t, 4, decodes)
+	_, err = c.Unmarshal([]byte(`{"name": "c"}`), newObj)
+	require.NoError(t, err)
+	assert.Equal(t, 4, decodes)
+}
+
+func TestCache_TypesAndErrors(t *testing.T) {
+	c := NewCache(10)
+	input := []byte(`{"name": "a"}`)
+
+	s, err := c.Unmarshal(input, func() Cloner { return &cloneS{} })
+	require.NoError(t, err)
+	assert.IsType(t, &cloneS{}, s)
+
+	var decodes int
+	cs, err := c.Unmarshal(input, func() Cloner { return &countingS{decodes: &decodes} })
+	require.NoError(t, err)
+	assert.IsType(t, &countingS{}, cs, "objects should be cached by type")
+
+	_, err = c.Unmarshal([]byte(`{"name": 1}`), func() Cloner { return &cloneS{} })
+	assert.Error(t, err)
+	assert.Equal(t, 2, c.Len(), "errors should not be cached")
+
+	assert.Panics(t, func() { NewCache(0) })
+}
+
+func TestCache_Concurrent(t *testing.T) {
+	c := NewCache(1)
+	inputs := []string{`{"name": "a", "v": [1]}`, `{"name": "b"}`}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				input := inputs[j%len(inputs)]
+				obj, err := c.Unmarshal([]byte(input), func() Cloner { return &cloneS{} })
+				if !assert.NoError(t, err) {
+					return
+				}
+				obj.(*cloneS).raw.SetUnknown("v", json.RawMessage(`2`))
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,51 @@
+package jsonobj
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// ToJSONCanonical is similar to ToJSON, but outputs a canonical form of the
+// object that's suitable for hashing or signing: two objects with the same
+// fields and values have the same output, regardless of key order and
+// formatting. Keys of all objects (including nested and retained objects)
+// are sorted lexicographically by their bytes, and there's no whitespace.
+//
+// This is sorted keys rather than RFC 8785 (JSON Canonicalization Scheme):
+// numbers are output as in the input (so 1 and 1.0 differ), and strings are
+// re-encoded by encoding/json with minimal escaping (HTML characters are not
+// escaped), with invalid UTF-8 replaced by U+FFFD.
+//
+// Options that format the output (WithEnvelopeKey, WithIndent,
+// WithTrailingNewline and WithOutputSchema) and key ordering options are
+// not used.
+//
+// opts override the configured options for this call only, see Configure.
+func (r *Retain) ToJSONCanonical(obj any, opts ...Option) ([]byte, error) {
+	if len(opts) > 0 {
+		rc := *r
+		rc.opts = r.opts.with(opts)
+		return rc.ToJSONCanonical(obj)
+	}
+
+	keys, all, err := r.outputFields("ToJSONCanonical", obj)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := r.marshalOrdered(keys, all)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(out))
+	dec.UseNumber()
+
+	var decoded any
+	if err := dec.Decode(&decoded); err != nil {
+		return nil, err
+	}
+
+	// encoding/json sorts map keys.
+	return marshalJSON(decoded, false /* escapeHTML */)
+}
@@ -0,0 +1,48 @@
+package jsonobj
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetain_ToJSONCanonical(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		opts  []Option
+	}{
+		{
+			name:  "input order",
+			input: `{"z": {"b": [2, {"y": 1, "x": 0}], "a": 1}, "name": "<a&b>", "m": 1.50}`,
+		},
+		{
+			name:  "different order and whitespace",
+			input: "{\n  \"m\": 1.50,\n  \"name\": \"\\u003ca\\u0026b\\u003e\",\n  \"z\": {\"a\": 1, \"b\": [2, {\"x\": 0, \"y\": 1}]}\n}",
+		},
+		{
+			name:  "formatting options are not used",
+			input: `{"name": "<a&b>", "m": 1.50, "z": {"a": 1, "b": [2, {"x": 0, "y": 1}]}}`,
+			opts:  []Option{WithIndent("", "  "), WithTrailingNewline(), WithHashOrder(), WithEnvelopeKey("data")},
+		},
+	}
+
+	const want = `{"m":1.50,"name":"<a&b>","z":{"a":1,"b":[2,{"x":0,"y":1}]}}`
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s S
+			require.NoError(t, s.raw.FromJSON([]byte(tt.input), &s))
+
+			got, err := s.raw.ToJSONCanonical(&s, tt.opts...)
+			require.NoError(t, err)
+			assert.Equal(t, want, string(got))
+		})
+	}
+}
+
+func TestRetain_ToJSONCanonical_Errors(t *testing.T) {
+	var r Retain
+	_, err := r.ToJSONCanonical("str")
+	assert.EqualError(t, err, "ToJSONCanonical requires a struct, got string")
+}
@@ -0,0 +1,96 @@
+package jsonobj
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// WithChangeTracking snapshots the known fields decoded by FromJSON, so that
+// ToJSONChanges can output only the known fields that have changed since.
+func WithChangeTracking() Option {
+	return func(o *options) {
+		o.trackChanges = true
+	}
+}
+
+// ToJSONChanges is similar to ToJSON, but only outputs the known fields whose
+// encoded values differ from the snapshot taken by the last FromJSON, along
+// with all retained fields, including fields added using SetUnknown. It
+// requires WithChangeTracking.
+//
+// Known fields are compared using their encoded JSON, so a field that's set
+// back to its original value isn't output. Fields that ToJSON omits (such as
+// omitempty fields set to an empty value) aren't output either, so a removed
+// field can't be distinguished from an unchanged field.
+//
+// opts override the configured options for this call only, see Configure.
+func (r *Retain) ToJSONChanges(obj any, opts ...Option) ([]byte, error) {
+	if len(opts) > 0 {
+		rc := *r
+		rc.opts = r.opts.with(opts)
+		return rc.ToJSONChanges(obj)
+	}
+
+	if r.snapshot == nil {
+		return nil, errors.New("ToJSONChanges requires WithChangeTracking")
+	}
+	if isNilObj(obj) {
+		return nil, errors.New("ToJSONChanges requires a non-nil struct")
+	}
+	rv, ok := ensureStruct(obj, false /* requirePtr */)
+	if !ok {
+		return nil, fmt.Errorf("ToJSONChanges requires a struct, got %T", obj)
+	}
+
+	current, err := r.knownFieldValues(rv)
+	if err != nil {
+		return nil, err
+	}
+
+	rc := *r
+	rc.unchanged = make(map[string]struct{})
+	for k, v := range current {
+		if prev, ok := r.snapshot[k]; ok && bytes.Equal(prev, v) {
+			rc.unchanged[k] = struct{}{}
+		}
+	}
+	return rc.ToJSON(obj)
+}
+
+// snapshotKnown records the encoded known fields of rv if change tracking
+// is enabled, see WithChangeTracking.
+func (r *Retain) snapshotKnown(rv reflect.Value) error {
+	r.snapshot = nil
+	if !r.opts.trackChanges {
+		return nil
+	}
+
+	snapshot, err := r.knownFieldValues(rv)
+	if err != nil {
+		return err
+	}
+	r.snapshot = snapshot
+	return nil
+}
+
+// knownFieldValues returns the encoded values of the known fields of rv
+// that are output by ToJSON.
+func (r *Retain) knownFieldValues(rv reflect.Value) (map[string]json.RawMessage, error) {
+	known := make(map[string]any)
+	if err := r.addKnownFields(known, rv); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]json.RawMessage, len(known))
+	for k, v := range known {
+		data, err := r.marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %w", k, err)
+		}
+		values[k] = data
+	}
+	return values, nil
+}
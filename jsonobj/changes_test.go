@@ -0,0 +1,102 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetain_ToJSONChanges(t *testing.T) {
+	type changesS struct {
+		raw Retain
+
+		Name  string   `json:"name"`
+		Count int      `json:"count,omitempty"`
+		Tags  []string `json:"tags"`
+	}
+
+	const input = `{"name": "foo", "count": 1, "tags": ["a"], "x": 1}`
+
+	tests := []struct {
+		name   string
+		modify func(s *changesS)
+		want   string
+	}{
+		{
+			name:   "unchanged",
+			modify: func(s *changesS) {},
+			want:   `{"x":1}`,
+		},
+		{
+			name: "changed fields",
+			modify: func(s *changesS) {
+				s.Name = "bar"
+				s.Tags = append(s.Tags, "b")
+			},
+			want: `{"name":"bar","tags":["a","b"],"x":1}`,
+		},
+		{
+			name: "set back to original value",
+			modify: func(s *changesS) {
+				s.Name = "bar"
+				s.Name = "foo"
+				s.Tags = []string{"a"}
+			},
+			want: `{"x":1}`,
+		},
+		{
+			name: "omitted field",
+			modify: func(s *changesS) {
+				s.Count = 0
+			},
+			want: `{"x":1}`,
+		},
+		{
+			name: "new unknown",
+			modify: func(s *changesS) {
+				s.Count = 2
+				s.raw.SetUnknown("y", json.RawMessage(`true`))
+			},
+			want: `{"count":2,"x":1,"y":true}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s changesS
+			s.raw.Configure(WithChangeTracking())
+			require.NoError(t, s.raw.FromJSON([]byte(input), &s))
+
+			tt.modify(&s)
+			got, err := s.raw.ToJSONChanges(&s)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+
+			// ToJSON is unaffected by change tracking.
+			full, err := s.raw.ToJSON(&s)
+			require.NoError(t, err)
+			assert.Contains(t, string(full), `"name":`)
+
+			// The snapshot is copied by Clone.
+			c := s.raw.Clone()
+			got, err = c.ToJSONChanges(&s)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestRetain_ToJSONChanges_Errors(t *testing.T) {
+	var s S
+	require.NoError(t, s.raw.FromJSON([]byte(`{"name": "foo"}`), &s))
+	_, err := s.raw.ToJSONChanges(&s)
+	assert.EqualError(t, err, "ToJSONChanges requires WithChangeTracking")
+
+	require.NoError(t, s.raw.FromJSON([]byte(`{"name": "foo"}`), &s, WithChangeTracking()))
+	_, err = s.raw.ToJSONChanges("str")
+	assert.EqualError(t, err, "ToJSONChanges requires a struct, got string")
+	_, err = s.raw.ToJSONChanges((*S)(nil))
+	assert.EqualError(t, err, "ToJSONChanges requires a non-nil struct")
+}
@@ -0,0 +1,97 @@
+package jsonobj
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// WithChecksumField adds a checksum of the object to the output of ToJSON
+// in the field name, and verifies the checksum in FromJSON, which fails if
+// the checksum is missing or doesn't match. The checksum field is removed
+// from the retained fields after it's verified.
+//
+// The checksum is the hex-encoded SHA-256 hash of the canonical JSON of all
+// other fields. The canonical JSON is the output of encoding/json after
+// decoding the object into an any (with numbers kept as json.Number), so
+// it has no insignificant whitespace, object keys are sorted at every level,
+// and numbers are kept as they were written.
+func WithChecksumField(name string) Option {
+	return func(o *options) {
+		o.checksumField = name
+	}
+}
+
+func (r *Retain) verifyChecksum() error {
+	name := r.opts.checksumField
+	if name == "" {
+		return nil
+	}
+
+	checksumJSON, ok := r.raw[name]
+	if !ok {
+		return fmt.Errorf("missing checksum field %q", name)
+	}
+
+	var want string
+	if err := json.Unmarshal(checksumJSON, &want); err != nil {
+		return fmt.Errorf("checksum field %q: %w", name, err)
+	}
+
+	delete(r.raw, name)
+	got, err := checksum(r.raw)
+	if err != nil {
+		return err
+	}
+
+	if got != want {
+		return fmt.Errorf("checksum field %q mismatch: got %v, computed %v", name, want, got)
+	}
+	return nil
+}
+
+func (r *Retain) addChecksum(all map[string]any) error {
+	name := r.opts.checksumField
+	if name == "" {
+		return nil
+	}
+
+	delete(all, name)
+	sum, err := checksum(all)
+	if err != nil {
+		return err
+	}
+
+	all[name] = sum
+	return nil
+}
+
+func checksum[V any](fields map[string]V) (string, error) {
+	canonical, err := canonicalJSON(fields)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// canonicalJSON marshals v with sorted keys at every level
+// and no insignificant whitespace.
+func canonicalJSON(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var decoded any
+	if err := dec.Decode(&decoded); err != nil {
+		return nil, err
+	}
+	return json.Marshal(decoded)
+}
@@ -0,0 +1,58 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithChecksumField(t *testing.T) {
+	newS := func() *S {
+		var s S
+		s.raw.Configure(WithChecksumField("_checksum"))
+		return &s
+	}
+
+	s := newS()
+	s.Name = "foo"
+	s.raw.SetUnknown("obj", json.RawMessage(`{"b": 1, "a": [1.50, "x"]}`))
+	signed := mustMarshal(t, s)
+
+	var fields map[string]any
+	require.NoError(t, json.Unmarshal([]byte(signed), &fields))
+	// sha256 of {"name":"foo","obj":{"a":[1.50,"x"],"b":1}}
+	assert.Equal(t, "e33d0994584263b5a6228ab1422221d191077edf2335d4c9db1210e93b080927", fields["_checksum"])
+
+	t.Run("round trip", func(t *testing.T) {
+		s2 := newS()
+		require.NoError(t, json.Unmarshal([]byte(signed), s2))
+		assert.Equal(t, "foo", s2.Name)
+
+		_, ok := s2.raw.GetUnknown("_checksum")
+		assert.False(t, ok, "checksum should not be retained")
+		assert.JSONEq(t, signed, mustMarshal(t, s2))
+	})
+
+	t.Run("reformatted input", func(t *testing.T) {
+		input := `{"obj": {"a": [1.50, "x"], "b": 1}, "_checksum": "e33d0994584263b5a6228ab1422221d191077edf2335d4c9db1210e93b080927", "name": "foo"}`
+		require.NoError(t, json.Unmarshal([]byte(input), newS()))
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		input := `{"obj": {"a": [1.5, "x"], "b": 1}, "_checksum": "e33d0994584263b5a6228ab1422221d191077edf2335d4c9db1210e93b080927", "name": "foo"}`
+		err := json.Unmarshal([]byte(input), newS())
+		assert.ErrorContains(t, err, `checksum field "_checksum" mismatch`)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		err := json.Unmarshal([]byte(`{"name": "foo"}`), newS())
+		assert.EqualError(t, err, `missing checksum field "_checksum"`)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		err := json.Unmarshal([]byte(`{"_checksum": 1}`), newS())
+		assert.ErrorContains(t, err, `checksum field "_checksum": json: cannot unmarshal number`)
+	})
+}
@@ -0,0 +1,48 @@
+package jsonobj
+
+import (
+	"maps"
+	"slices"
+)
+
+// Clone returns a deep copy of r, including retained fields, their order and
+// the configured options. The copy shares no mutable state owned by r, so the
+// copy and r can be modified independently, including concurrently.
+//
+// Values passed to options are shared rather than copied, including the
+// WithShadowStruct struct (which FromJSON writes to) and the WithDecodeTrace
+// writer, so the copy and r must not use these concurrently.
+//
+// Clone is the supported way to fork an object with retained fields, such as
+// a template that's decoded once and then modified per request:
+//
+//	obj := template
+//	obj.raw = template.raw.Clone()
+//
+// Known fields of the object are not copied by Clone.
+func (r *Retain) Clone() Retain {
+	c := *r
+	c.opts = r.opts.with(nil)
+	c.raw = cloneRawMap(r.raw)
+	c.envelope = cloneRawMap(r.envelope)
+	c.envelopeOrder = slices.Clone(r.envelopeOrder)
+	c.consumed = maps.Clone(r.consumed)
+	c.stripped = maps.Clone(r.stripped)
+	c.projected = maps.Clone(r.projected)
+	c.presence = slices.Clone(r.presence)
+	c.order = slices.Clone(r.order)
+	c.snapshot = cloneRawMap(r.snapshot)
+	return c
+}
+
+func cloneRawMap[K comparable, V ~[]byte](m map[K]V) map[K]V {
+	if m == nil {
+		return nil
+	}
+
+	c := make(map[K]V, len(m))
+	for k, v := range m {
+		c[k] = slices.Clone(v)
+	}
+	return c
+}
@@ -0,0 +1,27 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetain_Clone(t *testing.T) {
+	var template S
+	template.raw.Configure(WithStripPrefix("x_"))
+	require.NoError(t, json.Unmarshal([]byte(`{"z": {"k": "v"}, "name": "foo", "x_a": [1]}`), &template))
+
+	obj := template
+	obj.raw = template.raw.Clone()
+	assert.Equal(t, mustMarshal(t, &template), mustMarshal(t, &obj))
+
+	z, _ := obj.raw.GetUnknown("z")
+	z[7] = 'V'
+	obj.raw.DeleteUnknown("a")
+	obj.raw.SetUnknown("b", json.RawMessage(`2`))
+	obj.raw.Configure(WithStripPrefix(""))
+	assert.Equal(t, `{"z":{"k":"v"},"name":"foo","x_a":[1]}`, mustMarshal(t, &template))
+	assert.Equal(t, `{"z":{"k":"V"},"name":"foo","b":2}`, mustMarshal(t, &obj))
+}
@@ -0,0 +1,35 @@
+package jsonobj
+
+// Codec marshals and unmarshals JSON values. For example, the
+// ConfigCompatibleWithStandardLibrary API of github.com/json-iterator/go
+// implements Codec.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// WithCodec makes FromJSON and ToJSON use c rather than encoding/json to
+// decode the input object, decode known fields, and encode keys, known fields
+// and retained fields. Retained values are passed to c as json.RawMessage
+// values, so they're formatted by c rather than written as-is (see ToJSON).
+//
+// Behavior may differ from encoding/json depending on the codec, such as how
+// numbers are decoded into interface values and whether HTML is escaped.
+// WithUseNumber and WithEscapeHTML only apply to encoding/json, so the codec
+// should be configured instead.
+//
+// Some options (such as WithChecksumField and WithOutputSchema) process
+// the output using encoding/json, regardless of the codec.
+func WithCodec(c Codec) Option {
+	return func(o *options) {
+		o.codec = c
+	}
+}
+
+// marshal marshals v using the configured codec.
+func (r *Retain) marshal(v any) ([]byte, error) {
+	if r.opts.codec != nil {
+		return r.opts.codec.Marshal(v)
+	}
+	return marshalJSON(v, !r.opts.noEscapeHTML)
+}
@@ -0,0 +1,51 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// indentCodec is a Codec that indents marshalled values, and records calls.
+type indentCodec struct {
+	marshalled   []any
+	unmarshalled []string
+}
+
+func (c *indentCodec) Marshal(v any) ([]byte, error) {
+	c.marshalled = append(c.marshalled, v)
+	return json.MarshalIndent(v, "", " ")
+}
+
+func (c *indentCodec) Unmarshal(data []byte, v any) error {
+	c.unmarshalled = append(c.unmarshalled, string(data))
+	return json.Unmarshal(data, v)
+}
+
+func TestWithCodec(t *testing.T) {
+	var (
+		codec indentCodec
+		s     S
+	)
+	s.raw.Configure(WithCodec(&codec))
+	require.NoError(t, s.raw.FromJSON([]byte(`{"name":"foo","obj":{"a":[1]}}`), &s))
+	assert.Equal(t, "foo", s.Name)
+	assert.Equal(t, []string{`{"name":"foo","obj":{"a":[1]}}`, `"foo"`}, codec.unmarshalled,
+		"codec should decode the input and known fields")
+
+	got, err := s.raw.ToJSON(&s)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"name\":\"foo\",\"obj\":{\n \"a\": [\n  1\n ]\n}}", string(got),
+		"retained values should be formatted by the codec")
+	assert.Equal(t, []any{"name", "foo", "obj", json.RawMessage(`{"a":[1]}`)}, codec.marshalled,
+		"codec should encode keys, known fields and retained fields")
+}
+
+func TestWithCodec_Errors(t *testing.T) {
+	var s S
+	s.raw.Configure(WithCodec(&indentCodec{}))
+	err := s.raw.FromJSON([]byte(`{"name": 1}`), &s)
+	assert.ErrorContains(t, err, "cannot unmarshal number")
+}
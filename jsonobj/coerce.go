@@ -0,0 +1,58 @@
+package jsonobj
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// coerceToSlice wraps a non-array, non-null JSON value in an array
+// if it's being decoded into a slice. Byte slices are left as-is since
+// they're encoded as base64 strings.
+func coerceToSlice(data json.RawMessage, t reflect.Type) json.RawMessage {
+	if t.Kind() != reflect.Slice || t.Elem().Kind() == reflect.Uint8 {
+		return data
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || trimmed[0] == '[' || isNull(trimmed) {
+		return data
+	}
+
+	wrapped := make([]byte, 0, len(trimmed)+2)
+	wrapped = append(wrapped, '[')
+	wrapped = append(wrapped, trimmed...)
+	return append(wrapped, ']')
+}
+
+type arrayLength struct {
+	requireExact bool
+}
+
+// WithStrictArrayLength makes FromJSON fail when a known fixed-size array
+// field (such as [3]int) is decoded from a JSON array with more elements than
+// the array's length, rather than ignoring the extra elements. If requireExact
+// is set, FromJSON also fails for JSON arrays with fewer elements.
+// Slice fields are unaffected.
+func WithStrictArrayLength(requireExact bool) Option {
+	return func(o *options) {
+		o.strictArrays = &arrayLength{requireExact: requireExact}
+	}
+}
+
+func checkArrayLength(name string, data json.RawMessage, t reflect.Type, opts arrayLength) error {
+	if t.Kind() != reflect.Array || !bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		return nil
+	}
+
+	var elems []json.RawMessage
+	if err := json.Unmarshal(data, &elems); err != nil {
+		return err
+	}
+
+	if len(elems) > t.Len() || (opts.requireExact && len(elems) < t.Len()) {
+		return fmt.Errorf("field %q: expected array of length %v, got %v", name, t.Len(), len(elems))
+	}
+	return nil
+}
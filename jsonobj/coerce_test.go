@@ -0,0 +1,153 @@
+package jsonobj
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithCoerceScalarToSlice(t *testing.T) {
+	type Item struct {
+		ID int `json:"id"`
+	}
+
+	type Obj struct {
+		Tags  []string `json:"tags"`
+		Items []Item   `json:"items"`
+		Bytes []byte   `json:"bytes"`
+	}
+
+	tests := []struct {
+		name    string
+		json    string
+		want    Obj
+		wantErr string
+	}{
+		{
+			name: "arrays unchanged",
+			json: `{"tags": ["a", "b"], "items": [{"id": 1}]}`,
+			want: Obj{Tags: []string{"a", "b"}, Items: []Item{{ID: 1}}},
+		},
+		{
+			name: "scalar to []string",
+			json: `{"tags": "a"}`,
+			want: Obj{Tags: []string{"a"}},
+		},
+		{
+			name: "object to []struct",
+			json: `{"items": {"id": 2}}`,
+			want: Obj{Items: []Item{{ID: 2}}},
+		},
+		{
+			name: "null",
+			json: `{"tags": null}`,
+			want: Obj{},
+		},
+		{
+			name: "bytes are not coerced",
+			json: `{"bytes": "Ynl0ZXM="}`,
+			want: Obj{Bytes: []byte("bytes")},
+		},
+		{
+			name:    "incompatible element type",
+			json:    `{"tags": 1}`,
+			wantErr: "cannot unmarshal number into",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				obj Obj
+				r   Retain
+			)
+			r.Configure(WithCoerceScalarToSlice())
+
+			err := r.FromJSON([]byte(tt.json), &obj)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, obj)
+		})
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		var (
+			obj Obj
+			r   Retain
+		)
+		err := r.FromJSON([]byte(`{"tags": "a"}`), &obj)
+		assert.ErrorContains(t, err, "cannot unmarshal string into Go value of type []string")
+	})
+}
+
+func TestWithStrictArrayLength(t *testing.T) {
+	type Obj struct {
+		Point [3]int `json:"point"`
+		List  []int  `json:"list"`
+	}
+
+	tests := []struct {
+		name         string
+		requireExact bool
+		json         string
+		want         Obj
+		wantErr      string
+	}{
+		{
+			name: "exact length",
+			json: `{"point": [1, 2, 3]}`,
+			want: Obj{Point: [3]int{1, 2, 3}},
+		},
+		{
+			name:    "too long",
+			json:    `{"point": [1, 2, 3, 4]}`,
+			wantErr: `field "point": expected array of length 3, got 4`,
+		},
+		{
+			name: "too short allowed",
+			json: `{"point": [1, 2]}`,
+			want: Obj{Point: [3]int{1, 2, 0}},
+		},
+		{
+			name:         "too short with requireExact",
+			requireExact: true,
+			json:         `{"point": [1, 2]}`,
+			wantErr:      `field "point": expected array of length 3, got 2`,
+		},
+		{
+			name:         "null",
+			requireExact: true,
+			json:         `{"point": null}`,
+		},
+		{
+			name:         "slices unaffected",
+			requireExact: true,
+			json:         `{"list": [1, 2, 3, 4]}`,
+			want:         Obj{List: []int{1, 2, 3, 4}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				obj Obj
+				r   Retain
+			)
+			r.Configure(WithStrictArrayLength(tt.requireExact))
+
+			err := r.FromJSON([]byte(tt.json), &obj)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, obj)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package jsonobj
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// CheckCollisions returns a *CollisionError if any retained field has the
+// same name (or alias) as a known field of obj. FromJSON never retains these,
+// but they can be added using SetUnknown, or retained before the known field
+// was added to obj's type.
+//
+// ToJSON outputs the known field rather than the retained field, even if the
+// known field is omitted (such as by omitempty), so the retained value is
+// lost, unless WithRetainedAsKnown is used. Retained values that FromJSON
+// keeps in place of a known field (see FromJSONProject and
+// WithRetainUnknownTypes) are not collisions.
+func (r *Retain) CheckCollisions(obj any) error {
+	if isNilObj(obj) {
+		return errors.New("CheckCollisions requires a non-nil struct")
+	}
+	rv, ok := ensureStruct(obj, false /* requirePtr */)
+	if !ok {
+		return fmt.Errorf("CheckCollisions requires a struct, got %T", obj)
+	}
+
+	names, err := prefixedFieldNames(rv, r.tagKey())
+	if err != nil {
+		return err
+	}
+
+	inPlace := make(map[string]struct{})
+	forJSONField(rv, r.tagKey(), func(t jsonTag, v reflect.Value) bool {
+		if r.retainedInPlace(t, v) {
+			inPlace[t.name()] = struct{}{}
+		}
+		return false
+	})
+
+	var keys []string
+	for _, name := range names {
+		if _, ok := inPlace[name]; ok {
+			continue
+		}
+		if _, ok := r.raw[name]; ok {
+			keys = append(keys, name)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	slices.Sort(keys)
+	return &CollisionError{Keys: slices.Compact(keys)}
+}
+
+// CollisionError is returned by CheckCollisions when retained fields have
+// the same name as known fields.
+type CollisionError struct {
+	// Keys are the sorted retained keys that collide with known fields.
+	Keys []string
+}
+
+func (e *CollisionError) Error() string {
+	quoted := make([]string, len(e.Keys))
+	for i, k := range e.Keys {
+		quoted[i] = strconv.Quote(k)
+	}
+	return "retained fields collide with known fields: " + strings.Join(quoted, ", ")
+}
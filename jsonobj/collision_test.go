@@ -0,0 +1,101 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetain_KnownFieldPrecedence(t *testing.T) {
+	type labels struct {
+		Env string `json:"env,omitempty"`
+	}
+	type collideS struct {
+		raw Retain
+
+		Name   string `json:"name"`
+		Count  int    `json:"count,omitempty"`
+		Hidden string `json:"hidden" jsonobj:"noemit"`
+		Labels labels `json:"labels" jsonobj:"prefix=label_"`
+	}
+
+	tests := []struct {
+		name     string
+		obj      collideS
+		retained map[string]string
+		want     string
+	}{
+		{
+			name:     "emitted known field replaces retained field",
+			obj:      collideS{Name: "known", Count: 1},
+			retained: map[string]string{"name": `"retained"`, "count": `2`, "other": `3`},
+			want:     `{"name":"known","count":1,"other":3}`,
+		},
+		{
+			name:     "omitted known field omits retained field",
+			obj:      collideS{Name: "known"},
+			retained: map[string]string{"count": `2`, "other": `3`},
+			want:     `{"name":"known","other":3}`,
+		},
+		{
+			name:     "noemit field omits retained field",
+			retained: map[string]string{"hidden": `"retained"`},
+			want:     `{"name":""}`,
+		},
+		{
+			name:     "omitted prefixed field omits retained field",
+			retained: map[string]string{"label_env": `"retained"`},
+			want:     `{"name":""}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := tt.obj
+			for k, v := range tt.retained {
+				obj.raw.SetUnknown(k, json.RawMessage(v))
+			}
+
+			got, err := obj.raw.ToJSON(&obj)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestRetain_CheckCollisions(t *testing.T) {
+	type labels struct {
+		Env string `json:"env"`
+	}
+	type collideS struct {
+		raw Retain
+
+		Name   string `json:"name" jsonobj_aliases:"title"`
+		Labels labels `json:"labels" jsonobj:"prefix=label_"`
+	}
+
+	var s collideS
+	require.NoError(t, s.raw.FromJSON([]byte(`{"name": "foo", "NAME": "bar", "title": "baz", "label_env": "x", "other": 1}`), &s))
+	assert.NoError(t, s.raw.CheckCollisions(&s), "FromJSON should not retain known fields")
+
+	s.raw.SetUnknown("name", json.RawMessage(`"retained"`))
+	s.raw.SetUnknown("label_env", json.RawMessage(`"retained"`))
+	s.raw.SetUnknown("title", json.RawMessage(`"retained"`))
+	err := s.raw.CheckCollisions(s)
+	assert.EqualError(t, err, `retained fields collide with known fields: "label_env", "name", "title"`)
+
+	var collisionErr *CollisionError
+	require.True(t, errors.As(err, &collisionErr))
+	assert.Equal(t, []string{"label_env", "name", "title"}, collisionErr.Keys)
+
+	assert.EqualError(t, s.raw.CheckCollisions("str"), "CheckCollisions requires a struct, got string")
+}
+
+func TestRetain_CheckCollisions_Projected(t *testing.T) {
+	var s S
+	require.NoError(t, s.raw.FromJSONProject([]byte(`{"name": "foo", "x": 1}`), &s, nil))
+	assert.NoError(t, s.raw.CheckCollisions(&s), "projected fields are retained in place")
+}
@@ -0,0 +1,184 @@
+package jsonobj
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// ToJSONChangedOnly returns a JSON object containing the fields of current
+// that differ from baseline, including both known and retained fields.
+// Fields in baseline that are missing from current are included as null.
+// Values are compared using their canonical JSON (see WithChecksumField), so
+// formatting differences aren't considered changes.
+//
+// Both objects must be of the same type, and are marshalled using
+// json.Marshal, so they should be retainable types.
+func ToJSONChangedOnly(baseline, current any) ([]byte, error) {
+	if err := verifySameType("ToJSONChangedOnly", baseline, current); err != nil {
+		return nil, err
+	}
+
+	base, err := marshalFields(baseline)
+	if err != nil {
+		return nil, err
+	}
+
+	cur, err := marshalFields(current)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := make(map[string]json.RawMessage)
+	for k, v := range cur {
+		equal, err := jsonEqual(base[k], v)
+		if err != nil {
+			return nil, fmt.Errorf("compare field %q: %w", k, err)
+		}
+		if !equal {
+			changed[k] = v
+		}
+	}
+	for k := range base {
+		if _, ok := cur[k]; !ok {
+			changed[k] = json.RawMessage("null")
+		}
+	}
+
+	return json.Marshal(changed)
+}
+
+// Diff compares the fields of a and b, including both known and retained
+// fields, and returns the sorted keys that were added (only in b), removed
+// (only in a), and changed (in both, with different values). Values are
+// compared using their canonical JSON, as with ToJSONChangedOnly, so
+// formatting and key order differences aren't considered changes.
+//
+// Both objects must be of the same type, and are marshalled using
+// json.Marshal, so they should be retainable types.
+func Diff(a, b any) (added, removed, changed []string, err error) {
+	if err := verifySameType("Diff", a, b); err != nil {
+		return nil, nil, nil, err
+	}
+
+	aFields, err := marshalFields(a)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	bFields, err := marshalFields(b)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	for k, bv := range bFields {
+		av, ok := aFields[k]
+		if !ok {
+			added = append(added, k)
+			continue
+		}
+
+		equal, err := jsonEqual(av, bv)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("compare field %q: %w", k, err)
+		}
+		if !equal {
+			changed = append(changed, k)
+		}
+	}
+	for k := range aFields {
+		if _, ok := bFields[k]; !ok {
+			removed = append(removed, k)
+		}
+	}
+
+	sort.Strings(added)
+	sort.Strings(removed)
+	sort.Strings(changed)
+	return added, removed, changed, nil
+}
+
+// Equal returns whether a and b have the same fields with equal values,
+// including both known and retained fields. Values are compared using their
+// canonical JSON, as with Diff, so key order and formatting differences
+// (such as whitespace in retained fields) are ignored.
+//
+// Both objects must be of the same type, and are marshalled using
+// json.Marshal, so they should be retainable types.
+func Equal(a, b any) (bool, error) {
+	if err := verifySameType("Equal", a, b); err != nil {
+		return false, err
+	}
+
+	aFields, err := marshalFields(a)
+	if err != nil {
+		return false, err
+	}
+
+	bFields, err := marshalFields(b)
+	if err != nil {
+		return false, err
+	}
+
+	if len(aFields) != len(bFields) {
+		return false, nil
+	}
+	for k, av := range aFields {
+		bv, ok := bFields[k]
+		if !ok {
+			return false, nil
+		}
+
+		equal, err := jsonEqual(av, bv)
+		if err != nil {
+			return false, fmt.Errorf("compare field %q: %w", k, err)
+		}
+		if !equal {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func verifySameType(fn string, a, b any) error {
+	if reflect.TypeOf(a) != reflect.TypeOf(b) {
+		return fmt.Errorf("%v requires objects of the same type, got %T and %T", fn, a, b)
+	}
+	return nil
+}
+
+// marshalFields marshals obj and returns the fields of the resulting object.
+func marshalFields(obj any) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("%T must marshal to a JSON object: %w", obj, err)
+	}
+	return fields, nil
+}
+
+// jsonEqual compares two JSON values using their canonical form.
+// A nil value is not equal to any other value.
+func jsonEqual(a, b json.RawMessage) (bool, error) {
+	if a == nil || b == nil {
+		return a == nil && b == nil, nil
+	}
+
+	canonicalA, err := canonicalJSON(a)
+	if err != nil {
+		return false, err
+	}
+
+	canonicalB, err := canonicalJSON(b)
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(canonicalA, canonicalB), nil
+}
@@ -0,0 +1,214 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToJSONChangedOnly(t *testing.T) {
+	tests := []struct {
+		name     string
+		baseline string
+		update   func(*S)
+		want     string
+	}{
+		{
+			name:     "no changes",
+			baseline: `{"name": "foo", "obj": {"a": 1, "b": 2}}`,
+			want:     `{}`,
+		},
+		{
+			name:     "known field changed",
+			baseline: `{"name": "foo", "num": 1}`,
+			update: func(s *S) {
+				s.Name = "bar"
+			},
+			want: `{"name": "bar"}`,
+		},
+		{
+			name:     "known field removed",
+			baseline: `{"name": "foo", "num": 1}`,
+			update: func(s *S) {
+				s.Name = ""
+			},
+			want: `{"name": null}`,
+		},
+		{
+			name:     "retained fields changed",
+			baseline: `{"name": "foo", "num": 1, "old": true}`,
+			update: func(s *S) {
+				s.raw.SetUnknown("num", json.RawMessage(`2`))
+				s.raw.SetUnknown("new", json.RawMessage(`"x"`))
+				s.raw.DeleteUnknown("old")
+			},
+			want: `{"num": 2, "new": "x", "old": null}`,
+		},
+		{
+			name:     "formatting is not a change",
+			baseline: `{"obj": {"a": 1, "b": 2}}`,
+			update: func(s *S) {
+				s.raw.SetUnknown("obj", json.RawMessage(`{ "b":2,"a":1 }`))
+			},
+			want: `{}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var baseline, current S
+			require.NoError(t, json.Unmarshal([]byte(tt.baseline), &baseline))
+			require.NoError(t, json.Unmarshal([]byte(tt.baseline), &current))
+			if tt.update != nil {
+				tt.update(&current)
+			}
+
+			got, err := ToJSONChangedOnly(&baseline, &current)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestToJSONChangedOnly_Errors(t *testing.T) {
+	t.Run("different types", func(t *testing.T) {
+		_, err := ToJSONChangedOnly(&S{}, S{})
+		assert.EqualError(t, err, "ToJSONChangedOnly requires objects of the same type, got *jsonobj.S and jsonobj.S")
+	})
+
+	t.Run("not an object", func(t *testing.T) {
+		_, err := ToJSONChangedOnly("a", "b")
+		assert.ErrorContains(t, err, "string must marshal to a JSON object")
+	})
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name        string
+		update      func(*S)
+		wantAdded   []string
+		wantRemoved []string
+		wantChanged []string
+	}{
+		{
+			name: "no changes",
+		},
+		{
+			name: "reordered and reformatted",
+			update: func(s *S) {
+				s.raw.SetUnknown("obj", json.RawMessage(`{ "b":2,"a":1 }`))
+			},
+		},
+		{
+			name: "known and retained fields",
+			update: func(s *S) {
+				s.Name = ""
+				s.raw.SetUnknown("num", json.RawMessage(`2`))
+				s.raw.SetUnknown("z", json.RawMessage(`true`))
+				s.raw.SetUnknown("new", json.RawMessage(`"x"`))
+				s.raw.DeleteUnknown("old")
+			},
+			wantAdded:   []string{"new", "z"},
+			wantRemoved: []string{"name", "old"},
+			wantChanged: []string{"num"},
+		},
+	}
+
+	const input = `{"name": "foo", "num": 1, "old": true, "obj": {"a": 1, "b": 2}}`
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var a, b S
+			require.NoError(t, json.Unmarshal([]byte(input), &a))
+			require.NoError(t, json.Unmarshal([]byte(input), &b))
+			if tt.update != nil {
+				tt.update(&b)
+			}
+
+			added, removed, changed, err := Diff(&a, &b)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAdded, added, "added")
+			assert.Equal(t, tt.wantRemoved, removed, "removed")
+			assert.Equal(t, tt.wantChanged, changed, "changed")
+		})
+	}
+}
+
+func TestDiff_Errors(t *testing.T) {
+	t.Run("different types", func(t *testing.T) {
+		_, _, _, err := Diff(&S{}, &consumeS{})
+		assert.EqualError(t, err, "Diff requires objects of the same type, got *jsonobj.S and *jsonobj.consumeS")
+	})
+
+	t.Run("not an object", func(t *testing.T) {
+		_, _, _, err := Diff(1, 2)
+		assert.ErrorContains(t, err, "int must marshal to a JSON object")
+	})
+}
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want bool
+	}{
+		{
+			name: "identical",
+			a:    `{"name": "foo", "x": 1}`,
+			b:    `{"name": "foo", "x": 1}`,
+			want: true,
+		},
+		{
+			name: "reordered and reformatted",
+			a:    `{"name": "foo", "obj": {"a": 1, "b": [1, 2]}, "n": "\u0041"}`,
+			b:    `{"obj":{"b":[1,2],"a":1},"n":"A","name":"foo"}`,
+			want: true,
+		},
+		{
+			name: "different known field",
+			a:    `{"name": "foo", "x": 1}`,
+			b:    `{"name": "bar", "x": 1}`,
+		},
+		{
+			name: "different retained field",
+			a:    `{"name": "foo", "x": {"a": 1}}`,
+			b:    `{"name": "foo", "x": {"a": 2}}`,
+		},
+		{
+			name: "extra retained field",
+			a:    `{"name": "foo"}`,
+			b:    `{"name": "foo", "x": null}`,
+		},
+		{
+			name: "different retained keys",
+			a:    `{"name": "foo", "x": 1}`,
+			b:    `{"name": "foo", "y": 1}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var a, b S
+			require.NoError(t, json.Unmarshal([]byte(tt.a), &a))
+			require.NoError(t, json.Unmarshal([]byte(tt.b), &b))
+
+			got, err := Equal(&a, &b)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			got, err = Equal(&b, &a)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got, "Equal should be symmetric")
+		})
+	}
+}
+
+func TestEqual_Errors(t *testing.T) {
+	_, err := Equal(&S{}, S{})
+	assert.EqualError(t, err, "Equal requires objects of the same type, got *jsonobj.S and jsonobj.S")
+
+	_, err = Equal(1, 2)
+	assert.ErrorContains(t, err, "int must marshal to a JSON object")
+}
@@ -0,0 +1,110 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRetain_ConcurrentReads verifies the documented concurrency contract,
+// and is most useful when run with -race.
+func TestRetain_ConcurrentReads(t *testing.T) {
+	input := `{"name": "foo", "num": 1, "obj": {"k": "v"}, "list": [1, 2, 3]}`
+
+	var s S
+	require.NoError(t, json.Unmarshal([]byte(input), &s))
+
+	const (
+		goroutines = 8
+		iterations = 100
+	)
+
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < iterations; j++ {
+				got, err := s.raw.ToJSON(&s)
+				assert.NoError(t, err)
+				assert.JSONEq(t, input, string(got))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < iterations; j++ {
+				_, _, err := s.raw.SplitJSON(&s)
+				assert.NoError(t, err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < iterations; j++ {
+				v, ok := s.raw.GetUnknown("num")
+				assert.True(t, ok)
+				assert.Equal(t, "1", string(v))
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestRetain_ConcurrentIndependentDecodes(t *testing.T) {
+	input := []byte(`{"name": "foo", "num": 1}`)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				// Each goroutine uses its own Retain, and shares the input.
+				var s S
+				assert.NoError(t, json.Unmarshal(input, &s))
+				assert.JSONEq(t, string(input), mustMarshal(t, &s))
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// TestRetain_ConcurrentClones verifies that clones share no mutable state
+// with the original, and is most useful when run with -race.
+func TestRetain_ConcurrentClones(t *testing.T) {
+	var template S
+	template.raw.Configure(WithStripPrefix("x_"), WithFieldDefault("name", json.RawMessage(`"d"`)))
+	require.NoError(t, json.Unmarshal([]byte(`{"name": "foo", "x_a": [1], "b": {"k": "v"}}`), &template))
+	want, err := template.raw.ToJSON(&template)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				obj := template
+				obj.raw = template.raw.Clone()
+
+				b, _ := obj.raw.GetUnknown("b")
+				b[0] = '['
+				obj.raw.SetUnknown("c", json.RawMessage(`3`))
+				obj.raw.DeleteUnknown("a")
+				obj.raw.Configure(WithFieldDefault("name", json.RawMessage(`"foo"`)))
+				assert.NoError(t, obj.raw.FromJSON([]byte(`{"x_a": [2], "d": 4}`), &obj))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				got, err := template.raw.ToJSON(&template)
+				assert.NoError(t, err)
+				assert.Equal(t, string(want), string(got))
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,25 @@
+package jsonobj
+
+import "encoding/json"
+
+// WithFieldDefault registers defaultRaw as the default value of the known
+// field jsonName. ToJSON omits the field when its value is equal to the
+// default (comparing canonical JSON, see WithChecksumField), and FromJSON
+// decodes the default into the field when it's absent from the input, so
+// objects round-trip without loss while minimizing output.
+func WithFieldDefault(jsonName string, defaultRaw json.RawMessage) Option {
+	return func(o *options) {
+		if o.fieldDefaults == nil {
+			o.fieldDefaults = make(map[string]json.RawMessage)
+		}
+		o.fieldDefaults[jsonName] = defaultRaw
+	}
+}
+
+func equalsDefault(v any, def json.RawMessage) (bool, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return false, err
+	}
+	return jsonEqual(data, def)
+}
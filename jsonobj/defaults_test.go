@@ -0,0 +1,82 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithFieldDefault(t *testing.T) {
+	type Config struct {
+		Retries int      `json:"retries"`
+		Hosts   []string `json:"hosts"`
+		Name    string   `json:"name"`
+	}
+
+	newRetain := func() *Retain {
+		var r Retain
+		r.Configure(
+			WithFieldDefault("retries", json.RawMessage(`3`)),
+			WithFieldDefault("hosts", json.RawMessage(`["a", "b"]`)),
+		)
+		return &r
+	}
+
+	tests := []struct {
+		name    string
+		json    string
+		want    Config
+		wantOut string
+	}{
+		{
+			name:    "defaults applied",
+			json:    `{"name": "foo"}`,
+			want:    Config{Retries: 3, Hosts: []string{"a", "b"}, Name: "foo"},
+			wantOut: `{"name": "foo"}`,
+		},
+		{
+			name:    "explicit default values are omitted",
+			json:    `{"name": "foo", "retries": 3, "hosts": ["a","b"]}`,
+			want:    Config{Retries: 3, Hosts: []string{"a", "b"}, Name: "foo"},
+			wantOut: `{"name": "foo"}`,
+		},
+		{
+			name:    "non-default values",
+			json:    `{"name": "", "retries": 0, "hosts": ["b", "a"]}`,
+			want:    Config{Retries: 0, Hosts: []string{"b", "a"}},
+			wantOut: `{"name": "", "retries": 0, "hosts": ["b", "a"]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRetain()
+
+			var c Config
+			require.NoError(t, r.FromJSON([]byte(tt.json), &c))
+			assert.Equal(t, tt.want, c)
+
+			got, err := r.ToJSON(c)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.wantOut, string(got))
+
+			// Verify the output round-trips.
+			var c2 Config
+			require.NoError(t, newRetain().FromJSON(got, &c2))
+			assert.Equal(t, c, c2)
+		})
+	}
+}
+
+func TestWithFieldDefault_Invalid(t *testing.T) {
+	type Config struct {
+		Retries int `json:"retries"`
+	}
+
+	var r Retain
+	r.Configure(WithFieldDefault("retries", json.RawMessage(`"3"`)))
+	err := r.FromJSON([]byte(`{}`), &Config{})
+	assert.ErrorContains(t, err, "cannot unmarshal string")
+}
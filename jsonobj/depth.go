@@ -0,0 +1,92 @@
+package jsonobj
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// WithMaxStructDepth limits the nesting of retainable structs (structs with a
+// Retain field) that FromJSON descends into to n levels below the top-level
+// object, and fails with the path of the field where the limit was exceeded.
+// This bounds the recursive decoding of untrusted input.
+//
+// This is independent of the nesting of the JSON input itself, since nested
+// values that aren't retainable structs are decoded by encoding/json.
+// By default, the depth is unlimited.
+func WithMaxStructDepth(n int) Option {
+	return func(o *options) {
+		o.maxStructDepth = n
+	}
+}
+
+func checkStructDepth(rt reflect.Type, tagKey string, fields map[string]json.RawMessage, max int) error {
+	return checkFieldsDepth(rt, tagKey, fields, 0, max, "")
+}
+
+// checkFieldsDepth checks the known fields of the struct rt for retainable
+// structs nested more than max levels deep, where fields are at depth.
+func checkFieldsDepth(rt reflect.Type, tagKey string, fields map[string]json.RawMessage, depth, max int, path string) error {
+	return forJSONField(reflect.New(rt).Elem(), tagKey, func(t jsonTag, v reflect.Value) error {
+		fieldJSON, ok := fields[t.name()]
+		if !ok {
+			return nil
+		}
+		return checkValueDepth(v.Type(), fieldJSON, depth, max, joinPath(path, t.name()))
+	})
+}
+
+func checkValueDepth(t reflect.Type, data json.RawMessage, depth, max int, path string) error {
+	if isNull(data) {
+		return nil
+	}
+
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if !hasRetainField(t) {
+			return nil
+		}
+
+		if depth+1 > max {
+			return fmt.Errorf("max struct depth %v exceeded at %v", max, path)
+		}
+
+		var fields map[string]json.RawMessage
+		if json.Unmarshal(data, &fields) != nil {
+			// Not an object, so decoding will report an error.
+			return nil
+		}
+		// Nested structs are decoded using encoding/json.
+		return checkFieldsDepth(t, defaultTagKey, fields, depth+1, max, path)
+
+	case reflect.Slice, reflect.Array:
+		var elems []json.RawMessage
+		if json.Unmarshal(data, &elems) != nil {
+			return nil
+		}
+
+		for i, elem := range elems {
+			if err := checkValueDepth(t.Elem(), elem, depth, max, fmt.Sprintf("%v[%v]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func isNull(data json.RawMessage) bool {
+	return string(bytes.TrimSpace(data)) == "null"
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,90 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type node struct {
+	raw Retain
+
+	Name     string  `json:"name,omitempty"`
+	Child    *node   `json:"child,omitempty"`
+	Children []*node `json:"children,omitempty"`
+}
+
+func (n *node) UnmarshalJSON(data []byte) error {
+	return n.raw.FromJSON(data, n)
+}
+
+func (n *node) MarshalJSON() ([]byte, error) {
+	return n.raw.ToJSON(n)
+}
+
+func TestWithMaxStructDepth(t *testing.T) {
+	tests := []struct {
+		name    string
+		max     int
+		json    string
+		want    string // defaults to json
+		wantErr string
+	}{
+		{
+			name: "no nesting",
+			max:  1,
+			json: `{"name": "root"}`,
+		},
+		{
+			name: "within limit",
+			max:  2,
+			json: `{"child": {"child": {"name": "2"}}}`,
+		},
+		{
+			name:    "exceeded",
+			max:     1,
+			json:    `{"child": {"child": {"name": "2"}}}`,
+			wantErr: "max struct depth 1 exceeded at child.child",
+		},
+		{
+			name:    "exceeded in slice",
+			max:     1,
+			json:    `{"children": [{"name": "0"}, {"children": [{"name": "1.0"}]}]}`,
+			wantErr: "max struct depth 1 exceeded at children[1].children[0]",
+		},
+		{
+			name: "unknown fields are not checked",
+			max:  1,
+			json: `{"other": {"child": {"child": {}}}}`,
+		},
+		{
+			name: "null",
+			max:  1,
+			json: `{"child": {"child": null}}`,
+			want: `{"child": {}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var n node
+			n.raw.Configure(WithMaxStructDepth(tt.max))
+			err := n.raw.FromJSON([]byte(tt.json), &n)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			got, err := json.Marshal(&n)
+			require.NoError(t, err)
+			want := tt.want
+			if want == "" {
+				want = tt.json
+			}
+			assert.JSONEq(t, want, string(got))
+		})
+	}
+}
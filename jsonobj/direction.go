@@ -0,0 +1,23 @@
+package jsonobj
+
+// noEmitDirective marks a known field that's decoded by FromJSON, but never
+// output by ToJSON, such as a sensitive value:
+//
+//	Password string `json:"password" jsonobj:"noemit"`
+//
+// The field is omitted regardless of its value, so omitempty and omitzero
+// have no effect.
+const noEmitDirective = "noemit"
+
+// noReadDirective marks a known field that's output by ToJSON, but never
+// decoded by FromJSON, such as a computed value:
+//
+//	Total int `json:"total" jsonobj:"noread"`
+//
+// FromJSON leaves the field unchanged, and doesn't apply WithFieldDefault
+// to it. The field is output using the usual rules, including omitempty and
+// omitzero. noread has no effect on prefix fields.
+//
+// In both cases, the field's JSON name is still known, so a matching input
+// key is dropped rather than retained.
+const noReadDirective = "noread"
@@ -0,0 +1,77 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type directionS struct {
+	raw Retain
+
+	Name     string `json:"name"`
+	Password string `json:"password,omitempty" jsonobj:"noemit"`
+	Total    int    `json:"total,omitempty" jsonobj:"noread"`
+}
+
+func (s *directionS) UnmarshalJSON(data []byte) error {
+	return s.raw.FromJSON(data, s)
+}
+
+func (s directionS) MarshalJSON() ([]byte, error) {
+	return s.raw.ToJSON(s)
+}
+
+func TestDirectives(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		opts      []Option
+		wantPass  string
+		wantTotal int
+		want      string
+	}{
+		{
+			name:      "noemit decoded, noread ignored",
+			input:     `{"name":"foo","password":"secret","total":5}`,
+			wantPass:  "secret",
+			wantTotal: 7,
+			want:      `{"name":"foo","total":7}`,
+		},
+		{
+			name:      "absent fields",
+			input:     `{"name":"foo"}`,
+			wantTotal: 7,
+			want:      `{"name":"foo","total":7}`,
+		},
+		{
+			name:      "noread ignores field defaults",
+			input:     `{"name":"foo"}`,
+			opts:      []Option{WithFieldDefault("total", json.RawMessage("3"))},
+			wantTotal: 7,
+			want:      `{"name":"foo","total":7}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := directionS{Total: 7}
+			s.raw.Configure(tt.opts...)
+			require.NoError(t, json.Unmarshal([]byte(tt.input), &s))
+			assert.Equal(t, tt.wantPass, s.Password)
+			assert.Equal(t, tt.wantTotal, s.Total)
+			assert.Empty(t, s.raw.UnknownKeys(), "directive fields are known")
+			assert.Equal(t, tt.want, mustMarshal(t, s))
+		})
+	}
+}
+
+func TestDirectives_OmitEmpty(t *testing.T) {
+	s := directionS{Name: "foo", Password: "secret"}
+	assert.Equal(t, `{"name":"foo"}`, mustMarshal(t, s), "noemit fields are never output")
+
+	s.Total = 0
+	assert.Equal(t, `{"name":"foo"}`, mustMarshal(t, s), "noread fields use omitempty")
+}
@@ -0,0 +1,130 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+type discriminator struct {
+	field    string
+	registry map[string]func() any
+	names    map[reflect.Type]string
+}
+
+// WithDiscriminator decodes the known interface field jsonName polymorphically.
+// FromJSON reads the discriminator key field from the nested JSON object, and
+// decodes the object into the value returned by the registry function for
+// the discriminator. ToJSON writes the discriminator for the concrete type
+// back into the nested object.
+//
+// Unknown discriminator values cause FromJSON to fail, unless
+// WithRetainUnknownTypes is used.
+func WithDiscriminator(jsonName, field string, registry map[string]func() any) Option {
+	names := make(map[reflect.Type]string, len(registry))
+	for name, newFn := range registry {
+		names[reflect.TypeOf(newFn())] = name
+	}
+
+	d := &discriminator{
+		field:    field,
+		registry: registry,
+		names:    names,
+	}
+	return func(o *options) {
+		if o.discriminators == nil {
+			o.discriminators = make(map[string]*discriminator)
+		}
+		o.discriminators[jsonName] = d
+	}
+}
+
+// WithRetainUnknownTypes retains the raw value of fields configured using
+// WithDiscriminator when the discriminator is unknown, leaving the field nil.
+// The retained value is emitted by ToJSON as long as the field is nil.
+func WithRetainUnknownTypes() Option {
+	return func(o *options) {
+		o.retainUnknownTypes = true
+	}
+}
+
+// decodeDiscriminated decodes fieldJSON from the input key into the
+// discriminated field t with value v.
+func (r *Retain) decodeDiscriminated(t jsonTag, d *discriminator, key string, fieldJSON json.RawMessage, v reflect.Value) error {
+	if v.Kind() != reflect.Interface {
+		return fmt.Errorf("discriminated field %q must be an interface, got %v", key, v.Type())
+	}
+
+	if string(fieldJSON) == "null" {
+		v.SetZero()
+		return nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(fieldJSON, &fields); err != nil {
+		return fmt.Errorf("discriminated field %q: %w", key, err)
+	}
+
+	var name string
+	if nameJSON, ok := fields[d.field]; ok {
+		if err := json.Unmarshal(nameJSON, &name); err != nil {
+			return fmt.Errorf("discriminated field %q: discriminator %q: %w", key, d.field, err)
+		}
+	}
+
+	newFn, ok := d.registry[name]
+	if !ok {
+		if r.opts.retainUnknownTypes {
+			v.SetZero()
+			// Retain the value under the field's name (rather than
+			// a case-insensitive match), which ToJSON looks up.
+			r.SetUnknown(t.name(), fieldJSON)
+			return nil
+		}
+		return fmt.Errorf("discriminated field %q: unknown %q value %q", key, d.field, name)
+	}
+
+	concrete := newFn()
+	if err := json.Unmarshal(fieldJSON, concrete); err != nil {
+		return fmt.Errorf("discriminated field %q: %w", key, err)
+	}
+
+	cv := reflect.ValueOf(concrete)
+	if !cv.Type().AssignableTo(v.Type()) {
+		return fmt.Errorf("discriminated field %q: %T does not implement %v", key, concrete, v.Type())
+	}
+	v.Set(cv)
+	return nil
+}
+
+func (d *discriminator) encode(v reflect.Value) (any, error) {
+	if v.Kind() != reflect.Interface {
+		return nil, fmt.Errorf("discriminated field must be an interface, got %v", v.Type())
+	}
+	if v.IsNil() {
+		return nil, nil
+	}
+
+	concrete := v.Elem().Interface()
+	name, ok := d.names[reflect.TypeOf(concrete)]
+	if !ok {
+		return nil, fmt.Errorf("no discriminator registered for %T", concrete)
+	}
+
+	data, err := json.Marshal(concrete)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("discriminated %T must marshal to an object: %w", concrete, err)
+	}
+
+	nameJSON, err := json.Marshal(name)
+	if err != nil {
+		return nil, err
+	}
+	fields[d.field] = nameJSON
+	return fields, nil
+}
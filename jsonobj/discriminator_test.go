@@ -0,0 +1,164 @@
+package jsonobj
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type shape interface {
+	area() float64
+}
+
+type circle struct {
+	Radius float64 `json:"radius"`
+}
+
+func (c *circle) area() float64 { return 3 * c.Radius * c.Radius }
+
+type square struct {
+	Side float64 `json:"side"`
+}
+
+func (s *square) area() float64 { return s.Side * s.Side }
+
+type drawing struct {
+	Name  string `json:"name"`
+	Shape shape  `json:"shape,omitempty"`
+}
+
+func shapeRegistry() Option {
+	return WithDiscriminator("shape", "type", map[string]func() any{
+		"circle": func() any { return &circle{} },
+		"square": func() any { return &square{} },
+	})
+}
+
+func TestWithDiscriminator(t *testing.T) {
+	tests := []struct {
+		name      string
+		json      string
+		wantShape shape
+	}{
+		{
+			name:      "circle",
+			json:      `{"name": "c", "shape": {"type": "circle", "radius": 2}}`,
+			wantShape: &circle{Radius: 2},
+		},
+		{
+			name:      "square",
+			json:      `{"name": "s", "shape": {"type": "square", "side": 3}, "other": true}`,
+			wantShape: &square{Side: 3},
+		},
+		{
+			name: "null",
+			json: `{"name": "n", "shape": null}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				d drawing
+				r Retain
+			)
+			r.Configure(shapeRegistry())
+
+			require.NoError(t, r.FromJSON([]byte(tt.json), &d))
+			assert.Equal(t, tt.wantShape, d.Shape)
+
+			got, err := r.ToJSON(d)
+			require.NoError(t, err)
+			if tt.wantShape == nil {
+				// omitempty drops the nil shape.
+				return
+			}
+			assert.JSONEq(t, tt.json, string(got))
+		})
+	}
+}
+
+func TestWithDiscriminator_Unknown(t *testing.T) {
+	input := `{"name": "t", "shape": {"type": "triangle", "sides": [3, 4, 5]}}`
+
+	t.Run("error by default", func(t *testing.T) {
+		var r Retain
+		r.Configure(shapeRegistry())
+		err := r.FromJSON([]byte(input), &drawing{})
+		assert.EqualError(t, err, `discriminated field "shape": unknown "type" value "triangle"`)
+	})
+
+	t.Run("retain", func(t *testing.T) {
+		var (
+			d drawing
+			r Retain
+		)
+		r.Configure(shapeRegistry(), WithRetainUnknownTypes())
+		require.NoError(t, r.FromJSON([]byte(input), &d))
+		assert.Nil(t, d.Shape)
+
+		got, err := r.ToJSON(d)
+		require.NoError(t, err)
+		assert.JSONEq(t, input, string(got))
+
+		d.Shape = &square{Side: 1}
+		got, err = r.ToJSON(d)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"name": "t", "shape": {"type": "square", "side": 1}}`, string(got))
+	})
+
+	t.Run("retain case-insensitive match", func(t *testing.T) {
+		var (
+			d drawing
+			r Retain
+		)
+		r.Configure(shapeRegistry(), WithRetainUnknownTypes())
+		require.NoError(t, r.FromJSON([]byte(`{"name": "t", "Shape": {"type": "hexagon"}}`), &d))
+		assert.Nil(t, d.Shape)
+
+		got, err := r.ToJSON(d)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"name": "t", "shape": {"type": "hexagon"}}`, string(got))
+	})
+}
+
+func TestWithDiscriminator_Errors(t *testing.T) {
+	type hexagon struct{ shape }
+
+	t.Run("unregistered type", func(t *testing.T) {
+		var r Retain
+		r.Configure(shapeRegistry())
+		_, err := r.ToJSON(drawing{Shape: &hexagon{}})
+		assert.EqualError(t, err, "no discriminator registered for *jsonobj.hexagon")
+	})
+
+	t.Run("not an interface", func(t *testing.T) {
+		var (
+			r   Retain
+			obj struct {
+				Shape circle `json:"shape"`
+			}
+		)
+		r.Configure(shapeRegistry())
+		err := r.FromJSON([]byte(`{"shape": {"type": "circle"}}`), &obj)
+		assert.EqualError(t, err, `discriminated field "shape" must be an interface, got jsonobj.circle`)
+	})
+
+	t.Run("does not implement", func(t *testing.T) {
+		var r Retain
+		r.Configure(WithDiscriminator("shape", "type", map[string]func() any{
+			"circle": func() any { return &circle{} },
+			"other":  func() any { return &struct{}{} },
+		}))
+		err := r.FromJSON([]byte(`{"shape": {"type": "other"}}`), &drawing{})
+		assert.EqualError(t, err, `discriminated field "shape": *struct {} does not implement jsonobj.shape`)
+	})
+
+	t.Run("invalid discriminator", func(t *testing.T) {
+		var r Retain
+		r.Configure(shapeRegistry())
+		err := r.FromJSON([]byte(`{"shape": {"type": 1}}`), &drawing{})
+		assert.ErrorContains(t, err, `discriminated field "shape": discriminator "type": json: cannot unmarshal number`)
+	})
+}
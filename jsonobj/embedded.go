@@ -0,0 +1,46 @@
+package jsonobj
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// fieldByIndex returns the field t of the struct rv, and whether it's set.
+// A field promoted from a nil embedded pointer isn't set.
+func fieldByIndex(rv reflect.Value, t jsonTag) (reflect.Value, bool) {
+	if !t.viaPointer {
+		return rv.FieldByIndex(t.field.Index), true
+	}
+	v, err := rv.FieldByIndexErr(t.field.Index)
+	return v, err == nil
+}
+
+// settableField returns the field t of the struct rv, allocating any nil
+// embedded pointers so that it can be set.
+func settableField(rv reflect.Value, t jsonTag) (reflect.Value, error) {
+	return walkField(rv, t, true /* alloc */)
+}
+
+// walkField returns the field t of the struct rv. If alloc is false, nil
+// embedded pointers are replaced by new values that aren't stored in rv, so
+// rv isn't modified, but the same errors as settableField are returned.
+func walkField(rv reflect.Value, t jsonTag, alloc bool) (reflect.Value, error) {
+	v := rv
+	for i, x := range t.field.Index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, fmt.Errorf("field %q: cannot set embedded pointer to unexported struct %v", t.name(), v.Type().Elem())
+				}
+				if !alloc {
+					v = reflect.New(v.Type().Elem())
+				} else {
+					v.Set(reflect.New(v.Type().Elem()))
+				}
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
@@ -0,0 +1,150 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Base struct {
+	ID      string `json:"id"`
+	Version int    `json:"version,omitempty"`
+}
+
+type Audit struct {
+	CreatedBy string `json:"created_by"`
+}
+
+type Named struct {
+	Name string `json:"name"`
+}
+
+type embeddedS struct {
+	raw Retain
+
+	Base
+	*Audit
+	Named
+
+	// Name shadows Named.Name.
+	Name string `json:"name"`
+}
+
+func (s *embeddedS) UnmarshalJSON(data []byte) error {
+	return s.raw.FromJSON(data, s)
+}
+
+func (s *embeddedS) MarshalJSON() ([]byte, error) {
+	return s.raw.ToJSON(s)
+}
+
+func TestEmbedded(t *testing.T) {
+	require.NoError(t, Retainable(&embeddedS{}))
+
+	tests := []struct {
+		name    string
+		json    string
+		want    embeddedS
+		wantOut string
+	}{
+		{
+			name: "promoted fields",
+			json: `{"id": "1", "version": 2, "created_by": "me", "name": "n", "extra": true}`,
+			want: embeddedS{
+				Base:  Base{ID: "1", Version: 2},
+				Audit: &Audit{CreatedBy: "me"},
+				Name:  "n",
+			},
+			wantOut: `{"id":"1","version":2,"created_by":"me","name":"n","extra":true}`,
+		},
+		{
+			name:    "nil embedded pointer",
+			json:    `{"id": "1", "extra": true}`,
+			want:    embeddedS{Base: Base{ID: "1"}},
+			wantOut: `{"id":"1","extra":true,"name":""}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got embeddedS
+			require.NoError(t, json.Unmarshal([]byte(tt.json), &got))
+
+			tt.want.raw = got.raw
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, []string{"extra"}, got.raw.UnknownKeys(), "promoted fields should not be retained")
+
+			out, err := json.Marshal(&got)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantOut, string(out))
+		})
+	}
+}
+
+func TestEmbedded_MatchesEncodingJSON(t *testing.T) {
+	type Inner struct {
+		A string `json:"a"`
+		B string `json:"b"`
+		C string
+	}
+	type Other struct {
+		B string `json:"b"`
+		C string `json:"C"`
+	}
+	type unexported struct {
+		D string `json:"d"`
+	}
+	type Obj struct {
+		Inner
+		*Other
+		unexported
+
+		// A shadows Inner.A.
+		A string `json:"a"`
+
+		// Inner.B and Other.B conflict, so both are ignored.
+		// Other.C is tagged, so it's preferred over Inner.C.
+	}
+
+	obj := Obj{
+		Inner:      Inner{A: "inner a", B: "inner b", C: "inner c"},
+		Other:      &Other{B: "other b", C: "other c"},
+		unexported: unexported{D: "d"},
+		A:          "outer a",
+	}
+
+	var r Retain
+	got, err := r.ToJSON(obj)
+	require.NoError(t, err)
+	assert.JSONEq(t, mustMarshal(t, obj), string(got))
+
+	var decoded Obj
+	require.NoError(t, r.FromJSON([]byte(`{"a": "a", "b": "b", "C": "c", "d": "d"}`), &decoded))
+	assert.Equal(t, Obj{
+		Other:      &Other{C: "c"},
+		unexported: unexported{D: "d"},
+		A:          "a",
+	}, decoded)
+	assert.Equal(t, []string{"b"}, r.UnknownKeys(), "conflicting fields should be retained")
+}
+
+func TestEmbedded_UnexportedPointer(t *testing.T) {
+	type unexported struct {
+		A string `json:"a"`
+	}
+	type Obj struct {
+		*unexported
+	}
+
+	var (
+		r   Retain
+		obj Obj
+	)
+	require.NoError(t, r.FromJSON([]byte(`{}`), &obj))
+	assert.Nil(t, obj.unexported)
+
+	err := r.FromJSON([]byte(`{"a": "a"}`), &obj)
+	assert.ErrorContains(t, err, `field "a": cannot set embedded pointer to unexported struct`)
+}
@@ -0,0 +1,67 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// WithEnvelopeKey configures the Retain for objects wrapped in an envelope,
+// such as {"data": {...}, "meta": {...}} with key "data".
+//
+// FromJSON decodes the object under key into the struct, retaining its
+// unknown fields as usual (see GetUnknown). The sibling keys of the envelope
+// are stored separately as raw values, along with the input order of the
+// envelope, and aren't accessible using GetUnknown. ToJSON re-emits the
+// siblings unchanged in input order, with the encoded object under key at its
+// input position (or last, if there was no input). WithHashOrder applies to
+// the envelope keys too. The input must contain key.
+func WithEnvelopeKey(key string) Option {
+	return func(o *options) {
+		o.envelopeKey = key
+	}
+}
+
+// unwrapEnvelope stores the envelope siblings of data and their order, and
+// returns the object under the envelope key.
+func (r *Retain) unwrapEnvelope(data []byte) ([]byte, error) {
+	// Decode the envelope as an object, recording the order of its keys.
+	env := Retain{opts: r.opts}
+	if err := env.decodeObject(data, r.opts.codec); err != nil {
+		return nil, err
+	}
+
+	inner, ok := env.raw[r.opts.envelopeKey]
+	if !ok {
+		return nil, fmt.Errorf("missing envelope key %q", r.opts.envelopeKey)
+	}
+	delete(env.raw, r.opts.envelopeKey)
+
+	r.envelope = env.raw
+	r.envelopeOrder = env.order
+	return inner, nil
+}
+
+// wrapEnvelope returns the envelope with the encoded object obj under
+// the envelope key, in the input order of the envelope.
+func (r *Retain) wrapEnvelope(obj []byte) ([]byte, error) {
+	all := make(map[string]any, len(r.envelope)+1)
+	for k, v := range r.envelope {
+		all[k] = v
+	}
+	all[r.opts.envelopeKey] = json.RawMessage(obj)
+	if r.opts.hashOrder {
+		return r.marshalObject(all)
+	}
+
+	keys := make([]string, 0, len(all))
+	for _, k := range r.envelopeOrder {
+		if _, ok := all[k]; ok {
+			keys = append(keys, k)
+		}
+	}
+	if !slices.Contains(keys, r.opts.envelopeKey) {
+		keys = append(keys, r.opts.envelopeKey)
+	}
+	return r.marshalOrdered(keys, all)
+}
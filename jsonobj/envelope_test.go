@@ -0,0 +1,122 @@
+package jsonobj
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithEnvelopeKey(t *testing.T) {
+	type Page struct {
+		ID    int    `json:"id"`
+		Title string `json:"title"`
+	}
+
+	tests := []struct {
+		name        string
+		json        string
+		want        Page
+		wantUnknown map[string]string
+		wantOut     string
+		wantErr     string
+	}{
+		{
+			name:    "siblings and inner unknowns",
+			json:    `{"data": {"id": 1, "title": "t", "extra": true}, "meta": {"page": 2}, "v": 1}`,
+			want:    Page{ID: 1, Title: "t"},
+			wantOut: `{"data": {"id": 1, "title": "t", "extra": true}, "meta": {"page": 2}, "v": 1}`,
+			wantUnknown: map[string]string{
+				"extra": "true",
+			},
+		},
+		{
+			name:    "no siblings",
+			json:    `{"data": {"id": 1}}`,
+			want:    Page{ID: 1},
+			wantOut: `{"data": {"id": 1, "title": ""}}`,
+		},
+		{
+			name:    "missing envelope key",
+			json:    `{"meta": {}}`,
+			wantErr: `missing envelope key "data"`,
+		},
+		{
+			name:    "inner not an object",
+			json:    `{"data": [1]}`,
+			wantErr: "cannot unmarshal array",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				r    Retain
+				page Page
+			)
+			r.Configure(WithEnvelopeKey("data"))
+			err := r.FromJSON([]byte(tt.json), &page)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, page)
+
+			for k, v := range tt.wantUnknown {
+				got, ok := r.GetUnknown(k)
+				require.True(t, ok, "missing unknown %v", k)
+				assert.JSONEq(t, v, string(got))
+			}
+			_, ok := r.GetUnknown("meta")
+			assert.False(t, ok, "envelope siblings should not be inner unknowns")
+
+			out, err := r.ToJSON(page)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.wantOut, string(out))
+		})
+	}
+}
+
+func TestWithEnvelopeKey_ReplacesSiblings(t *testing.T) {
+	type Page struct {
+		ID int `json:"id"`
+	}
+
+	var (
+		r    Retain
+		page Page
+	)
+	r.Configure(WithEnvelopeKey("data"))
+	require.NoError(t, r.FromJSON([]byte(`{"data": {"id": 1}, "meta": 1}`), &page))
+	require.NoError(t, r.FromJSON([]byte(`{"data": {"id": 2}, "links": 2}`), &page))
+
+	out, err := r.ToJSON(page)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"data": {"id": 2}, "links": 2}`, string(out))
+}
+
+func TestWithEnvelopeKey_Order(t *testing.T) {
+	type Page struct {
+		ID int `json:"id"`
+	}
+
+	var (
+		r    Retain
+		page Page
+	)
+	r.Configure(WithEnvelopeKey("data"))
+	require.NoError(t, r.FromJSON([]byte(`{"z": 1, "data": {"id": 1, "x": 2}, "meta": {"b": 1, "a": 2}, "a": 3}`), &page))
+
+	out, err := r.ToJSON(page)
+	require.NoError(t, err)
+	assert.Equal(t, `{"z":1,"data":{"id":1,"x":2},"meta":{"b": 1, "a": 2},"a":3}`, string(out),
+		"siblings should be emitted in input order")
+
+	var empty Retain
+	empty.Configure(WithEnvelopeKey("data"))
+	out, err = empty.ToJSON(page)
+	require.NoError(t, err)
+	assert.Equal(t, `{"data":{"id":1}}`, string(out))
+}
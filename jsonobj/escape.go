@@ -0,0 +1,42 @@
+package jsonobj
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// WithEscapeHTML sets whether ToJSON escapes <, > and & in the JSON strings
+// of known fields, as json.Encoder.SetEscapeHTML does. Escaping is enabled by
+// default, as with json.Marshal. Disabling escaping allows known fields
+// containing these characters (such as URLs with query strings) to round-trip
+// unchanged.
+//
+// Retained fields are always written as-is (see ToJSON), even if escaping is
+// enabled, so ToJSON doesn't escape these characters in retained values, such
+// as {"a": "a&b<c>"}. This differs from json.Marshal of the retained values,
+// and from earlier versions of ToJSON, which escaped them.
+//
+// json.Marshal escapes the output of MarshalJSON methods, so escaping is only
+// disabled when calling ToJSON directly, or when marshalling using
+// a json.Encoder with SetEscapeHTML(false).
+func WithEscapeHTML(escape bool) Option {
+	return func(o *options) {
+		o.noEscapeHTML = !escape
+	}
+}
+
+// marshalJSON is similar to json.Marshal, but only escapes HTML characters
+// if escapeHTML is set.
+func marshalJSON(v any, escapeHTML bool) ([]byte, error) {
+	if escapeHTML {
+		return json.Marshal(v)
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
@@ -0,0 +1,81 @@
+package jsonobj
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithEscapeHTML(t *testing.T) {
+	const input = `{"name":"<b>&</b>","url":"https://example.com/?a=1&b=2","obj":{"html":"<p>"}}`
+
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{
+			name: "default",
+			want: `{"name":"\u003cb\u003e\u0026\u003c/b\u003e","url":"https://example.com/?a=1&b=2","obj":{"html":"<p>"}}`,
+		},
+		{
+			name: "escape",
+			opts: []Option{WithEscapeHTML(true)},
+			want: `{"name":"\u003cb\u003e\u0026\u003c/b\u003e","url":"https://example.com/?a=1&b=2","obj":{"html":"<p>"}}`,
+		},
+		{
+			name: "no escape",
+			opts: []Option{WithEscapeHTML(false)},
+			want: input,
+		},
+		{
+			name: "no escape with hash order",
+			opts: []Option{WithEscapeHTML(false), WithHashOrder()},
+			want: `{"obj":{"html":"<p>"},"url":"https://example.com/?a=1&b=2","name":"<b>&</b>"}`,
+		},
+		{
+			name: "no escape with recursive omitempty",
+			opts: []Option{WithEscapeHTML(false), WithRecursiveOmitEmpty()},
+			want: input,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s S
+			require.NoError(t, s.raw.FromJSON([]byte(input), &s))
+
+			got, err := s.raw.ToJSON(&s, tt.opts...)
+			require.NoError(t, err)
+			assert.JSONEq(t, input, string(got))
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+
+	t.Run("json.Marshal escapes retained values", func(t *testing.T) {
+		var s S
+		require.NoError(t, json.Unmarshal([]byte(`{"a":"a&b<c>"}`), &s))
+
+		got, err := s.raw.ToJSON(&s)
+		require.NoError(t, err)
+		assert.Equal(t, `{"a":"a&b<c>"}`, string(got), "ToJSON writes retained values as-is")
+		assert.Equal(t, `{"a":"a\u0026b\u003cc\u003e"}`, mustMarshal(t, &s))
+	})
+}
+
+func TestWithEscapeHTML_Encoder(t *testing.T) {
+	var s S
+	s.raw.Configure(WithEscapeHTML(false))
+	require.NoError(t, json.Unmarshal([]byte(`{"a":"&"}`), &s))
+
+	assert.Equal(t, `{"a":"\u0026"}`, mustMarshal(t, &s), "json.Marshal escapes MarshalJSON output")
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	require.NoError(t, enc.Encode(&s))
+	assert.Equal(t, `{"a":"&"}`+"\n", buf.String())
+}
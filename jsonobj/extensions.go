@@ -0,0 +1,140 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// Extensions is a Retain that promotes some unknown fields into the typed
+// struct Ext, while retaining all other unknown fields. It's used in place of
+// Retain to model frequently-seen extension fields without adding them to the
+// main struct:
+//
+//	type Page struct {
+//		raw jsonobj.Extensions[PageExt]
+//
+//		Title string `json:"title"`
+//	}
+//
+//	type PageExt struct {
+//		Icon string `json:"icon,omitempty"`
+//	}
+//
+// T must be a struct with scalar (bool, number or string) fields, or pointers
+// to scalars. Extension fields use the same tags as known fields.
+type Extensions[T any] struct {
+	Retain
+
+	Ext T
+}
+
+// FromJSON decodes known fields into obj, and then decodes any unknown fields
+// that match a field of Ext, retaining all other unknown fields.
+//
+// Keys that match a field of Ext are treated as known fields rather than
+// unknown fields by options such as WithDisallowUnknownFields, WithRetainOnly
+// and WithMaxUnknownFields.
+func (e *Extensions[T]) FromJSON(data []byte, obj any, opts ...Option) error {
+	ev, err := e.extValue()
+	if err != nil {
+		return err
+	}
+
+	return e.Retain.FromJSON(data, obj, append(slices.Clip(opts), withExtensions(ev))...)
+}
+
+// withExtensions decodes keys that match the fields of the struct ev into ev,
+// before unknown fields are checked and retained.
+func withExtensions(ev reflect.Value) Option {
+	return func(o *options) {
+		o.extensions = ev
+	}
+}
+
+// extensionNames returns the JSON names of the extension fields,
+// see withExtensions.
+func (r *Retain) extensionNames() map[string]struct{} {
+	if !r.opts.extensions.IsValid() {
+		return nil
+	}
+	return cachedFields(r.opts.extensions.Type(), r.tagKey()).names
+}
+
+// decodeExtensions decodes keys in r.raw that match an extension field,
+// see withExtensions.
+func (r *Retain) decodeExtensions(trace *decodeTrace) error {
+	ev := r.opts.extensions
+	if !ev.IsValid() {
+		return nil
+	}
+
+	return forJSONField(ev, r.tagKey(), func(t jsonTag, v reflect.Value) error {
+		fieldJSON, ok := r.raw[t.name()]
+		if !ok {
+			return nil
+		}
+
+		delete(r.raw, t.name())
+		trace.matched(t.name(), "Ext."+t.field.Name, matchExact)
+		v, err := settableField(ev, t)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(fieldJSON, v.Addr().Interface()); err != nil {
+			return fmt.Errorf("extension field %q: %w", t.name(), err)
+		}
+		return nil
+	})
+}
+
+// ToJSON marshals obj along with the Ext fields and retained unknown fields.
+func (e *Extensions[T]) ToJSON(obj any, opts ...Option) ([]byte, error) {
+	ev, err := e.extValue()
+	if err != nil {
+		return nil, err
+	}
+
+	ext := make(map[string]any)
+	if err := e.addKnownFields(ext, ev); err != nil {
+		return nil, err
+	}
+
+	// Use a copy of the Retain so e isn't modified,
+	// and ToJSON remains safe for concurrent use.
+	r := e.Retain
+	r.raw = make(map[string]json.RawMessage, len(e.raw)+len(ext))
+	for k, v := range e.raw {
+		r.raw[k] = v
+	}
+	for k, v := range ext {
+		extJSON, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("extension field %q: %w", k, err)
+		}
+		r.raw[k] = extJSON
+	}
+
+	return r.ToJSON(obj, opts...)
+}
+
+// extValue returns the addressable Ext value, after verifying its type.
+func (e *Extensions[T]) extValue() (reflect.Value, error) {
+	ev := reflect.ValueOf(&e.Ext).Elem()
+	if ev.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("Extensions requires a struct type, got %v", ev.Type())
+	}
+
+	err := forJSONField(ev, e.tagKey(), func(t jsonTag, v reflect.Value) error {
+		ft := v.Type()
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if !isScalar(ft.Kind()) {
+			return fmt.Errorf("extension field %q has unsupported type %v", t.name(), v.Type())
+		}
+		return nil
+	})
+	return ev, err
+}
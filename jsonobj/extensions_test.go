@@ -0,0 +1,119 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type pageExt struct {
+	Icon   string `json:"icon,omitempty"`
+	Weight *int   `json:"weight,omitempty"`
+}
+
+type extPage struct {
+	raw Extensions[pageExt]
+
+	Title string `json:"title"`
+}
+
+func (p *extPage) UnmarshalJSON(data []byte) error {
+	return p.raw.FromJSON(data, p)
+}
+
+func (p *extPage) MarshalJSON() ([]byte, error) {
+	return p.raw.ToJSON(p)
+}
+
+func TestExtensions(t *testing.T) {
+	input := `{"title": "Contact", "icon": "email", "weight": 2, "color": "red"}`
+
+	var p extPage
+	require.NoError(t, json.Unmarshal([]byte(input), &p))
+	assert.Equal(t, "Contact", p.Title)
+	assert.Equal(t, pageExt{Icon: "email", Weight: ptr(2)}, p.raw.Ext)
+
+	_, ok := p.raw.GetUnknown("icon")
+	assert.False(t, ok, "extension fields should not be retained")
+	color, ok := p.raw.GetUnknown("color")
+	assert.True(t, ok)
+	assert.Equal(t, `"red"`, string(color))
+
+	assert.JSONEq(t, input, mustMarshal(t, &p))
+	assert.Empty(t, LintTypes(&p))
+
+	t.Run("update extensions", func(t *testing.T) {
+		p.raw.Ext.Icon = ""
+		p.raw.Ext.Weight = ptr(3)
+		assert.JSONEq(t, `{"title": "Contact", "weight": 3, "color": "red"}`, mustMarshal(t, &p))
+	})
+}
+
+func TestExtensions_UnknownFieldOptions(t *testing.T) {
+	input := `{"title": "Contact", "icon": "email", "weight": 2, "color": "red"}`
+
+	tests := []struct {
+		name        string
+		opts        []Option
+		wantErr     string
+		wantUnknown []string
+	}{
+		{
+			name:    "disallow unknown fields",
+			opts:    []Option{WithDisallowUnknownFields()},
+			wantErr: `unknown fields: "color"`,
+		},
+		{
+			name:        "retain only",
+			opts:        []Option{WithRetainOnly("other")},
+			wantUnknown: []string{},
+		},
+		{
+			name:        "max unknown fields",
+			opts:        []Option{WithMaxUnknownFields(1)},
+			wantUnknown: []string{"color"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p extPage
+			err := p.raw.FromJSON([]byte(input), &p, tt.opts...)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, pageExt{Icon: "email", Weight: ptr(2)}, p.raw.Ext, "extension fields should be decoded")
+			assert.Equal(t, tt.wantUnknown, p.raw.UnknownKeys())
+		})
+	}
+}
+
+func TestExtensions_Errors(t *testing.T) {
+	t.Run("wrong type", func(t *testing.T) {
+		var p extPage
+		err := json.Unmarshal([]byte(`{"icon": 1}`), &p)
+		assert.ErrorContains(t, err, `extension field "icon": json: cannot unmarshal number`)
+	})
+
+	t.Run("non-scalar field", func(t *testing.T) {
+		var e Extensions[struct {
+			Tags []string `json:"tags"`
+		}]
+		err := e.FromJSON([]byte(`{}`), &S{})
+		assert.EqualError(t, err, `extension field "tags" has unsupported type []string`)
+
+		_, err = e.ToJSON(S{})
+		assert.EqualError(t, err, `extension field "tags" has unsupported type []string`)
+	})
+
+	t.Run("non-struct", func(t *testing.T) {
+		var e Extensions[string]
+		err := e.FromJSON([]byte(`{}`), &S{})
+		assert.EqualError(t, err, "Extensions requires a struct type, got string")
+	})
+}
@@ -0,0 +1,44 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// RetainedFallback is called by ToJSON for a retained field key whose value
+// badRaw is not valid JSON. It returns a valid replacement value, or an error
+// to fail ToJSON.
+type RetainedFallback func(key string, badRaw []byte) (json.RawMessage, error)
+
+// WithRetainedFallback configures fn to salvage invalid retained values,
+// such as those set by SetUnknown, when encoding with ToJSON.
+// fn is only called for values that are not valid JSON.
+func WithRetainedFallback(fn RetainedFallback) Option {
+	return func(o *options) {
+		o.retainedFallback = fn
+	}
+}
+
+// salvageRetained replaces invalid retained values in all using the
+// configured fallback.
+func (r *Retain) salvageRetained(all map[string]any) error {
+	if r.opts.retainedFallback == nil {
+		return nil
+	}
+
+	for k, v := range r.raw {
+		if json.Valid(v) {
+			continue
+		}
+
+		fixed, err := r.opts.retainedFallback(k, v)
+		if err != nil {
+			return fmt.Errorf("retained field %q: %w", k, err)
+		}
+		if !json.Valid(fixed) {
+			return fmt.Errorf("retained field %q: fallback returned invalid JSON", k)
+		}
+		all[r.retainedKey(k)] = fixed
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRetainedFallback(t *testing.T) {
+	type Obj struct {
+		Name string `json:"name"`
+	}
+
+	tests := []struct {
+		name      string
+		unknown   map[string]string
+		fallback  RetainedFallback
+		wantOut   string
+		wantCalls []string
+		wantErr   string
+	}{
+		{
+			name:    "valid values are unchanged",
+			unknown: map[string]string{"a": `1`, "b": `{"c": true}`},
+			fallback: func(key string, _ []byte) (json.RawMessage, error) {
+				return nil, errors.New("unexpected call")
+			},
+			wantOut: `{"name": "n", "a": 1, "b": {"c": true}}`,
+		},
+		{
+			name:    "invalid value replaced",
+			unknown: map[string]string{"a": `1`, "bad": `{"c":`},
+			fallback: func(key string, badRaw []byte) (json.RawMessage, error) {
+				return json.Marshal(string(badRaw))
+			},
+			wantOut:   `{"name": "n", "a": 1, "bad": "{\"c\":"}`,
+			wantCalls: []string{"bad"},
+		},
+		{
+			name:    "fallback error",
+			unknown: map[string]string{"bad": `nope`},
+			fallback: func(key string, _ []byte) (json.RawMessage, error) {
+				return nil, errors.New("cannot salvage")
+			},
+			wantCalls: []string{"bad"},
+			wantErr:   `retained field "bad": cannot salvage`,
+		},
+		{
+			name:    "fallback returns invalid JSON",
+			unknown: map[string]string{"bad": `nope`},
+			fallback: func(key string, badRaw []byte) (json.RawMessage, error) {
+				return badRaw, nil
+			},
+			wantCalls: []string{"bad"},
+			wantErr:   `retained field "bad": fallback returned invalid JSON`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				r     Retain
+				calls []string
+			)
+			r.Configure(WithRetainedFallback(func(key string, badRaw []byte) (json.RawMessage, error) {
+				calls = append(calls, key)
+				return tt.fallback(key, badRaw)
+			}))
+			for k, v := range tt.unknown {
+				r.SetUnknown(k, json.RawMessage(v))
+			}
+
+			out, err := r.ToJSON(Obj{Name: "n"})
+			assert.Equal(t, tt.wantCalls, calls)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.wantOut, string(out))
+		})
+	}
+}
+
+func TestWithRetainedFallback_Unset(t *testing.T) {
+	var r Retain
+	r.SetUnknown("bad", json.RawMessage(`{`))
+	_, err := r.ToJSON(struct{}{})
+	assert.Error(t, err, "invalid retained values fail without a fallback")
+}
+
+func TestWithRetainedFallback_Sets(t *testing.T) {
+	var r Retain
+	r.Configure(
+		WithRetainedSets("tags"),
+		WithRetainedFallback(func(key string, badRaw []byte) (json.RawMessage, error) {
+			return json.RawMessage(`["b", "a", "b"]`), nil
+		}),
+	)
+	r.SetUnknown("tags", json.RawMessage(`["b", "a"`))
+
+	out, err := r.ToJSON(struct{}{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"tags": ["a", "b"]}`, string(out))
+}
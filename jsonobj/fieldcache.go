@@ -0,0 +1,93 @@
+package jsonobj
+
+import (
+	"maps"
+	"reflect"
+	"sync"
+)
+
+// fieldCache caches the JSON fields of struct types, since parsing tags and
+// resolving conflicts on every FromJSON and ToJSON is expensive.
+var fieldCache sync.Map // map[fieldCacheKey]*typeFields
+
+// fieldCacheKey identifies the fields of a struct type using a tag key.
+type fieldCacheKey struct {
+	rt     reflect.Type
+	tagKey string
+}
+
+// typeFields are the JSON fields of a struct type. They're shared, so
+// they must not be modified.
+type typeFields struct {
+	// all contains all JSON fields, including conflicting fields.
+	all []jsonTag
+
+	// dominant contains the JSON fields after resolving conflicts.
+	dominant []jsonTag
+
+	// names contains the JSON names and aliases of the dominant fields.
+	names map[string]struct{}
+
+	// known contains names, and the prefixed names and aliases of the fields
+	// of prefix fields.
+	known map[string]struct{}
+
+	// folded contains the foldKey of the names of the dominant fields
+	// (other than prefix fields) and the prefixed names of the fields of
+	// prefix fields, which match keys case-insensitively.
+	folded map[string]struct{}
+
+	// index maps the JSON name of each dominant field to its index.
+	index map[string]int
+}
+
+// cachedFields returns the JSON fields of the struct type rt, using the
+// struct tag tagKey.
+func cachedFields(rt reflect.Type, tagKey string) *typeFields {
+	key := fieldCacheKey{rt, tagKey}
+	if f, ok := fieldCache.Load(key); ok {
+		return f.(*typeFields)
+	}
+
+	all := jsonFields(rt, tagKey)
+	dominant := dominantFields(all)
+	names := make(map[string]struct{}, len(dominant))
+	index := make(map[string]int, len(dominant))
+	for i, jt := range dominant {
+		index[jt.name()] = i
+		names[jt.name()] = struct{}{}
+		for _, alias := range jt.aliases {
+			names[alias] = struct{}{}
+		}
+	}
+
+	known := maps.Clone(names)
+	folded := make(map[string]struct{}, len(dominant))
+	for _, jt := range dominant {
+		prefix, ok := fieldPrefix(jt.field)
+		if !ok {
+			folded[foldKey(jt.name())] = struct{}{}
+			continue
+		}
+		if jt.field.Type.Kind() != reflect.Struct {
+			continue
+		}
+		for _, st := range dominantFields(jsonFields(jt.field.Type, tagKey)) {
+			known[prefix+st.name()] = struct{}{}
+			folded[foldKey(prefix+st.name())] = struct{}{}
+			for _, alias := range st.aliases {
+				known[prefix+alias] = struct{}{}
+			}
+		}
+	}
+
+	f, _ := fieldCache.LoadOrStore(key, &typeFields{
+		all:      all,
+		dominant: dominant,
+		names:    names,
+		known:    known,
+		folded:   folded,
+		index:    index,
+	})
+	return f.(*typeFields)
+}
@@ -0,0 +1,151 @@
+package jsonobj
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachedFields(t *testing.T) {
+	// The type is built using reflect, since go vet reports duplicate tags.
+	rt := reflect.StructOf([]reflect.StructField{
+		{Name: "A", Type: reflect.TypeOf(""), Tag: `json:"a,omitempty"`},
+		{Name: "B", Type: reflect.TypeOf(0)},
+		{Name: "C", Type: reflect.TypeOf(false), Tag: `json:"-"`},
+		{Name: "D", Type: reflect.TypeOf(""), Tag: `json:",omitempty"`},
+		{Name: "Dup1", Type: reflect.TypeOf(""), Tag: `json:"dup"`},
+		{Name: "Dup2", Type: reflect.TypeOf(""), Tag: `json:"dup"`},
+		{Name: "inner", PkgPath: "jsonobj", Type: reflect.TypeOf("")},
+	})
+	got := cachedFields(rt, defaultTagKey)
+	assert.Same(t, got, cachedFields(rt, defaultTagKey), "fields should be cached")
+
+	all := jsonFields(rt, defaultTagKey)
+	assert.Equal(t, all, got.all)
+	assert.Equal(t, dominantFields(all), got.dominant)
+
+	var names []string
+	for _, jt := range got.dominant {
+		names = append(names, fmt.Sprintf("%v:%v", jt.name(), jt.omitEmpty()))
+	}
+	assert.Equal(t, []string{"a:true", "B:false", "D:true"}, names)
+}
+
+func TestCachedFields_Concurrent(t *testing.T) {
+	type Obj struct {
+		A string `json:"a"`
+	}
+	rt := reflect.TypeOf(Obj{})
+
+	const goroutines = 8
+	results := make([]*typeFields, goroutines)
+
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = cachedFields(rt, defaultTagKey)
+		}()
+	}
+	wg.Wait()
+
+	for _, f := range results {
+		assert.Same(t, results[0], f)
+	}
+}
+
+// benchObj is a 20-field struct used to benchmark FromJSON and ToJSON.
+type benchObj struct {
+	F01 string  `json:"f01"`
+	F02 string  `json:"f02,omitempty"`
+	F03 int     `json:"f03"`
+	F04 int     `json:"f04,omitempty"`
+	F05 bool    `json:"f05"`
+	F06 float64 `json:"f06"`
+	F07 string  `json:"f07"`
+	F08 string  `json:"f08"`
+	F09 int     `json:"f09"`
+	F10 int     `json:"f10"`
+	F11 string  `json:"f11"`
+	F12 string  `json:"f12,omitempty"`
+	F13 int     `json:"f13"`
+	F14 int     `json:"f14"`
+	F15 bool    `json:"f15"`
+	F16 float64 `json:"f16"`
+	F17 string  `json:"f17"`
+	F18 string  `json:"f18"`
+	F19 int     `json:"f19"`
+	F20 []int   `json:"f20"`
+}
+
+func BenchmarkRetain_20Fields(b *testing.B) {
+	var sb strings.Builder
+	sb.WriteString(`{"unknown1": {"k": "v"}, "unknown2": [1, 2]`)
+	for _, jt := range jsonFields(reflect.TypeOf(benchObj{}), defaultTagKey) {
+		switch jt.field.Type.Kind() {
+		case reflect.String:
+			fmt.Fprintf(&sb, `, %q: "value"`, jt.name())
+		case reflect.Bool:
+			fmt.Fprintf(&sb, `, %q: true`, jt.name())
+		case reflect.Slice:
+			fmt.Fprintf(&sb, `, %q: [1, 2, 3]`, jt.name())
+		default:
+			fmt.Fprintf(&sb, `, %q: 42`, jt.name())
+		}
+	}
+	sb.WriteString("}")
+	input := []byte(sb.String())
+
+	run := func(b *testing.B, clearCache bool) {
+		b.Run("FromJSON", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if clearCache {
+					clearFieldCache()
+				}
+				var (
+					r   Retain
+					obj benchObj
+				)
+				if err := r.FromJSON(input, &obj); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run("ToJSON", func(b *testing.B) {
+			var (
+				r   Retain
+				obj benchObj
+			)
+			require.NoError(b, r.FromJSON(input, &obj))
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if clearCache {
+					clearFieldCache()
+				}
+				if _, err := r.ToJSON(obj); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+
+	b.Run("cached", func(b *testing.B) { run(b, false) })
+	b.Run("uncached", func(b *testing.B) { run(b, true) })
+}
+
+func clearFieldCache() {
+	fieldCache.Range(func(k, _ any) bool {
+		fieldCache.Delete(k)
+		return true
+	})
+}
@@ -0,0 +1,59 @@
+package jsonobj
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FlattenRetained returns the retained fields flattened into a single map with
+// dotted keys. Nested objects are flattened using their keys ("meta.owner.id"),
+// and arrays using their indexes ("tags.0"). Scalars are decoded as they are
+// by encoding/json, except numbers are decoded as json.Number to preserve
+// precision. Empty objects and arrays are kept as empty values.
+//
+// Keys containing "." or "\" are escaped by prefixing those characters
+// with "\", so the key "a.b" in the object "meta" is flattened to "meta.a\.b".
+func (r *Retain) FlattenRetained() (map[string]any, error) {
+	flat := make(map[string]any)
+	for k, v := range r.raw {
+		var value any
+		dec := json.NewDecoder(bytes.NewReader(v))
+		dec.UseNumber()
+		if err := dec.Decode(&value); err != nil {
+			return nil, fmt.Errorf("retained field %q: %w", k, err)
+		}
+
+		flatten(flat, escapeFlatKey(k), value)
+	}
+	return flat, nil
+}
+
+func flatten(flat map[string]any, prefix string, value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			flat[prefix] = v
+		}
+		for k, elem := range v {
+			flatten(flat, prefix+"."+escapeFlatKey(k), elem)
+		}
+	case []any:
+		if len(v) == 0 {
+			flat[prefix] = v
+		}
+		for i, elem := range v {
+			flatten(flat, prefix+"."+strconv.Itoa(i), elem)
+		}
+	default:
+		flat[prefix] = v
+	}
+}
+
+var flatKeyEscaper = strings.NewReplacer(`\`, `\\`, `.`, `\.`)
+
+func escapeFlatKey(k string) string {
+	return flatKeyEscaper.Replace(k)
+}
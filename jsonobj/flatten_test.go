@@ -0,0 +1,77 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetain_FlattenRetained(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want map[string]any
+	}{
+		{
+			name: "no retained fields",
+			json: `{"name": "foo"}`,
+			want: map[string]any{},
+		},
+		{
+			name: "scalars",
+			json: `{"name": "foo", "str": "s", "num": 12345678901234567890, "bool": true, "null": null}`,
+			want: map[string]any{
+				"str":  "s",
+				"num":  json.Number("12345678901234567890"),
+				"bool": true,
+				"null": nil,
+			},
+		},
+		{
+			name: "nested",
+			json: `{"meta": {"owner": {"id": 1}, "tags": ["a", {"b": true}]}}`,
+			want: map[string]any{
+				"meta.owner.id": json.Number("1"),
+				"meta.tags.0":   "a",
+				"meta.tags.1.b": true,
+			},
+		},
+		{
+			name: "empty containers",
+			json: `{"obj": {}, "list": []}`,
+			want: map[string]any{
+				"obj":  map[string]any{},
+				"list": []any{},
+			},
+		},
+		{
+			name: "escaped keys",
+			json: `{"a.b": {"c\\d": 1, "e": {"f.g": 2}}, "a": {"b": 3}}`,
+			want: map[string]any{
+				`a\.b.c\\d`:   json.Number("1"),
+				`a\.b.e.f\.g`: json.Number("2"),
+				`a.b`:         json.Number("3"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s S
+			require.NoError(t, json.Unmarshal([]byte(tt.json), &s))
+
+			got, err := s.raw.FlattenRetained()
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRetain_FlattenRetained_Invalid(t *testing.T) {
+	var r Retain
+	r.SetUnknown("bad", json.RawMessage(`{`))
+	_, err := r.FlattenRetained()
+	assert.ErrorContains(t, err, `retained field "bad": unexpected EOF`)
+}
@@ -0,0 +1,20 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func mustMarshal(t testing.TB, v any) string {
+	t.Helper()
+
+	got, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(got)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
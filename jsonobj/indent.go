@@ -0,0 +1,66 @@
+package jsonobj
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+type indentation struct {
+	prefix string
+	indent string
+}
+
+// WithIndent indents the output of ToJSON as json.MarshalIndent does.
+//
+// encoding/json compacts the output of MarshalJSON methods, so this is only
+// useful when calling ToJSON directly, typically as a per-call option:
+//
+//	r.ToJSON(obj, jsonobj.WithIndent("", "  "))
+func WithIndent(prefix, indent string) Option {
+	return func(o *options) {
+		o.indent = &indentation{prefix: prefix, indent: indent}
+	}
+}
+
+// ToJSONIndent is like ToJSON, but indents the output as json.MarshalIndent
+// does. The output is equivalent to the compact output of ToJSON indented
+// using json.Indent, so keys are in the same order.
+func (r *Retain) ToJSONIndent(obj any, prefix, indent string) ([]byte, error) {
+	return r.ToJSON(obj, WithIndent(prefix, indent))
+}
+
+// WithTrailingNewline appends a newline to the output of ToJSON, for writing
+// files that should end with a newline.
+//
+// encoding/json compacts the output of MarshalJSON methods, so the newline is
+// dropped when the object is marshalled by json.Marshal or json.Encoder (which
+// adds its own newline), and output never ends with multiple newlines.
+func WithTrailingNewline() Option {
+	return func(o *options) {
+		o.trailingNewline = true
+	}
+}
+
+// formatOutput applies the configured indentation and trailing newline.
+func (r *Retain) formatOutput(data []byte) ([]byte, error) {
+	data, err := r.indent(data)
+	if err != nil {
+		return nil, err
+	}
+	if r.opts.trailingNewline {
+		data = append(data, '\n')
+	}
+	return data, nil
+}
+
+func (r *Retain) indent(data []byte) ([]byte, error) {
+	if r.opts.indent == nil {
+		return data, nil
+	}
+
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, r.opts.indent.prefix, r.opts.indent.indent); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
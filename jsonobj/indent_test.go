@@ -0,0 +1,88 @@
+package jsonobj
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithIndent(t *testing.T) {
+	var (
+		r Retain
+		s S
+	)
+	require.NoError(t, r.FromJSON([]byte(`{"name": "foo", "meta": {"a": [1, 2]}}`), &s))
+
+	r.Configure(WithIndent(">", "\t"))
+	got, err := r.ToJSON(&s)
+	require.NoError(t, err)
+	assert.Equal(t, "{\n>\t\"name\": \"foo\",\n>\t\"meta\": {\n>\t\t\"a\": [\n>\t\t\t1,\n>\t\t\t2\n>\t\t]\n>\t}\n>}", string(got))
+}
+
+func TestToJSONIndent(t *testing.T) {
+	var (
+		r Retain
+		s S
+	)
+	require.NoError(t, r.FromJSON([]byte(`{"z": 1, "name": "foo", "a": {"c": [], "b": {}}}`), &s))
+
+	got, err := r.ToJSONIndent(&s, "", "  ")
+	require.NoError(t, err)
+	assert.Equal(t, `{
+  "z": 1,
+  "name": "foo",
+  "a": {
+    "c": [],
+    "b": {}
+  }
+}`, string(got))
+
+	compact, err := r.ToJSON(&s)
+	require.NoError(t, err)
+	assert.NotContains(t, string(compact), "\n", "ToJSONIndent should not modify the configured options")
+
+	var want bytes.Buffer
+	require.NoError(t, json.Indent(&want, compact, "", "  "))
+	assert.Equal(t, want.String(), string(got), "should match json.Indent of ToJSON")
+}
+
+func TestWithTrailingNewline(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{
+			name: "plain",
+			opts: []Option{WithTrailingNewline()},
+			want: "{\"name\":\"foo\",\"a\":1}\n",
+		},
+		{
+			name: "indented",
+			opts: []Option{WithTrailingNewline(), WithIndent("", "  ")},
+			want: "{\n  \"name\": \"foo\",\n  \"a\": 1\n}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s S
+			s.raw.Configure(tt.opts...)
+			require.NoError(t, json.Unmarshal([]byte(`{"name": "foo", "a": 1}`), &s))
+
+			got, err := s.raw.ToJSON(&s)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+
+			// encoding/json drops the newline, so there's no double newline.
+			assert.Equal(t, `{"name":"foo","a":1}`, mustMarshal(t, &s))
+
+			var buf bytes.Buffer
+			require.NoError(t, json.NewEncoder(&buf).Encode(&s))
+			assert.Equal(t, "{\"name\":\"foo\",\"a\":1}\n", buf.String())
+		})
+	}
+}
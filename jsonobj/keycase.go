@@ -0,0 +1,128 @@
+package jsonobj
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// KeyCase is a naming convention for JSON keys.
+type KeyCase int
+
+const (
+	// CamelCase keys start with a lowercase word, followed by capitalized
+	// words with no separators, such as "userId".
+	CamelCase KeyCase = iota + 1
+
+	// SnakeCase keys are lowercase words separated by "_", such as "user_id".
+	SnakeCase
+
+	// KebabCase keys are lowercase words separated by "-", such as "user-id".
+	KebabCase
+)
+
+// String returns the name of the key case.
+func (c KeyCase) String() string {
+	switch c {
+	case CamelCase:
+		return "CamelCase"
+	case SnakeCase:
+		return "SnakeCase"
+	case KebabCase:
+		return "KebabCase"
+	default:
+		return fmt.Sprintf("KeyCase(%d)", int(c))
+	}
+}
+
+// Convert converts key to the key case. Words in key are separated by any
+// of "_", "-", "." or spaces, and by case changes. Acronyms are treated as
+// a single word ("HTTPServer" is "HTTP" and "Server"), and digits are part of
+// the preceding word ("utf8Name" is "utf8" and "Name").
+func (c KeyCase) Convert(key string) string {
+	words := splitWords(key)
+	for i, w := range words {
+		w = strings.ToLower(w)
+		if c == CamelCase && i > 0 {
+			w = capitalize(w)
+		}
+		words[i] = w
+	}
+
+	switch c {
+	case SnakeCase:
+		return strings.Join(words, "_")
+	case KebabCase:
+		return strings.Join(words, "-")
+	default:
+		return strings.Join(words, "")
+	}
+}
+
+// WithOutputKeyCase converts all keys output by ToJSON, both known and
+// retained, to the key case c. ToJSON fails if multiple keys convert to the
+// same key. FromJSON is unaffected.
+func WithOutputKeyCase(c KeyCase) Option {
+	return func(o *options) {
+		o.outputKeyCase = &c
+	}
+}
+
+func convertKeys(m map[string]any, c *KeyCase) (map[string]any, error) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	converted := make(map[string]any, len(m))
+	sources := make(map[string]string, len(m))
+	for _, k := range keys {
+		ck := c.Convert(k)
+		if prev, ok := sources[ck]; ok {
+			return nil, fmt.Errorf("keys %q and %q both convert to %v key %q", prev, k, c, ck)
+		}
+
+		sources[ck] = k
+		converted[ck] = m[k]
+	}
+	return converted, nil
+}
+
+func splitWords(s string) []string {
+	var (
+		words []string
+		cur   []rune
+	)
+	flush := func() {
+		if len(cur) > 0 {
+			words = append(words, string(cur))
+			cur = nil
+		}
+	}
+
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || r == '.' || unicode.IsSpace(r):
+			flush()
+			continue
+		case unicode.IsUpper(r) && i > 0:
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				flush()
+			}
+		}
+		cur = append(cur, r)
+	}
+	flush()
+	return words
+}
+
+func capitalize(w string) string {
+	runes := []rune(w)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
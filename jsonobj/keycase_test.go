@@ -0,0 +1,85 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyCase_Convert(t *testing.T) {
+	tests := []struct {
+		key       string
+		wantCamel string
+		wantSnake string
+		wantKebab string
+	}{
+		{"", "", "", ""},
+		{"name", "name", "name", "name"},
+		{"userId", "userId", "user_id", "user-id"},
+		{"user_id", "userId", "user_id", "user-id"},
+		{"user-id", "userId", "user_id", "user-id"},
+		{"UserID", "userId", "user_id", "user-id"},
+		{"HTTPServer", "httpServer", "http_server", "http-server"},
+		{"parseHTTPRequest", "parseHttpRequest", "parse_http_request", "parse-http-request"},
+		{"utf8Name", "utf8Name", "utf8_name", "utf8-name"},
+		{"HTTP2Server", "http2Server", "http2_server", "http2-server"},
+		{"v2", "v2", "v2", "v2"},
+		{"address_line_1", "addressLine1", "address_line_1", "address-line-1"},
+		{"user.first name", "userFirstName", "user_first_name", "user-first-name"},
+		{"__private", "private", "private", "private"},
+		{"ÜberName", "überName", "über_name", "über-name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			assert.Equal(t, tt.wantCamel, CamelCase.Convert(tt.key), "CamelCase")
+			assert.Equal(t, tt.wantSnake, SnakeCase.Convert(tt.key), "SnakeCase")
+			assert.Equal(t, tt.wantKebab, KebabCase.Convert(tt.key), "KebabCase")
+		})
+	}
+}
+
+func TestWithOutputKeyCase(t *testing.T) {
+	type Obj struct {
+		UserID   int    `json:"user_id"`
+		FullName string `json:"FullName"`
+	}
+
+	input := `{"user_id": 1, "FullName": "foo", "created-at": "now", "nested": {"inner_key": 1}}`
+
+	tests := []struct {
+		keyCase KeyCase
+		want    string
+	}{
+		{CamelCase, `{"userId": 1, "fullName": "foo", "createdAt": "now", "nested": {"inner_key": 1}}`},
+		{SnakeCase, `{"user_id": 1, "full_name": "foo", "created_at": "now", "nested": {"inner_key": 1}}`},
+		{KebabCase, `{"user-id": 1, "full-name": "foo", "created-at": "now", "nested": {"inner_key": 1}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.keyCase.String(), func(t *testing.T) {
+			var (
+				obj Obj
+				r   Retain
+			)
+			r.Configure(WithOutputKeyCase(tt.keyCase))
+			require.NoError(t, r.FromJSON([]byte(input), &obj))
+			assert.Equal(t, Obj{UserID: 1, FullName: "foo"}, obj, "decode is unaffected")
+
+			got, err := r.ToJSON(obj)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+
+	t.Run("collision", func(t *testing.T) {
+		var s S
+		s.raw.Configure(WithOutputKeyCase(SnakeCase))
+		require.NoError(t, json.Unmarshal([]byte(`{"userId": 1, "user_id": 2}`), &s))
+
+		_, err := json.Marshal(&s)
+		assert.ErrorContains(t, err, `keys "userId" and "user_id" both convert to SnakeCase key "user_id"`)
+	})
+}
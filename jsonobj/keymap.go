@@ -0,0 +1,110 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// WithRetainedKeyMapping renames retained keys: FromJSON renames the keys of
+// unknown fields using input, and ToJSON renames retained keys using output.
+// For example, to retain the snake_case fields of an upstream API, but output
+// them to clients in camelCase:
+//
+//	r.Configure(jsonobj.WithRetainedKeyMapping(
+//		jsonobj.CamelCase.Convert, // output
+//		jsonobj.SnakeCase.Convert, // input
+//	))
+//
+// Retained fields are accessed (using GetUnknown, SetUnknown and others) by
+// the keys returned by input. Known fields are unaffected, since they're
+// named by their tags.
+//
+// output and input should be inverses of each other, so that objects
+// round-trip. Mappings that aren't invertible may lose fields: FromJSON and
+// ToJSON fail if multiple retained keys map to the same key, and a retained
+// key that output maps to the name of a known field is replaced by the known
+// field. Keys that don't round-trip also lose their input order.
+func WithRetainedKeyMapping(output, input func(string) string) Option {
+	return func(o *options) {
+		o.retainedKeyOutput = output
+		o.retainedKeyInput = input
+	}
+}
+
+// mapRetainedKeys renames the retained keys using the configured input
+// mapping, and updates the order to use the renamed output keys.
+func (r *Retain) mapRetainedKeys() error {
+	input := r.opts.retainedKeyInput
+	if input == nil {
+		return nil
+	}
+
+	keys := make([]string, 0, len(r.raw))
+	for k := range r.raw {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var (
+		retained = make(map[string]json.RawMessage, len(r.raw))
+		sources  = make(map[string]string, len(r.raw))
+		renamed  = make(map[string]string, len(r.raw))
+		stripped map[string]struct{}
+	)
+	for _, k := range keys {
+		key := input(k)
+		if prev, ok := sources[key]; ok {
+			return fmt.Errorf("retained keys %q and %q both map to %q", prev, k, key)
+		}
+		sources[key] = k
+		retained[key] = r.raw[k]
+
+		// The order contains keys as they were in the input.
+		if _, ok := r.stripped[k]; ok {
+			if stripped == nil {
+				stripped = make(map[string]struct{})
+			}
+			stripped[key] = struct{}{}
+			renamed[r.opts.stripPrefix+k] = key
+		} else {
+			renamed[k] = key
+		}
+	}
+	r.raw = retained
+	r.stripped = stripped
+
+	for i, k := range r.order {
+		if key, ok := renamed[k]; ok {
+			r.order[i] = r.retainedKey(key)
+		}
+	}
+	return nil
+}
+
+// addRetainedFields adds the retained fields to all using their output keys.
+func (r *Retain) addRetainedFields(all map[string]any) error {
+	if r.opts.retainedKeyOutput == nil {
+		for k, v := range r.raw {
+			all[r.retainedKey(k)] = v
+		}
+		return nil
+	}
+
+	keys := make([]string, 0, len(r.raw))
+	for k := range r.raw {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	sources := make(map[string]string, len(r.raw))
+	for _, k := range keys {
+		key := r.retainedKey(k)
+		if prev, ok := sources[key]; ok {
+			return fmt.Errorf("retained keys %q and %q both map to %q", prev, k, key)
+		}
+		sources[key] = k
+		all[key] = r.raw[k]
+	}
+	return nil
+}
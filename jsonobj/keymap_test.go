@@ -0,0 +1,64 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRetainedKeyMapping(t *testing.T) {
+	var s S
+	s.raw.Configure(WithRetainedKeyMapping(CamelCase.Convert, SnakeCase.Convert))
+	require.NoError(t, s.raw.FromJSON([]byte(`{"future_field": 1, "name": "foo", "otherField": [2]}`), &s))
+
+	assert.Equal(t, "foo", s.Name)
+	got, ok := s.raw.GetUnknown("future_field")
+	require.True(t, ok, "unknown fields should be retained using the input mapping")
+	assert.Equal(t, `1`, string(got))
+	assert.Equal(t, []string{"future_field", "other_field"}, s.raw.UnknownKeys())
+
+	s.raw.SetUnknown("added_field", json.RawMessage(`true`))
+	out, err := s.raw.ToJSON(&s)
+	require.NoError(t, err)
+	assert.Equal(t, `{"futureField":1,"name":"foo","otherField":[2],"addedField":true}`, string(out))
+
+	// Decoding the output retains the same keys.
+	var s2 S
+	s2.raw.Configure(WithRetainedKeyMapping(CamelCase.Convert, SnakeCase.Convert))
+	require.NoError(t, s2.raw.FromJSON(out, &s2))
+	assert.Equal(t, []string{"added_field", "future_field", "other_field"}, s2.raw.UnknownKeys())
+
+	out2, err := s2.raw.ToJSON(&s2)
+	require.NoError(t, err)
+	assert.Equal(t, string(out), string(out2))
+}
+
+func TestWithRetainedKeyMapping_StripPrefix(t *testing.T) {
+	var s S
+	s.raw.Configure(
+		WithStripPrefix("x_"),
+		WithRetainedKeyMapping(strings.ToUpper, strings.ToLower),
+	)
+	require.NoError(t, s.raw.FromJSON([]byte(`{"x_Foo": 1, "Bar": 2}`), &s))
+	assert.Equal(t, []string{"bar", "foo"}, s.raw.UnknownKeys())
+
+	out, err := s.raw.ToJSON(&s)
+	require.NoError(t, err)
+	assert.Equal(t, `{"x_FOO":1,"BAR":2}`, string(out))
+}
+
+func TestWithRetainedKeyMapping_Collisions(t *testing.T) {
+	var s S
+	s.raw.Configure(WithRetainedKeyMapping(CamelCase.Convert, SnakeCase.Convert))
+	err := s.raw.FromJSON([]byte(`{"fooBar": 1, "foo_bar": 2}`), &s)
+	assert.EqualError(t, err, `retained keys "fooBar" and "foo_bar" both map to "foo_bar"`)
+
+	var s2 S
+	s2.raw.Configure(WithRetainedKeyMapping(CamelCase.Convert, func(k string) string { return k }))
+	require.NoError(t, s2.raw.FromJSON([]byte(`{"fooBar": 1, "foo_bar": 2}`), &s2))
+	_, err = s2.raw.ToJSON(&s2)
+	assert.EqualError(t, err, `retained keys "fooBar" and "foo_bar" both map to "fooBar"`)
+}
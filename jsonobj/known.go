@@ -0,0 +1,30 @@
+package jsonobj
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// KnownFields returns the JSON names of the known fields of the struct type of
+// obj, in declaration order, such as for generating a schema. Names are
+// resolved as they are by FromJSON and ToJSON: fields tagged "-" are skipped,
+// and fields of embedded structs are promoted unless they're shadowed.
+//
+// Only the type of obj is used, so obj may be a nil struct pointer. Use
+// Retain.UnknownKeys for the retained fields of a decoded object.
+func KnownFields(obj any, opts ...Option) ([]string, error) {
+	rt := reflect.TypeOf(obj)
+	if rt != nil && rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	if rt == nil || rt.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("KnownFields requires a struct, got %T", obj)
+	}
+
+	fields := cachedFields(rt, options{}.with(opts).structTagKey()).dominant
+	names := make([]string, 0, len(fields))
+	for _, t := range fields {
+		names = append(names, t.name())
+	}
+	return names, nil
+}
@@ -0,0 +1,71 @@
+package jsonobj
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKnownFields(t *testing.T) {
+	type Embedded struct {
+		Zone   string `json:"zone"`
+		Shadow string `json:"name"`
+	}
+	type knownS struct {
+		raw Retain
+
+		Name     string `json:"name"`
+		ID       int    `json:"id,omitempty"`
+		Ignored  string `json:"-"`
+		Dash     string `json:"-,"`
+		Untagged bool
+		Embedded
+		Tagged string `api:"tagged_name"`
+	}
+
+	tests := []struct {
+		name string
+		obj  any
+		opts []Option
+		want []string
+	}{
+		{
+			name: "struct",
+			obj:  knownS{},
+			want: []string{"name", "id", "-", "Untagged", "zone", "Tagged"},
+		},
+		{
+			name: "nil struct pointer",
+			obj:  (*knownS)(nil),
+			want: []string{"name", "id", "-", "Untagged", "zone", "Tagged"},
+		},
+		{
+			name: "tag key",
+			obj:  &knownS{},
+			opts: []Option{WithTagKey("api")},
+			want: []string{"Name", "ID", "Ignored", "Dash", "Untagged", "Zone", "Shadow", "tagged_name"},
+		},
+		{
+			name: "no fields",
+			obj:  struct{}{},
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := KnownFields(tt.obj, tt.opts...)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestKnownFields_Errors(t *testing.T) {
+	_, err := KnownFields("str")
+	assert.EqualError(t, err, "KnownFields requires a struct, got string")
+
+	_, err = KnownFields(nil)
+	assert.EqualError(t, err, "KnownFields requires a struct, got <nil>")
+}
@@ -0,0 +1,90 @@
+package jsonobj
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// WithWorkLimit limits FromJSON to inputs with at most maxTokens JSON tokens,
+// where each delimiter ({, }, [, ]), object key and value is a token. The
+// input is scanned before it's decoded, and the scan stops as soon as the
+// limit is exceeded, which bounds the work done for pathological inputs.
+func WithWorkLimit(maxTokens int) Option {
+	return func(o *options) {
+		o.workLimit = maxTokens
+	}
+}
+
+// WithMaxInputSize limits FromJSON to inputs of at most maxBytes bytes.
+// Larger inputs fail before they're decoded. By default, the input size
+// is unlimited.
+func WithMaxInputSize(maxBytes int) Option {
+	return func(o *options) {
+		o.maxInputSize = maxBytes
+	}
+}
+
+// WithMaxUnknownFields limits FromJSON to inputs with at most maxFields
+// unknown fields, which bounds the size of the retained fields. The limit is
+// checked before any fields are decoded, so the object isn't modified if it's
+// exceeded. By default, the number of unknown fields is unlimited.
+//
+// Keys that match a known field (including case-insensitively, and using
+// aliases), a field of a `jsonobj:"prefix=..."` field, or an Extensions field
+// are not counted. Duplicate keys are counted once.
+func WithMaxUnknownFields(maxFields int) Option {
+	return func(o *options) {
+		o.maxUnknownFields = maxFields
+	}
+}
+
+func checkInputSize(data []byte, maxBytes int) error {
+	if len(data) > maxBytes {
+		return fmt.Errorf("input size %v exceeds limit of %v bytes", len(data), maxBytes)
+	}
+	return nil
+}
+
+// checkUnknownLimit returns an error if r.raw has more than maxUnknownFields
+// keys that don't match a known field of fields or an extension field. It runs
+// before any fields are decoded. folded indexes the keys of r.raw, see
+// foldKeys.
+func (r *Retain) checkUnknownLimit(fields *typeFields, folded map[string][]string) error {
+	maxFields := r.opts.maxUnknownFields
+	if maxFields <= 0 {
+		return nil
+	}
+
+	extNames := r.extensionNames()
+	unknown := 0
+	for fk, keys := range folded {
+		_, foldMatch := fields.folded[fk]
+		for _, k := range keys {
+			if _, ok := fields.known[k]; ok || foldMatch {
+				continue
+			}
+			if _, ok := extNames[k]; ok {
+				continue
+			}
+			if unknown++; unknown > maxFields {
+				return fmt.Errorf("input exceeds limit of %v unknown fields", maxFields)
+			}
+		}
+	}
+	return nil
+}
+
+func checkWorkLimit(data []byte, maxTokens int) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for tokens := 0; ; tokens++ {
+		if tokens > maxTokens {
+			return fmt.Errorf("input exceeds work limit of %v tokens", maxTokens)
+		}
+
+		if _, err := dec.Token(); err != nil {
+			// Either io.EOF, or a syntax error that's reported when decoding.
+			return nil
+		}
+	}
+}
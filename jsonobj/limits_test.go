@@ -0,0 +1,122 @@
+package jsonobj
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithWorkLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		wantErr string
+	}{
+		{
+			// {, "name", "foo", }
+			name: "at limit",
+			json: `{"name": "foo"}`,
+		},
+		{
+			name:    "over limit",
+			json:    `{"name": "foo", "a": 1}`,
+			wantErr: "input exceeds work limit of 4 tokens",
+		},
+		{
+			name:    "deeply nested",
+			json:    strings.Repeat("[", 1000) + strings.Repeat("]", 1000),
+			wantErr: "input exceeds work limit of 4 tokens",
+		},
+		{
+			name:    "invalid JSON",
+			json:    `{"name"}`,
+			wantErr: "invalid character",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s S
+			s.raw.Configure(WithWorkLimit(4))
+			err := s.raw.FromJSON([]byte(tt.json), &s)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestWithMaxInputSize(t *testing.T) {
+	var s S
+	s.raw.Configure(WithMaxInputSize(16))
+	require.NoError(t, s.raw.FromJSON([]byte(`{"name": "foo"}`), &s))
+
+	err := s.raw.FromJSON([]byte(`{"name": "foobar"}`), &s)
+	assert.EqualError(t, err, "input size 18 exceeds limit of 16 bytes")
+}
+
+func TestWithMaxUnknownFields(t *testing.T) {
+	type prefixed struct {
+		A string `json:"a"`
+	}
+	type limitS struct {
+		raw Retain
+
+		Name   string   `json:"name" jsonobj_aliases:"title"`
+		Labels prefixed `json:"labels" jsonobj:"prefix=label_"`
+	}
+
+	tests := []struct {
+		name    string
+		json    string
+		wantErr string
+	}{
+		{
+			name: "known fields are not counted",
+			json: `{"name": "foo", "NAME": "bar", "title": "baz", "label_a": "x", "u1": 1, "u2": 2}`,
+		},
+		{
+			name: "duplicate keys",
+			json: `{"u1": 1, "u2": 2, "u1": 3}`,
+		},
+		{
+			name:    "over limit",
+			json:    `{"u1": 1, "u2": 2, "name": "foo", "u3": 3}`,
+			wantErr: "input exceeds limit of 2 unknown fields",
+		},
+		{
+			name:    "unknown prefixed key",
+			json:    `{"u1": 1, "u2": 2, "label_b": "y"}`,
+			wantErr: "input exceeds limit of 2 unknown fields",
+		},
+		{
+			name:    "invalid JSON",
+			json:    `{"u1": }`,
+			wantErr: "invalid character",
+		},
+		{
+			name:    "not object",
+			json:    `[1]`,
+			wantErr: "cannot unmarshal array",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s limitS
+			s.raw.Configure(WithMaxUnknownFields(2))
+			err := s.raw.FromJSON([]byte(tt.json), &s)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				assert.Empty(t, s.Name, "fields should not be decoded")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, []string{"u1", "u2"}, s.raw.UnknownKeys())
+		})
+	}
+}
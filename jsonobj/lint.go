@@ -0,0 +1,163 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var (
+	marshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	retainType      = reflect.TypeOf(Retain{})
+)
+
+// LintTypes checks that each of the provided objects is correctly wired up for
+// Retain marshalling, and returns all problems found. Each object should
+// be a pointer to a retainable struct, such as &ObjType{}. In addition to
+// the Retainable checks, it verifies that:
+//  * The type has MarshalJSON and UnmarshalJSON methods.
+//  * UnmarshalJSON has a pointer receiver, so it modifies the object.
+//
+// It's intended to be called from a test listing all retainable types.
+func LintTypes(types ...any) []error {
+	var errs []error
+	for _, obj := range types {
+		errs = append(errs, lintType(obj)...)
+	}
+	return errs
+}
+
+// LintValueTypes is like LintTypes, but also verifies that MarshalJSON has
+// a value receiver. encoding/json only calls pointer receiver methods on
+// addressable values, so a type with a pointer receiver MarshalJSON is
+// silently encoded without its unknown fields when stored by value in a map
+// (such as map[string]Page) or passed by value to json.Marshal.
+//
+// Types with a value receiver MarshalJSON and a pointer receiver
+// UnmarshalJSON are safe to use in value containers.
+func LintValueTypes(types ...any) []error {
+	var errs []error
+	for _, obj := range types {
+		errs = append(errs, lintType(obj)...)
+
+		rt := reflect.TypeOf(obj)
+		if rt != nil && rt.Kind() == reflect.Pointer && rt.Implements(marshalerType) && !rt.Elem().Implements(marshalerType) {
+			errs = append(errs, fmt.Errorf("%T: MarshalJSON must have a value receiver to be used in value containers", obj))
+		}
+	}
+	return errs
+}
+
+func lintType(obj any) []error {
+	rt := reflect.TypeOf(obj)
+	if rt == nil || rt.Kind() != reflect.Pointer || rt.Elem().Kind() != reflect.Struct {
+		return []error{fmt.Errorf("%T: requires struct pointer", obj)}
+	}
+
+	var errs []error
+	addErr := func(msg string) {
+		errs = append(errs, fmt.Errorf("%T: %v", obj, msg))
+	}
+
+	st := rt.Elem()
+	if !rt.Implements(marshalerType) {
+		addErr("missing MarshalJSON method")
+	}
+	if !rt.Implements(unmarshalerType) {
+		addErr("missing UnmarshalJSON method")
+	} else if st.Implements(unmarshalerType) {
+		addErr("UnmarshalJSON must have a pointer receiver to modify the object")
+	}
+	if v, ok := obj.(interface {
+		json.Marshaler
+		json.Unmarshaler
+	}); ok {
+		// Retainable also checks the Retain field.
+		if err := Retainable(v); err != nil {
+			errs = append(errs, err)
+		}
+	} else if err := verifyOneRetainField(st); err != nil {
+		addErr(err.Error())
+	}
+
+	return errs
+}
+
+// hasRetainField returns whether the struct st has a field that holds
+// unknown fields, either a Retain or a type embedding Retain (such as
+// Extensions).
+func hasRetainField(st reflect.Type) bool {
+	for f := 0; f < st.NumField(); f++ {
+		if isRetainType(st.Field(f).Type) {
+			return true
+		}
+	}
+	return false
+}
+
+func isRetainType(t reflect.Type) bool {
+	if t == retainType {
+		return true
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+
+	for f := 0; f < t.NumField(); f++ {
+		if ft := t.Field(f); ft.Anonymous && ft.Type == retainType {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyOneRetainField returns an error unless the struct st has exactly one
+// field that holds unknown fields, including fields of embedded structs.
+func verifyOneRetainField(st reflect.Type) error {
+	paths := retainFieldPaths(nil, st, "", nil)
+	switch len(paths) {
+	case 0:
+		return errors.New("no Retain field, unknown fields are not retained")
+	case 1:
+		return nil
+	default:
+		return fmt.Errorf("found %v Retain fields (%v), expected exactly one", len(paths), strings.Join(paths, ", "))
+	}
+}
+
+// retainFieldPaths appends the paths of fields of the struct st that hold
+// unknown fields to paths, recursing into embedded structs.
+// visiting contains the embedded struct types being walked, to avoid cycles.
+func retainFieldPaths(paths []string, st reflect.Type, prefix string, visiting map[reflect.Type]struct{}) []string {
+	for f := 0; f < st.NumField(); f++ {
+		ft := st.Field(f)
+		if isRetainType(ft.Type) {
+			paths = append(paths, prefix+ft.Name)
+			continue
+		}
+		if !ft.Anonymous {
+			continue
+		}
+
+		et := ft.Type
+		if et.Kind() == reflect.Pointer {
+			et = et.Elem()
+		}
+		if et.Kind() != reflect.Struct {
+			continue
+		}
+		if _, ok := visiting[et]; ok {
+			continue
+		}
+		if visiting == nil {
+			visiting = make(map[reflect.Type]struct{})
+		}
+		visiting[et] = struct{}{}
+		paths = retainFieldPaths(paths, et, prefix+ft.Name+".", visiting)
+		delete(visiting, et)
+	}
+	return paths
+}
@@ -0,0 +1,174 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type lintValueUnmarshal struct {
+	raw Retain
+}
+
+func (l lintValueUnmarshal) UnmarshalJSON(data []byte) error {
+	return l.raw.FromJSON(data, &l)
+}
+
+func (l lintValueUnmarshal) MarshalJSON() ([]byte, error) {
+	return l.raw.ToJSON(l)
+}
+
+type lintNoRetain struct {
+	Name string
+}
+
+func (l *lintNoRetain) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &l.Name)
+}
+
+func (l *lintNoRetain) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.Name)
+}
+
+type lintNoMethods struct {
+	raw Retain
+}
+
+type lintDuplicate struct {
+	S
+
+	A string
+	B string `json:"A"`
+}
+
+func TestLintTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		types    []any
+		wantErrs []string
+	}{
+		{
+			name:  "valid",
+			types: []any{&S{}, &rawInputS{}},
+		},
+		{
+			name:  "not struct pointer",
+			types: []any{S{}, "str", nil},
+			wantErrs: []string{
+				"jsonobj.S: requires struct pointer",
+				"string: requires struct pointer",
+				"<nil>: requires struct pointer",
+			},
+		},
+		{
+			name:  "value receiver",
+			types: []any{&lintValueUnmarshal{}},
+			wantErrs: []string{
+				"*jsonobj.lintValueUnmarshal: UnmarshalJSON must have a pointer receiver to modify the object",
+			},
+		},
+		{
+			name:  "missing Retain",
+			types: []any{&lintNoRetain{}},
+			wantErrs: []string{
+				"*jsonobj.lintNoRetain not Retainable: no Retain field, unknown fields are not retained",
+			},
+		},
+		{
+			name:  "missing methods",
+			types: []any{&lintNoMethods{}},
+			wantErrs: []string{
+				"*jsonobj.lintNoMethods: missing MarshalJSON method",
+				"*jsonobj.lintNoMethods: missing UnmarshalJSON method",
+			},
+		},
+		{
+			name:  "not Retainable",
+			types: []any{&lintDuplicate{}},
+			wantErrs: []string{
+				`*jsonobj.lintDuplicate not Retainable: duplicate JSON field "A"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, err := range LintTypes(tt.types...) {
+				got = append(got, err.Error())
+			}
+			assert.Equal(t, tt.wantErrs, got)
+		})
+	}
+}
+
+func TestLintValueTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		types    []any
+		wantErrs []string
+	}{
+		{
+			name:  "value receiver MarshalJSON",
+			types: []any{&lintValueMarshal{}},
+		},
+		{
+			name:  "pointer receiver MarshalJSON",
+			types: []any{&S{}, &lintValueMarshal{}},
+			wantErrs: []string{
+				"*jsonobj.S: MarshalJSON must have a value receiver to be used in value containers",
+			},
+		},
+		{
+			name:  "includes LintTypes errors",
+			types: []any{&lintNoRetain{}, "str"},
+			wantErrs: []string{
+				"*jsonobj.lintNoRetain not Retainable: no Retain field, unknown fields are not retained",
+				"*jsonobj.lintNoRetain: MarshalJSON must have a value receiver to be used in value containers",
+				"string: requires struct pointer",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, err := range LintValueTypes(tt.types...) {
+				got = append(got, err.Error())
+			}
+			assert.Equal(t, tt.wantErrs, got)
+		})
+	}
+}
+
+func TestValueContainers(t *testing.T) {
+	const input = `{"a": {"name": "a", "extra": 1}}`
+
+	t.Run("value receiver", func(t *testing.T) {
+		var m map[string]lintValueMarshal
+		require.NoError(t, json.Unmarshal([]byte(input), &m))
+		assert.JSONEq(t, input, mustMarshal(t, m))
+	})
+
+	t.Run("pointer receiver drops unknown fields", func(t *testing.T) {
+		var m map[string]S
+		require.NoError(t, json.Unmarshal([]byte(`{"a": {"name": "x", "extra": 1}}`), &m))
+		assert.NotContains(t, mustMarshal(t, m), "extra")
+	})
+}
+
+type lintValueMarshal struct {
+	raw Retain
+
+	Name string `json:"name"`
+}
+
+func (l *lintValueMarshal) UnmarshalJSON(data []byte) error {
+	return l.raw.FromJSON(data, l)
+}
+
+func (l lintValueMarshal) MarshalJSON() ([]byte, error) {
+	return l.raw.ToJSON(l)
+}
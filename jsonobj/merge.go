@@ -0,0 +1,29 @@
+package jsonobj
+
+import "slices"
+
+// Merge merges the retained fields of other into r. Fields retained by both
+// use the value from other, and fields only retained by r are kept. New
+// fields are output after the existing fields, in the order of other.
+//
+// Merge only affects retained fields: known fields are part of the object,
+// so they're merged separately (such as using OverlayKnown).
+func (r *Retain) Merge(other *Retain) {
+	keys := other.UnknownKeys()
+	other.sortInputOrder(keys)
+
+	for _, k := range keys {
+		_, otherStripped := other.stripped[k]
+		if _, stripped := r.stripped[k]; stripped != otherStripped {
+			// The output key differs, so use the key of other.
+			r.DeleteUnknown(k)
+		}
+		if otherStripped {
+			if r.stripped == nil {
+				r.stripped = make(map[string]struct{})
+			}
+			r.stripped[k] = struct{}{}
+		}
+		r.SetUnknown(k, slices.Clone(other.raw[k]))
+	}
+}
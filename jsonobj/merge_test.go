@@ -0,0 +1,57 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetain_Merge(t *testing.T) {
+	var base, patch S
+	require.NoError(t, json.Unmarshal([]byte(`{"name": "base", "a": 1, "b": {"k": "base"}}`), &base))
+	require.NoError(t, json.Unmarshal([]byte(`{"c": 3, "name": "patch", "b": {"k": "patch"}}`), &patch))
+
+	base.raw.Merge(&patch.raw)
+
+	// Only retained fields are merged, so the known field keeps its value.
+	assert.Equal(t, "base", base.Name)
+	assert.Equal(t, `{"name":"base","a":1,"b":{"k":"patch"},"c":3}`, mustMarshal(t, &base))
+
+	b, _ := patch.raw.GetUnknown("b")
+	b[7] = 'P'
+	assert.Equal(t, `{"name":"base","a":1,"b":{"k":"patch"},"c":3}`, mustMarshal(t, &base),
+		"merged values should be copied")
+	assert.Equal(t, `{"c":3,"name":"patch","b":{"k":"Patch"}}`, mustMarshal(t, &patch),
+		"other should not be modified")
+
+	// Known fields can be merged separately.
+	require.NoError(t, OverlayKnown(&base, &patch))
+	assert.Equal(t, `{"name":"patch","a":1,"b":{"k":"patch"},"c":3}`, mustMarshal(t, &base))
+}
+
+func TestRetain_Merge_Empty(t *testing.T) {
+	var base, patch S
+	base.raw.Merge(&patch.raw)
+	assert.Equal(t, `{}`, mustMarshal(t, &base))
+
+	require.NoError(t, json.Unmarshal([]byte(`{"a": 1}`), &patch))
+	base.raw.Merge(&patch.raw)
+	assert.Equal(t, `{"a":1}`, mustMarshal(t, &base))
+}
+
+func TestRetain_Merge_StripPrefix(t *testing.T) {
+	var base, patch Retain
+	base.Configure(WithStripPrefix("x_"))
+	patch.Configure(WithStripPrefix("x_"))
+
+	var obj S
+	require.NoError(t, base.FromJSON([]byte(`{"a": 1, "x_b": 2}`), &obj))
+	require.NoError(t, patch.FromJSON([]byte(`{"x_a": 3, "x_c": 4}`), &obj))
+
+	base.Merge(&patch)
+	got, err := base.ToJSON(&obj)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"x_a": 3, "x_b": 2, "x_c": 4}`, string(got))
+}
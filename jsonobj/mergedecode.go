@@ -0,0 +1,73 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"slices"
+)
+
+// WithMergeDecode makes FromJSON merge the input into the existing object, to
+// apply partial updates (deltas) to an object:
+//   - Known fields present in the input are decoded, as usual.
+//   - Known fields absent from the input are left as-is, even if they have
+//     a default configured using WithFieldDefault.
+//   - Unknown fields in the input are retained, replacing any existing
+//     retained field with the same key (the input wins), while other
+//     existing retained fields are kept.
+//
+// Without this option, retained fields are replaced by each FromJSON.
+func WithMergeDecode() Option {
+	return func(o *options) {
+		o.mergeDecode = true
+	}
+}
+
+// retainedState is the retained fields of a Retain, and their metadata.
+type retainedState struct {
+	raw      map[string]json.RawMessage
+	stripped map[string]struct{}
+	order    []string
+}
+
+// retained returns the current retained state for mergeRetained.
+func (r *Retain) retained() retainedState {
+	if !r.opts.mergeDecode {
+		return retainedState{}
+	}
+
+	// FromJSON replaces these rather than modifying them.
+	return retainedState{
+		raw:      r.raw,
+		stripped: r.stripped,
+		order:    r.order,
+	}
+}
+
+// mergeRetained adds fields from prev that aren't retained from the input.
+func (r *Retain) mergeRetained(prev retainedState) {
+	for k, v := range prev.raw {
+		if _, ok := r.raw[k]; ok {
+			continue
+		}
+
+		if r.raw == nil {
+			r.raw = make(map[string]json.RawMessage)
+		}
+		r.raw[k] = v
+
+		if _, ok := prev.stripped[k]; ok {
+			if r.stripped == nil {
+				r.stripped = make(map[string]struct{})
+			}
+			r.stripped[k] = struct{}{}
+		}
+	}
+
+	// Existing fields keep their position, with new fields added after.
+	order := slices.Clone(prev.order)
+	for _, k := range r.order {
+		if !slices.Contains(prev.order, k) {
+			order = append(order, k)
+		}
+	}
+	r.order = order
+}
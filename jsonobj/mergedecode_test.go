@@ -0,0 +1,77 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithMergeDecode(t *testing.T) {
+	type Obj struct {
+		Name    string `json:"name"`
+		Retries int    `json:"retries"`
+		Tags    []string
+	}
+
+	var (
+		r   Retain
+		obj Obj
+	)
+	r.Configure(
+		WithMergeDecode(),
+		WithInsertionOrder(),
+		WithFieldDefault("retries", json.RawMessage(`3`)),
+	)
+
+	deltas := []struct {
+		json    string
+		want    Obj
+		wantOut string
+	}{
+		{
+			json:    `{"name": "a", "x": 1}`,
+			want:    Obj{Name: "a"},
+			wantOut: `{"name":"a","retries":0,"Tags":null,"x":1}`,
+		},
+		{
+			json:    `{"retries": 5, "y": 2, "x": 10}`,
+			want:    Obj{Name: "a", Retries: 5},
+			wantOut: `{"name":"a","retries":5,"Tags":null,"x":10,"y":2}`,
+		},
+		{
+			json:    `{"Tags": ["t"], "z": 3}`,
+			want:    Obj{Name: "a", Retries: 5, Tags: []string{"t"}},
+			wantOut: `{"name":"a","retries":5,"Tags":["t"],"x":10,"y":2,"z":3}`,
+		},
+		{
+			json:    `{}`,
+			want:    Obj{Name: "a", Retries: 5, Tags: []string{"t"}},
+			wantOut: `{"name":"a","retries":5,"Tags":["t"],"x":10,"y":2,"z":3}`,
+		},
+	}
+
+	for _, d := range deltas {
+		require.NoError(t, r.FromJSON([]byte(d.json), &obj), "decode %s", d.json)
+		assert.Equal(t, d.want, obj, "decode %s", d.json)
+
+		got, err := r.ToJSON(obj)
+		require.NoError(t, err)
+		assert.Equal(t, d.wantOut, string(got), "decode %s", d.json)
+	}
+}
+
+func TestWithMergeDecode_StripPrefix(t *testing.T) {
+	var (
+		r Retain
+		s S
+	)
+	r.Configure(WithMergeDecode(), WithStripPrefix("ext_"))
+	require.NoError(t, r.FromJSON([]byte(`{"ext_a": 1, "b": 2}`), &s))
+	require.NoError(t, r.FromJSON([]byte(`{"ext_a": 3, "ext_c": 4}`), &s))
+
+	got, err := r.ToJSON(&s)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"ext_a": 3, "b": 2, "ext_c": 4}`, string(got))
+}
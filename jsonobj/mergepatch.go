@@ -0,0 +1,280 @@
+package jsonobj
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// ApplyMergePatches applies the JSON merge patches (RFC 7386) in order to obj
+// and its retained fields. Patched known fields are decoded into obj, patched
+// unknown fields are retained, and null values in a patch delete the field,
+// zeroing known fields and removing retained fields.
+//
+// Patch keys match known fields as input keys do in FromJSON. Only the fields
+// in the patches are modified, so other known fields and retained fields are
+// unchanged, and keep their output order. New retained fields are output
+// after existing ones, in patch order. Patches are applied to copies, so if
+// any patch fails, obj and the retained fields are unchanged.
+func (r *Retain) ApplyMergePatches(obj any, patches ...[]byte) error {
+	return r.applyMergePatches("ApplyMergePatches", obj, patches)
+}
+
+// ApplyMergePatch applies a single JSON merge patch (RFC 7386) to obj and its
+// retained fields, such as for handling a PATCH request. It's equivalent to
+// ApplyMergePatches(obj, patch).
+//
+// Nested objects are merged recursively, in both known fields (whose values
+// are patched as JSON, and decoded into obj) and retained fields. Fields that
+// ToJSON doesn't output, such as noemit fields, are only modified if they're
+// in the patch, while noread fields are never modified.
+func (r *Retain) ApplyMergePatch(patch []byte, obj any) error {
+	return r.applyMergePatches("ApplyMergePatch", obj, [][]byte{patch})
+}
+
+// patchState holds the retained fields and known field values patched by
+// applyMergePatches, before they're applied to the object.
+type patchState struct {
+	raw   map[string]json.RawMessage
+	order []string
+
+	// fields holds the patched known fields in the order they were first
+	// patched, indexed by JSON name in byName.
+	fields []*patchedField
+	byName map[string]*patchedField
+}
+
+// patchedField is the new value v for the known field at path, which is the
+// field, preceded by the prefix field that contains it (if any).
+type patchedField struct {
+	path []jsonTag
+	v    reflect.Value
+}
+
+func (r *Retain) applyMergePatches(method string, obj any, patches [][]byte) error {
+	rv, ok := ensureStruct(obj, true /* requirePtr */)
+	if !ok {
+		return fmt.Errorf("%v requires a struct pointer, got %T", method, obj)
+	}
+
+	st := &patchState{
+		raw:    maps.Clone(r.raw),
+		order:  slices.Clone(r.order),
+		byName: make(map[string]*patchedField),
+	}
+	for i, patch := range patches {
+		if !isJSONObject(patch) {
+			return fmt.Errorf("merge patch %v must be a JSON object", i)
+		}
+		if err := r.patchObject(st, rv, patch); err != nil {
+			return fmt.Errorf("merge patch %v: %w", i, err)
+		}
+	}
+
+	// All patches were applied, so update obj and r.
+	for _, f := range st.fields {
+		v := rv
+		for _, t := range f.path {
+			var err error
+			if v, err = settableField(v, t); err != nil {
+				return err
+			}
+		}
+		v.Set(f.v)
+	}
+	r.raw = st.raw
+	if len(r.raw) == 0 {
+		r.raw = nil
+	}
+	r.order = st.order
+	return nil
+}
+
+// patchObject applies the merge patch to the known fields of rv and the
+// retained fields in st.
+func (r *Retain) patchObject(st *patchState, rv reflect.Value, patch []byte) error {
+	p := Retain{opts: r.opts}
+	if err := p.decodeObject(patch, nil /* codec */); err != nil {
+		return err
+	}
+
+	fields := cachedFields(rv.Type(), r.tagKey())
+	folded := foldKeys(p.raw)
+	if err := forFields(rv, fields.dominant, func(t jsonTag, v reflect.Value) error {
+		prefix, ok := fieldPrefix(t.field)
+		if !ok {
+			return r.patchField(st, &p, rv, []jsonTag{t}, fields.known, folded)
+		}
+		if v.Kind() != reflect.Struct {
+			return fmt.Errorf("field %q: prefix directive requires a struct, got %v", t.field.Name, v.Type())
+		}
+		return forJSONField(v, r.tagKey(), func(sub jsonTag, _ reflect.Value) error {
+			sub.jsonName = prefix + sub.name()
+			sub.aliases = prefixAll(prefix, sub.aliases)
+			return r.patchField(st, &p, rv, []jsonTag{t, sub}, fields.known, folded)
+		})
+	}); err != nil {
+		return err
+	}
+
+	// Keys that didn't match a known field patch the retained fields.
+	for _, k := range p.order {
+		v, ok := p.raw[k]
+		if !ok {
+			continue
+		}
+		if isNull(v) {
+			delete(st.raw, k)
+			continue
+		}
+
+		merged, err := mergePatch(st.raw[k], v)
+		if err != nil {
+			return fmt.Errorf("retained field %q: %w", k, err)
+		}
+		if st.raw == nil {
+			st.raw = make(map[string]json.RawMessage)
+		}
+		if _, ok := st.raw[k]; !ok && !slices.Contains(st.order, k) {
+			st.order = append(st.order, k)
+		}
+		st.raw[k] = merged
+	}
+	return nil
+}
+
+// patchField applies the value in the patch p (if any) for the known field at
+// path to st. Object values are merged with the field's current value, and
+// the result is decoded into a new value, as FromJSON decodes the field.
+func (r *Retain) patchField(st *patchState, p *Retain, rv reflect.Value, path []jsonTag, known map[string]struct{}, folded map[string][]string) error {
+	t := path[len(path)-1]
+	keys, rule := p.lookupField(t, known, folded)
+	if len(keys) == 0 {
+		return nil
+	}
+
+	key, patch := p.takeField(t, keys, rule, t.field.Name, nil /* trace */)
+	if t.noRead {
+		return nil
+	}
+
+	f, ok := st.byName[t.name()]
+	if !ok {
+		// Check the field can be set, without allocating any nil embedded
+		// pointers until all patches are applied.
+		v := rv
+		for _, pt := range path {
+			var err error
+			if v, err = walkField(v, pt, false /* alloc */); err != nil {
+				return err
+			}
+		}
+		f = &patchedField{path: path, v: v}
+		st.fields = append(st.fields, f)
+		st.byName[t.name()] = f
+	}
+
+	nv := reflect.New(t.field.Type).Elem()
+	if !isNull(patch) {
+		if isJSONObject(patch) {
+			cur, err := r.encodeField(t, f.v)
+			if err != nil {
+				return err
+			}
+			curJSON, err := r.marshal(cur)
+			if err != nil {
+				return err
+			}
+			if patch, err = mergePatch(curJSON, patch); err != nil {
+				return err
+			}
+		}
+		if err := r.decodeField(t, key, patch, nv); err != nil {
+			return err
+		}
+	}
+	f.v = nv
+	return nil
+}
+
+// mergePatch returns the result of applying the merge patch to target. Keys
+// of target keep their order, and keys added by the patch follow in patch
+// order. patch must be valid JSON, while target (such as a retained value set
+// using SetUnknown) is validated.
+func mergePatch(target, patch json.RawMessage) (json.RawMessage, error) {
+	if !isJSONObject(patch) {
+		return patch, nil
+	}
+
+	var (
+		keys   []string
+		listed = make(map[string]struct{})
+		values = make(map[string]json.RawMessage)
+	)
+	addKey := func(key string) {
+		if _, ok := listed[key]; !ok {
+			listed[key] = struct{}{}
+			keys = append(keys, key)
+		}
+	}
+	if isJSONObject(target) {
+		if !json.Valid(target) {
+			return nil, fmt.Errorf("invalid JSON value %q", target)
+		}
+		if err := forObjectMembers(target, func(key string, value []byte) error {
+			addKey(key)
+			values[key] = value
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := forObjectMembers(patch, func(key string, value []byte) error {
+		if isNull(value) {
+			delete(values, key)
+			return nil
+		}
+
+		merged, err := mergePatch(values[key], value)
+		if err != nil {
+			return err
+		}
+		addKey(key)
+		values[key] = merged
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for _, k := range keys {
+		v, ok := values[k]
+		if !ok {
+			continue
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		if !writeSimpleKey(&buf, k) {
+			keyJSON, err := json.Marshal(k)
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(keyJSON)
+		}
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func isJSONObject(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+	return len(trimmed) > 0 && trimmed[0] == '{'
+}
@@ -0,0 +1,346 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetain_ApplyMergePatches(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		patches  []string
+		wantName string
+		want     string
+	}{
+		{
+			name:     "no patches",
+			json:     `{"name": "foo", "num": 1}`,
+			wantName: "foo",
+			want:     `{"name": "foo", "num": 1}`,
+		},
+		{
+			name:     "update known and unknown",
+			json:     `{"name": "foo", "num": 1}`,
+			patches:  []string{`{"name": "bar", "num": 2, "new": true}`},
+			wantName: "bar",
+			want:     `{"name": "bar", "num": 2, "new": true}`,
+		},
+		{
+			name:     "delete known and unknown",
+			json:     `{"name": "foo", "num": 1, "keep": 1}`,
+			patches:  []string{`{"name": null, "num": null, "missing": null}`},
+			wantName: "",
+			want:     `{"keep": 1}`,
+		},
+		{
+			name: "nested unknown",
+			json: `{"meta": {"a": 1, "b": {"c": 2, "d": 3}}}`,
+			patches: []string{
+				`{"meta": {"a": null, "b": {"c": 4}, "e": [1]}}`,
+			},
+			want: `{"meta": {"b": {"c": 4, "d": 3}, "e": [1]}}`,
+		},
+		{
+			name: "later patch re-adds deleted key",
+			json: `{"name": "foo", "num": 1}`,
+			patches: []string{
+				`{"num": null, "name": null}`,
+				`{"num": {"v": 2}}`,
+				`{"num": {"w": 3}, "name": "bar"}`,
+			},
+			wantName: "bar",
+			want:     `{"name": "bar", "num": {"v": 2, "w": 3}}`,
+		},
+		{
+			name: "later patch deletes added key",
+			json: `{}`,
+			patches: []string{
+				`{"num": 1}`,
+				`{"num": null}`,
+			},
+			want: `{}`,
+		},
+		{
+			name:    "replace object with scalar",
+			json:    `{"meta": {"a": 1}}`,
+			patches: []string{`{"meta": "scalar"}`},
+			want:    `{"meta": "scalar"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s S
+			require.NoError(t, json.Unmarshal([]byte(tt.json), &s))
+
+			patches := make([][]byte, len(tt.patches))
+			for i, p := range tt.patches {
+				patches[i] = []byte(p)
+			}
+
+			require.NoError(t, s.raw.ApplyMergePatches(&s, patches...))
+			assert.Equal(t, tt.wantName, s.Name)
+			assert.JSONEq(t, tt.want, mustMarshal(t, &s))
+		})
+	}
+}
+
+func TestRetain_ApplyMergePatches_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		obj     any
+		patches []string
+		wantErr string
+	}{
+		{
+			name:    "not struct pointer",
+			obj:     S{},
+			wantErr: "ApplyMergePatches requires a struct pointer, got jsonobj.S",
+		},
+		{
+			name:    "patch not object",
+			obj:     &S{},
+			patches: []string{`{}`, `[1]`},
+			wantErr: "merge patch 1 must be a JSON object",
+		},
+		{
+			name:    "invalid patch",
+			obj:     &S{},
+			patches: []string{`{"a": }`},
+			wantErr: "merge patch 0: invalid character",
+		},
+		{
+			name:    "invalid known field",
+			obj:     &S{},
+			patches: []string{`{"name": 1}`},
+			wantErr: "cannot unmarshal number",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patches := make([][]byte, len(tt.patches))
+			for i, p := range tt.patches {
+				patches[i] = []byte(p)
+			}
+
+			var r Retain
+			err := r.ApplyMergePatches(tt.obj, patches...)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestRetain_ApplyMergePatch(t *testing.T) {
+	type Inner struct {
+		A int `json:"a,omitempty"`
+		B int `json:"b,omitempty"`
+	}
+	type patchS struct {
+		raw Retain
+
+		Name  string `json:"name,omitempty"`
+		Inner Inner  `json:"inner"`
+		Tags  []string
+	}
+
+	var s patchS
+	require.NoError(t, s.raw.FromJSON([]byte(`{"name": "foo", "inner": {"a": 1, "b": 2}, "Tags": ["x"], "meta": {"c": 3, "d": 4}, "keep": 1}`), &s))
+
+	patch := `{"inner": {"a": null, "b": 5}, "Tags": null, "meta": {"c": null, "e": [1]}, "keep": null, "new": "v"}`
+	require.NoError(t, s.raw.ApplyMergePatch([]byte(patch), &s))
+
+	assert.Equal(t, "foo", s.Name)
+	assert.Equal(t, Inner{B: 5}, s.Inner)
+	assert.Nil(t, s.Tags)
+
+	_, ok := s.raw.GetUnknown("keep")
+	assert.False(t, ok, "deleted unknown field should be removed")
+
+	got, err := s.raw.ToJSON(&s)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name": "foo", "inner": {"b": 5}, "Tags": null, "meta": {"d": 4, "e": [1]}, "new": "v"}`, string(got))
+
+	assert.EqualError(t, s.raw.ApplyMergePatch([]byte(`{}`), s), "ApplyMergePatch requires a struct pointer, got jsonobj.patchS")
+	assert.EqualError(t, s.raw.ApplyMergePatch([]byte(`"scalar"`), &s), "merge patch 0 must be a JSON object")
+}
+
+func TestRetain_ApplyMergePatches_Order(t *testing.T) {
+	var s S
+	require.NoError(t, s.raw.FromJSON([]byte(`{"b": 1, "name": "foo", "meta": {"z": 1, "y": 2}, "a": 2}`), &s))
+
+	patches := [][]byte{
+		[]byte(`{"c": 3, "meta": {"x": 3, "z": 4}, "name": "bar"}`),
+		[]byte(`{"d": 4, "a": {"v": 1}}`),
+	}
+	require.NoError(t, s.raw.ApplyMergePatches(&s, patches...))
+
+	got, err := s.raw.ToJSON(&s)
+	require.NoError(t, err)
+	assert.Equal(t, `{"b":1,"name":"bar","meta":{"z":4,"y":2,"x":3},"a":{"v":1},"c":3,"d":4}`, string(got))
+}
+
+func TestRetain_ApplyMergePatches_Options(t *testing.T) {
+	t.Run("checksum field", func(t *testing.T) {
+		var s S
+		s.raw.Configure(WithChecksumField("_checksum"))
+		s.Name = "foo"
+		signed := mustMarshal(t, &s)
+
+		s = S{}
+		s.raw.Configure(WithChecksumField("_checksum"))
+		require.NoError(t, json.Unmarshal([]byte(signed), &s))
+		require.NoError(t, s.raw.ApplyMergePatch([]byte(`{"name": "bar", "new": 1}`), &s))
+		assert.Equal(t, "bar", s.Name)
+
+		// The patched output has a new checksum, which FromJSON verifies.
+		var s2 S
+		s2.raw.Configure(WithChecksumField("_checksum"))
+		require.NoError(t, json.Unmarshal([]byte(mustMarshal(t, &s)), &s2))
+		assert.Equal(t, "bar", s2.Name)
+	})
+
+	t.Run("output key case", func(t *testing.T) {
+		type Obj struct {
+			UserID int    `json:"userId"`
+			Name   string `json:"name"`
+		}
+
+		var (
+			obj Obj
+			r   Retain
+		)
+		r.Configure(WithOutputKeyCase(SnakeCase))
+		require.NoError(t, r.FromJSON([]byte(`{"userId": 1, "name": "foo"}`), &obj))
+		require.NoError(t, r.ApplyMergePatch([]byte(`{"name": "bar"}`), &obj))
+		assert.Equal(t, Obj{UserID: 1, Name: "bar"}, obj)
+		assert.Empty(t, r.UnknownKeys(), "user_id should stay known")
+
+		got, err := r.ToJSON(obj)
+		require.NoError(t, err)
+		assert.Equal(t, `{"user_id":1,"name":"bar"}`, string(got))
+	})
+}
+
+func TestRetain_ApplyMergePatches_Atomic(t *testing.T) {
+	type Inner struct {
+		A int `json:"a"`
+	}
+	type Obj struct {
+		raw Retain
+
+		Name  string   `json:"name"`
+		Num   int      `json:"num"`
+		Inner *Inner   `json:"inner"`
+		Tags  []string `json:"tags"`
+	}
+
+	inner := &Inner{A: 1}
+	obj := Obj{Name: "foo", Num: 1, Inner: inner, Tags: []string{"x"}}
+	require.NoError(t, obj.raw.FromJSON([]byte(`{"name": "foo", "num": 1, "inner": {"a": 1}, "tags": ["x"], "keep": 1}`), &obj))
+	obj.Inner = inner
+	want, err := obj.raw.ToJSON(&obj)
+	require.NoError(t, err)
+
+	patches := [][]byte{
+		[]byte(`{"num": 2, "inner": {"a": 2}, "tags": null, "keep": null, "new": 1}`),
+		[]byte(`{"name": 1}`),
+	}
+	err = obj.raw.ApplyMergePatches(&obj, patches...)
+	assert.ErrorContains(t, err, "merge patch 1:")
+	assert.ErrorContains(t, err, "cannot unmarshal number")
+
+	assert.Equal(t, "foo", obj.Name)
+	assert.Equal(t, 1, obj.Num)
+	assert.Same(t, inner, obj.Inner)
+	assert.Equal(t, Inner{A: 1}, *inner)
+	assert.Equal(t, []string{"x"}, obj.Tags)
+	assert.Equal(t, []string{"keep"}, obj.raw.UnknownKeys())
+
+	got, err := obj.raw.ToJSON(&obj)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+}
+
+func TestRetain_ApplyMergePatch_Directions(t *testing.T) {
+	type Obj struct {
+		raw Retain
+
+		Name     string `json:"name"`
+		Password string `json:"password" jsonobj:"noemit"`
+		Total    int    `json:"total" jsonobj:"noread"`
+	}
+
+	obj := Obj{Total: 3}
+	require.NoError(t, obj.raw.FromJSON([]byte(`{"name": "foo", "password": "secret"}`), &obj))
+
+	require.NoError(t, obj.raw.ApplyMergePatch([]byte(`{"name": "bar", "total": 5}`), &obj))
+	assert.Equal(t, Obj{raw: obj.raw, Name: "bar", Password: "secret", Total: 3}, obj, "noemit field should be preserved")
+	assert.Empty(t, obj.raw.UnknownKeys(), "noread key should not be retained")
+
+	require.NoError(t, obj.raw.ApplyMergePatch([]byte(`{"password": "new"}`), &obj))
+	assert.Equal(t, "new", obj.Password)
+
+	require.NoError(t, obj.raw.ApplyMergePatch([]byte(`{"password": null}`), &obj))
+	assert.Empty(t, obj.Password)
+}
+
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  string
+		patch   string
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "keeps target order",
+			target: `{"b": 1, "a": 2}`,
+			patch:  `{"c": 3, "b": 4}`,
+			want:   `{"b":4,"a":2,"c":3}`,
+		},
+		{
+			name:   "delete and re-add key",
+			target: `{"a": 1, "b": 2}`,
+			patch:  `{"a": null, "a": 3}`,
+			want:   `{"a":3,"b":2}`,
+		},
+		{
+			name:   "repeated new key",
+			target: `{}`,
+			patch:  `{"a": 1, "a": null, "a": 2}`,
+			want:   `{"a":2}`,
+		},
+		{
+			name:    "invalid target",
+			target:  `{"x"`,
+			patch:   `{"y": 1}`,
+			wantErr: `invalid JSON value "{\"x\""`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mergePatch(json.RawMessage(tt.target), json.RawMessage(tt.patch))
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestRetain_ApplyMergePatch_InvalidRetained(t *testing.T) {
+	var s S
+	s.raw.SetUnknown("a", json.RawMessage(`{"x"`))
+
+	err := s.raw.ApplyMergePatch([]byte(`{"a": {"y": 1}}`), &s)
+	assert.EqualError(t, err, `merge patch 0: retained field "a": invalid JSON value "{\"x\""`)
+}
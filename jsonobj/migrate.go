@@ -0,0 +1,72 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+type migration struct {
+	to int
+	fn func(r *Retain) error
+}
+
+// WithVersionField sets the JSON field that holds the schema version of
+// an object, used to run migrations registered with RegisterMigration.
+// Objects without the field are treated as version 0.
+func WithVersionField(name string) Option {
+	return func(o *options) {
+		o.versionField = name
+	}
+}
+
+// RegisterMigration registers fn to upgrade objects with schema version from
+// to version to, which must be greater than from. Registering a migration
+// replaces any existing migration for the same from version.
+//
+// Migrations run in FromJSON before known fields are decoded, so the
+// GetUnknown, SetUnknown and DeleteUnknown methods operate on all fields of
+// the input. FromJSON runs the chain of migrations starting at the input's
+// version, and then updates the version field to the final version.
+func (r *Retain) RegisterMigration(from, to int, fn func(r *Retain) error) {
+	if to <= from {
+		panic(fmt.Sprintf("jsonobj: migration from %v must be to a later version, got %v", from, to))
+	}
+
+	if r.opts.migrations == nil {
+		r.opts.migrations = make(map[int]migration)
+	}
+	r.opts.migrations[from] = migration{to: to, fn: fn}
+}
+
+// migrate runs migrations against r.raw, which contains all input fields.
+func (r *Retain) migrate() error {
+	if r.opts.versionField == "" || len(r.opts.migrations) == 0 {
+		return nil
+	}
+
+	var version int
+	if versionJSON, ok := r.raw[r.opts.versionField]; ok {
+		if err := json.Unmarshal(versionJSON, &version); err != nil {
+			return fmt.Errorf("version field %q: %w", r.opts.versionField, err)
+		}
+	}
+
+	from := version
+	for {
+		m, ok := r.opts.migrations[version]
+		if !ok {
+			break
+		}
+
+		if err := m.fn(r); err != nil {
+			return fmt.Errorf("migrate from version %v to %v: %w", version, m.to, err)
+		}
+		version = m.to
+	}
+
+	if version != from {
+		r.SetUnknown(r.opts.versionField, json.RawMessage(strconv.Itoa(version)))
+	}
+	return nil
+}
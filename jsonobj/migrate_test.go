@@ -0,0 +1,110 @@
+package jsonobj
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type versioned struct {
+	raw Retain
+
+	Version int    `json:"schemaVersion"`
+	Name    string `json:"name"`
+}
+
+func newVersioned() *versioned {
+	var v versioned
+	v.raw.Configure(WithVersionField("schemaVersion"))
+
+	// v0 -> v1: rename "title" to "name".
+	v.raw.RegisterMigration(0, 1, func(r *Retain) error {
+		if title, ok := r.GetUnknown("title"); ok {
+			r.DeleteUnknown("title")
+			r.SetUnknown("name", title)
+		}
+		return nil
+	})
+
+	// v1 -> v3: drop "legacy".
+	v.raw.RegisterMigration(1, 3, func(r *Retain) error {
+		r.DeleteUnknown("legacy")
+		return nil
+	})
+	return &v
+}
+
+func TestRegisterMigration(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		want     string
+		wantName string
+	}{
+		{
+			name:     "no version",
+			json:     `{"title": "foo", "legacy": true, "other": 1}`,
+			want:     `{"schemaVersion": 3, "name": "foo", "other": 1}`,
+			wantName: "foo",
+		},
+		{
+			name:     "partially migrated",
+			json:     `{"schemaVersion": 1, "title": "foo", "legacy": true}`,
+			want:     `{"schemaVersion": 3, "title": "foo", "name": ""}`,
+			wantName: "",
+		},
+		{
+			name:     "current version",
+			json:     `{"schemaVersion": 3, "name": "foo", "legacy": true}`,
+			want:     `{"schemaVersion": 3, "name": "foo", "legacy": true}`,
+			wantName: "foo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newVersioned()
+			require.NoError(t, v.raw.FromJSON([]byte(tt.json), v))
+			assert.Equal(t, 3, v.Version)
+			assert.Equal(t, tt.wantName, v.Name)
+
+			got, err := v.raw.ToJSON(v)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestRegisterMigration_Errors(t *testing.T) {
+	t.Run("invalid version", func(t *testing.T) {
+		v := newVersioned()
+		err := v.raw.FromJSON([]byte(`{"schemaVersion": "1"}`), v)
+		assert.ErrorContains(t, err, `version field "schemaVersion": json: cannot unmarshal string`)
+	})
+
+	t.Run("migration fails", func(t *testing.T) {
+		v := newVersioned()
+		v.raw.RegisterMigration(1, 2, func(r *Retain) error {
+			return errors.New("bad data")
+		})
+		err := v.raw.FromJSON([]byte(`{}`), v)
+		assert.EqualError(t, err, "migrate from version 1 to 2: bad data")
+	})
+
+	t.Run("migration to earlier version", func(t *testing.T) {
+		var r Retain
+		assert.PanicsWithValue(t, "jsonobj: migration from 2 must be to a later version, got 2", func() {
+			r.RegisterMigration(2, 2, func(r *Retain) error { return nil })
+		})
+	})
+
+	t.Run("no version field", func(t *testing.T) {
+		var v versioned
+		v.raw.RegisterMigration(0, 1, func(r *Retain) error {
+			return errors.New("should not run")
+		})
+		require.NoError(t, v.raw.FromJSON([]byte(`{}`), &v))
+	})
+}
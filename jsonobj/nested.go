@@ -0,0 +1,166 @@
+package jsonobj
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// isNestedRetain returns whether known fields of the type rt (or the struct
+// that rt points to) retain their own unknown fields. These are struct types
+// with an accessible Retain field (see retainField) that don't have their own
+// MarshalJSON or UnmarshalJSON methods.
+//
+// Slices and arrays of these types are handled element by element, see
+// isNestedRetainElems, while maps are decoded using encoding/json, and don't
+// retain unknown fields.
+func isNestedRetain(rt reflect.Type) bool {
+	if rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+	}
+	if rt.Kind() != reflect.Struct || isRetainType(rt) {
+		return false
+	}
+
+	pt := reflect.PointerTo(rt)
+	if pt.Implements(marshalerType) || pt.Implements(unmarshalerType) {
+		return false
+	}
+	return hasAccessibleRetain(rt)
+}
+
+// isNestedRetainElems returns whether rt is a slice or array type whose
+// elements are nested retain types, see isNestedRetain.
+func isNestedRetainElems(rt reflect.Type) bool {
+	switch rt.Kind() {
+	case reflect.Slice, reflect.Array:
+		return isNestedRetain(rt.Elem())
+	default:
+		return false
+	}
+}
+
+// decodeNested decodes fieldJSON into v using the Retain field of v,
+// if v's type is a nested retain type, see isNestedRetain. Slices and arrays
+// of nested retain types are decoded element by element.
+func decodeNested(fieldJSON json.RawMessage, v reflect.Value) (bool, error) {
+	if isNestedRetainElems(v.Type()) {
+		return true, decodeNestedElems(fieldJSON, v)
+	}
+	if !isNestedRetain(v.Type()) {
+		return false, nil
+	}
+
+	if v.Kind() == reflect.Pointer {
+		if isNull(fieldJSON) {
+			v.SetZero()
+			return true, nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	r, _ := retainField(v)
+	return true, r.FromJSON(fieldJSON, v.Addr().Interface())
+}
+
+// decodeNestedElems decodes the JSON array fieldJSON into the slice or array
+// v, decoding each element using decodeNested. As with encoding/json, null
+// sets a slice to nil (and leaves an array unmodified), and extra elements
+// are ignored for arrays, while missing elements are zeroed.
+func decodeNestedElems(fieldJSON json.RawMessage, v reflect.Value) error {
+	if isNull(fieldJSON) {
+		if v.Kind() == reflect.Slice {
+			v.SetZero()
+		}
+		return nil
+	}
+
+	var elems []json.RawMessage
+	if err := json.Unmarshal(fieldJSON, &elems); err != nil {
+		return err
+	}
+
+	if v.Kind() == reflect.Slice {
+		v.Set(reflect.MakeSlice(v.Type(), len(elems), len(elems)))
+	}
+	for i := 0; i < v.Len(); i++ {
+		ev := v.Index(i)
+		if i >= len(elems) {
+			ev.SetZero()
+			continue
+		}
+
+		// Elements are decoded into new values, so they don't share
+		// retained fields with previous elements.
+		ev.SetZero()
+		if _, err := decodeNested(elems[i], ev); err != nil {
+			return fmt.Errorf("element %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// encodeNested marshals v using the Retain field of v, if v's type is
+// a nested retain type, see isNestedRetain. Slices and arrays of nested
+// retain types are encoded element by element.
+func encodeNested(v reflect.Value) (any, bool, error) {
+	if isNestedRetainElems(v.Type()) {
+		return encodeNestedElems(v)
+	}
+	if !isNestedRetain(v.Type()) {
+		return nil, false, nil
+	}
+
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, false, nil
+		}
+		v = v.Elem()
+	}
+	if !v.CanAddr() {
+		addressable := reflect.New(v.Type()).Elem()
+		addressable.Set(v)
+		v = addressable
+	}
+
+	r, _ := retainField(v)
+	data, err := r.ToJSON(v.Addr().Interface())
+	if err != nil {
+		return nil, true, err
+	}
+	return json.RawMessage(data), true, nil
+}
+
+// encodeNestedElems encodes the slice or array v as a JSON array, encoding
+// each element using encodeNested.
+func encodeNestedElems(v reflect.Value) (any, bool, error) {
+	if v.Kind() == reflect.Slice && v.IsNil() {
+		// Use encoding/json, which encodes nil slices as null.
+		return nil, false, nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i := 0; i < v.Len(); i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		ev, ok, err := encodeNested(v.Index(i))
+		if err != nil {
+			return nil, true, fmt.Errorf("element %d: %w", i, err)
+		}
+		if !ok {
+			// Only nil pointers aren't encoded by encodeNested.
+			buf.WriteString("null")
+			continue
+		}
+		buf.Write(ev.(json.RawMessage))
+	}
+	buf.WriteByte(']')
+	return json.RawMessage(buf.Bytes()), true, nil
+}
@@ -0,0 +1,244 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type nestedMeta struct {
+	raw Retain
+
+	A int `json:"a"`
+}
+
+func (m *nestedMeta) JSONRetain() *Retain {
+	return &m.raw
+}
+
+type exportedMeta struct {
+	Raw Retain `json:"-"`
+
+	A int `json:"a"`
+}
+
+type nestedS struct {
+	raw Retain
+
+	Title   string      `json:"title"`
+	Meta    nestedMeta  `json:"meta"`
+	MetaPtr *nestedMeta `json:"metaPtr,omitempty"`
+}
+
+func (s *nestedS) UnmarshalJSON(data []byte) error {
+	return s.raw.FromJSON(data, s)
+}
+
+func (s nestedS) MarshalJSON() ([]byte, error) {
+	return s.raw.ToJSON(s)
+}
+
+func TestRetain_NestedFields(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "nested value",
+			input: `{"title":"x","meta":{"a":1,"future":2}}`,
+		},
+		{
+			name:  "nested pointer",
+			input: `{"title":"x","meta":{"a":1},"metaPtr":{"b":[1],"a":2},"other":true}`,
+		},
+		{
+			name:  "null pointer",
+			input: `{"title":"x","meta":{"a":1},"metaPtr":null}`,
+			want:  `{"title":"x","meta":{"a":1}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s nestedS
+			require.NoError(t, json.Unmarshal([]byte(tt.input), &s))
+
+			want := tt.want
+			if want == "" {
+				want = tt.input
+			}
+			assert.Equal(t, want, mustMarshal(t, s))
+		})
+	}
+}
+
+func TestRetain_NestedFieldsModified(t *testing.T) {
+	var s nestedS
+	require.NoError(t, json.Unmarshal([]byte(`{"title":"x","meta":{"future":2,"a":1}}`), &s))
+	future, ok := s.Meta.raw.GetUnknown("future")
+	require.True(t, ok, "nested unknown field should be retained")
+	assert.Equal(t, json.RawMessage("2"), future)
+
+	s.Meta.A = 5
+	s.MetaPtr = &nestedMeta{A: 3}
+	assert.Equal(t, `{"title":"x","meta":{"future":2,"a":5},"metaPtr":{"a":3}}`, mustMarshal(t, s))
+}
+
+func TestRetain_NestedFieldError(t *testing.T) {
+	var s nestedS
+	err := json.Unmarshal([]byte(`{"title":"x","metaPtr":{"a":"str"}}`), &s)
+	assert.ErrorContains(t, err, `field "metaPtr" (MetaPtr): field "a" (A): json: cannot unmarshal string into`)
+}
+
+func TestIsNestedRetain(t *testing.T) {
+	type noRetain struct {
+		A int
+	}
+	type unexportedRetain struct {
+		raw Retain
+		A   int
+	}
+
+	tests := []struct {
+		name string
+		v    any
+		want bool
+	}{
+		{name: "struct with RetainAccessor", v: nestedMeta{}, want: true},
+		{name: "pointer to struct with RetainAccessor", v: &nestedMeta{}, want: true},
+		{name: "struct with exported Retain", v: exportedMeta{}, want: true},
+		{name: "struct with unexported Retain", v: unexportedRetain{}, want: false},
+		{name: "struct with methods", v: S{}, want: false},
+		{name: "struct without Retain", v: noRetain{}, want: false},
+		{name: "slice", v: []nestedMeta{}, want: false},
+		{name: "Retain", v: Retain{}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isNestedRetain(reflect.TypeOf(tt.v)))
+		})
+	}
+}
+
+type nestedSlicesS struct {
+	raw Retain
+
+	Items []nestedMeta          `json:"items"`
+	Ptrs  []*nestedMeta         `json:"ptrs,omitempty"`
+	Arr   [2]nestedMeta         `json:"arr"`
+	Objs  []S                   `json:"objs,omitempty"`
+	Map   map[string]nestedMeta `json:"map,omitempty"`
+}
+
+func TestRetain_NestedSlices(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "elements with and without unknowns",
+			input: `{"items":[{"a":1,"x":true},{"a":2}],"arr":[{"a":3},{"y":[1],"a":4}]}`,
+		},
+		{
+			name:  "nil and empty",
+			input: `{"items":null,"ptrs":[],"arr":[{"a":0},{"a":0}]}`,
+			want:  `{"items":null,"arr":[{"a":0},{"a":0}]}`,
+		},
+		{
+			name:  "pointer elements",
+			input: `{"items":[],"ptrs":[{"a":1,"x":2},null],"arr":[{"a":0},{"a":0}]}`,
+		},
+		{
+			name:  "short array",
+			input: `{"items":[],"arr":[{"a":1,"x":2}]}`,
+			want:  `{"items":[],"arr":[{"a":1,"x":2},{"a":0}]}`,
+		},
+		{
+			name:  "long array",
+			input: `{"items":[],"arr":[{"a":1},{"a":2},{"a":3}]}`,
+			want:  `{"items":[],"arr":[{"a":1},{"a":2}]}`,
+		},
+		{
+			name:  "Retainable elements",
+			input: `{"items":[],"arr":[{"a":0},{"a":0}],"objs":[{"name":"foo","x":1}]}`,
+		},
+		{
+			name:  "maps are not retained",
+			input: `{"items":[],"arr":[{"a":0},{"a":0}],"map":{"k":{"a":1,"x":2}}}`,
+			want:  `{"items":[],"arr":[{"a":0},{"a":0}],"map":{"k":{"a":1}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s nestedSlicesS
+			require.NoError(t, s.raw.FromJSON([]byte(tt.input), &s))
+
+			want := tt.want
+			if want == "" {
+				want = tt.input
+			}
+			got, err := s.raw.ToJSON(&s)
+			require.NoError(t, err)
+			assert.Equal(t, want, string(got))
+		})
+	}
+}
+
+func TestRetain_NestedSlicesFresh(t *testing.T) {
+	s := nestedSlicesS{Items: make([]nestedMeta, 1, 2)}
+	s.Items[0].raw.SetUnknown("stale", json.RawMessage(`1`))
+	require.NoError(t, s.raw.FromJSON([]byte(`{"items":[{"a":1}]}`), &s))
+	assert.False(t, s.Items[0].raw.HasUnknown(), "elements should be decoded into new values")
+
+	err := s.raw.FromJSON([]byte(`{"items":[{"a":1},{"a":"str"}]}`), &s)
+	assert.ErrorContains(t, err, `field "items" (Items): element 1: field "a" (A): json: cannot unmarshal string`)
+
+	err = s.raw.FromJSON([]byte(`{"items":{}}`), &s)
+	assert.ErrorContains(t, err, `field "items" (Items): json: cannot unmarshal object`)
+}
+
+func TestIsNestedRetainElems(t *testing.T) {
+	tests := []struct {
+		name string
+		v    any
+		want bool
+	}{
+		{name: "slice", v: []nestedMeta{}, want: true},
+		{name: "pointer slice", v: []*nestedMeta{}, want: true},
+		{name: "array", v: [1]nestedMeta{}, want: true},
+		{name: "slice with methods", v: []S{}, want: false},
+		{name: "map", v: map[string]nestedMeta{}, want: false},
+		{name: "struct", v: nestedMeta{}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isNestedRetainElems(reflect.TypeOf(tt.v)))
+		})
+	}
+}
+
+func TestRetain_NestedExportedRetain(t *testing.T) {
+	type obj struct {
+		Meta exportedMeta `json:"meta"`
+	}
+
+	var (
+		o obj
+		r Retain
+	)
+	input := `{"meta":{"a":1,"future":2}}`
+	require.NoError(t, r.FromJSON([]byte(input), &o))
+	assert.Equal(t, []string{"future"}, o.Meta.Raw.UnknownKeys())
+
+	got, err := r.ToJSON(o)
+	require.NoError(t, err)
+	assert.Equal(t, input, string(got))
+}
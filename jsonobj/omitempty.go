@@ -0,0 +1,116 @@
+package jsonobj
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// WithRecursiveOmitEmpty makes ToJSON omit fields, both known and retained,
+// whose value is null, or an empty object or array after recursively omitting
+// empty fields from nested objects. For example,
+// {"a": {"b": {}, "c": [], "d": null}, "e": 1} is emitted as {"e": 1}.
+//
+// It applies to all fields, regardless of omitempty tags. Other scalars
+// (including zero values such as 0, false and "") are never omitted, and array
+// elements are kept so indexes are unchanged. Objects and arrays with omitted
+// fields are written without whitespace, keeping the order and bytes of their
+// remaining keys and values, and other values (including retained values) are
+// written as-is.
+func WithRecursiveOmitEmpty() Option {
+	return func(o *options) {
+		o.recursiveOmitEmpty = true
+	}
+}
+
+// omitEmptyFields removes fields from all that are empty after recursively
+// omitting empty fields.
+func (r *Retain) omitEmptyFields(all map[string]any) error {
+	if !r.opts.recursiveOmitEmpty {
+		return nil
+	}
+
+	for k, v := range all {
+		data, ok := v.(json.RawMessage)
+		if ok {
+			if !json.Valid(data) {
+				return fmt.Errorf("field %q: invalid JSON value %q", k, data)
+			}
+		} else {
+			var err error
+			if data, err = r.marshal(v); err != nil {
+				return fmt.Errorf("field %q: %w", k, err)
+			}
+		}
+
+		stripped, empty, _ := omitEmptyJSON(data)
+		if empty {
+			delete(all, k)
+			continue
+		}
+		// The value is marshalled, so it's written as-is.
+		all[k] = json.RawMessage(stripped)
+	}
+	return nil
+}
+
+// omitEmptyJSON recursively omits empty fields from objects in the valid JSON
+// value data, and returns the result, whether it's null or an empty object or
+// array, and whether it was modified. Unmodified values are returned as-is,
+// while modified objects and arrays are written without whitespace, keeping
+// the order and bytes of their remaining keys and values.
+func omitEmptyJSON(data []byte) (_ []byte, empty, changed bool) {
+	i := skipSpace(data, 0)
+	open := data[i]
+	if open != '{' && open != '[' {
+		return data, isNull(data), false
+	}
+
+	type member struct {
+		key, value []byte
+	}
+	var members []member
+	for i = skipSpace(data, i+1); data[i] != '}' && data[i] != ']'; {
+		var m member
+		if open == '{' {
+			keyEnd := stringEnd(data, i)
+			m.key = data[i:keyEnd]
+			i = skipSpace(data, skipSpace(data, keyEnd)+1) // skip ':'
+		}
+
+		end := valueEnd(data, i)
+		value, valueEmpty, valueChanged := omitEmptyJSON(data[i:end])
+		if open == '{' && valueEmpty {
+			// Empty fields are omitted, while array elements are kept.
+			changed = true
+		} else {
+			m.value = value
+			members = append(members, m)
+			changed = changed || valueChanged
+		}
+
+		if i = skipSpace(data, end); data[i] == ',' {
+			i = skipSpace(data, i+1)
+		}
+	}
+
+	empty = len(members) == 0
+	if !changed {
+		return data, empty, false
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte(open)
+	for j, m := range members {
+		if j > 0 {
+			buf.WriteByte(',')
+		}
+		if m.key != nil {
+			buf.Write(m.key)
+			buf.WriteByte(':')
+		}
+		buf.Write(m.value)
+	}
+	buf.WriteByte(data[i])
+	return buf.Bytes(), empty, true
+}
@@ -0,0 +1,121 @@
+package jsonobj
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRecursiveOmitEmpty(t *testing.T) {
+	type Leaf struct {
+		Name string   `json:"name,omitempty"`
+		Tags []string `json:"tags,omitempty"`
+	}
+	type Branch struct {
+		Leaf   Leaf           `json:"leaf"`
+		Leaves []Leaf         `json:"leaves"`
+		Attrs  map[string]any `json:"attrs"`
+	}
+	type Tree struct {
+		ID     int    `json:"id"`
+		Branch Branch `json:"branch"`
+	}
+
+	tests := []struct {
+		name    string
+		json    string
+		tree    func(*Tree)
+		wantOut string
+	}{
+		{
+			name:    "deeply empty collapses",
+			json:    `{}`,
+			wantOut: `{"id": 0}`,
+		},
+		{
+			name: "non-empty leaf kept",
+			json: `{}`,
+			tree: func(tr *Tree) {
+				tr.Branch.Leaf.Tags = []string{"a"}
+			},
+			wantOut: `{"id": 0, "branch": {"leaf": {"tags": ["a"]}}}`,
+		},
+		{
+			name: "array elements kept",
+			json: `{}`,
+			tree: func(tr *Tree) {
+				tr.Branch.Leaves = []Leaf{{}, {Name: "n"}}
+			},
+			wantOut: `{"id": 0, "branch": {"leaves": [{}, {"name": "n"}]}}`,
+		},
+		{
+			name:    "null array elements kept",
+			json:    `{"list": [null, {"a": null}]}`,
+			wantOut: `{"id": 0, "list": [null, {}]}`,
+		},
+		{
+			name: "zero scalars kept, nulls omitted",
+			json: `{}`,
+			tree: func(tr *Tree) {
+				tr.Branch.Attrs = map[string]any{"a": map[string]any{}, "b": nil, "c": ""}
+			},
+			wantOut: `{"id": 0, "branch": {"attrs": {"c": ""}}}`,
+		},
+		{
+			name:    "retained objects",
+			json:    `{"meta": {"a": {}, "b": [], "c": {"d": null}}, "list": [], "keep": {"x": {"y": 1, "z": {}}}}`,
+			wantOut: `{"id": 0, "keep": {"x": {"y": 1}}}`,
+		},
+		{
+			name:    "unchanged retained values",
+			json:    `{"keep": {"b":1, "a":[ {} ]}}`,
+			wantOut: `{"id": 0, "keep": {"b":1, "a":[ {} ]}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				r    Retain
+				tree Tree
+			)
+			r.Configure(WithRecursiveOmitEmpty())
+			require.NoError(t, r.FromJSON([]byte(tt.json), &tree))
+			if tt.tree != nil {
+				tt.tree(&tree)
+			}
+
+			out, err := r.ToJSON(tree)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.wantOut, string(out))
+		})
+	}
+}
+
+func TestWithRecursiveOmitEmpty_Exact(t *testing.T) {
+	var (
+		r Retain
+		s S
+	)
+	r.Configure(WithRecursiveOmitEmpty())
+	input := `{"z": {"b": "\u003c", "a": {}}, "name": "n", "keep": {"b":1,  "a":[ {} ]}, "list": [ {"x": null}, {"y": [ ]}, 2 ]}`
+	require.NoError(t, r.FromJSON([]byte(input), &s))
+
+	out, err := r.ToJSON(&s)
+	require.NoError(t, err)
+	assert.Equal(t, `{"z":{"b":"\u003c"},"name":"n","keep":{"b":1,  "a":[ {} ]},"list":[{},{},2]}`, string(out),
+		"unmodified values should be byte-exact, and modified objects keep their key order")
+}
+
+func TestWithRecursiveOmitEmpty_Disabled(t *testing.T) {
+	var (
+		r Retain
+		s S
+	)
+	require.NoError(t, r.FromJSON([]byte(`{"meta": {"a": {}}}`), &s))
+
+	out, err := r.ToJSON(&s)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"meta": {"a": {}}}`, string(out))
+}
@@ -0,0 +1,38 @@
+package jsonobj
+
+import "reflect"
+
+// omitZeroTagOption is the `json:",omitzero"` tag option added in Go 1.24,
+// which omits a field if it's the zero value, or if its IsZero method
+// returns true.
+const omitZeroTagOption = "omitzero"
+
+var isZeroerType = reflect.TypeOf((*isZeroer)(nil)).Elem()
+
+type isZeroer interface {
+	IsZero() bool
+}
+
+// omitted returns whether the field v is omitted in the output because of
+// the omitempty or omitzero tag options.
+func (t jsonTag) omitted(v reflect.Value) bool {
+	if t.omitEmpty() && isZero(v) {
+		return true
+	}
+	return t.omitzero && isZeroValue(v)
+}
+
+// isZeroValue returns whether v is the zero value, using its IsZero method
+// if it has one, as encoding/json does for omitzero.
+func isZeroValue(v reflect.Value) bool {
+	if (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil() {
+		return true
+	}
+	if v.Type().Implements(isZeroerType) {
+		return v.Interface().(isZeroer).IsZero()
+	}
+	if v.CanAddr() && reflect.PointerTo(v.Type()).Implements(isZeroerType) {
+		return v.Addr().Interface().(isZeroer).IsZero()
+	}
+	return v.IsZero()
+}
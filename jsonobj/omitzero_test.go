@@ -0,0 +1,91 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// evenZero is zero if it's even, to test that IsZero is used.
+type evenZero int
+
+func (v *evenZero) IsZero() bool {
+	return *v%2 == 0
+}
+
+type omitZeroS struct {
+	raw Retain
+
+	Name    string    `json:"name,omitzero"`
+	Created time.Time `json:"created,omitzero"`
+	Count   evenZero  `json:"count,omitzero"`
+	Tags    []string  `json:"tags,omitzero"`
+	Ptr     *int      `json:"ptr,omitzero"`
+}
+
+func (s *omitZeroS) UnmarshalJSON(data []byte) error {
+	return s.raw.FromJSON(data, s)
+}
+
+func (s *omitZeroS) MarshalJSON() ([]byte, error) {
+	return s.raw.ToJSON(s)
+}
+
+var _ = MustRetainable(&omitZeroS{})
+
+func TestOmitZero(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	zero := 0
+
+	tests := []struct {
+		name string
+		obj  omitZeroS
+		want string
+	}{
+		{
+			name: "zero",
+			obj:  omitZeroS{},
+			want: `{}`,
+		},
+		{
+			name: "time set",
+			obj:  omitZeroS{Created: created},
+			want: `{"created":"2024-01-02T03:04:05Z"}`,
+		},
+		{
+			name: "IsZero method",
+			obj:  omitZeroS{Count: 2},
+			want: `{}`,
+		},
+		{
+			name: "IsZero method false",
+			obj:  omitZeroS{Count: 3},
+			want: `{"count":3}`,
+		},
+		{
+			name: "empty non-nil slice",
+			obj:  omitZeroS{Tags: []string{}},
+			want: `{"tags":[]}`,
+		},
+		{
+			name: "pointer to zero",
+			obj:  omitZeroS{Ptr: &zero},
+			want: `{"ptr":0}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, mustMarshal(t, &tt.obj))
+		})
+	}
+}
+
+func TestOmitZero_RoundTrip(t *testing.T) {
+	var s omitZeroS
+	require.NoError(t, json.Unmarshal([]byte(`{"name":"","created":"2024-01-02T03:04:05Z","x":1}`), &s))
+	assert.Equal(t, `{"created":"2024-01-02T03:04:05Z","x":1}`, mustMarshal(t, &s))
+}
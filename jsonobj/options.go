@@ -0,0 +1,118 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"io"
+	"maps"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// Option configures how a Retain decodes and encodes objects.
+type Option func(*options)
+
+type options struct {
+	shadow              any
+	coerceScalarToSlice bool
+	trace               io.Writer
+	versionField        string
+	migrations          map[int]migration
+	hashOrder           bool
+	unixTime            time.Duration
+	checksumField       string
+	discriminators      map[string]*discriminator
+	retainUnknownTypes  bool
+	maxStructDepth      int
+	workLimit           int
+	outputKeyCase       *KeyCase
+	trackPresence       bool
+	validators          map[string][]func(json.RawMessage) error
+	retainedSets        []string
+	strictArrays        *arrayLength
+	fieldDefaults       map[string]json.RawMessage
+	envelopeKey         string
+	retainedFallback    RetainedFallback
+	indent              *indentation
+	stripPrefix         string
+	recursiveOmitEmpty  bool
+	outputSchema        *schema
+	outputSchemaErr     error
+	insertionOrder      bool
+	retainedAsKnown     bool
+	populate            map[string]struct{}
+	trailingNewline     bool
+	unknownSampleRate   *float64
+	sampleRateErr       error
+	mergeDecode         bool
+	useNumber           bool
+	disallowUnknown     bool
+	disallowDuplicates  bool
+	retainOnly          map[string]struct{}
+	tagKey              string
+	noEscapeHTML        bool
+	codec               Codec
+	trackChanges        bool
+	retainedKeyOutput   func(string) string
+	retainedKeyInput    func(string) string
+	maxInputSize        int
+	maxUnknownFields    int
+	disallowTrailing    bool
+	unknownFieldHook    UnknownFieldHook
+	extensions          reflect.Value
+}
+
+// Configure applies opts to r. The options are used by all subsequent
+// calls to FromJSON and ToJSON, and are typically applied in
+// obj.UnmarshalJSON before calling FromJSON.
+//
+// Options can also be passed to a single FromJSON or ToJSON call, in which
+// case they're applied on top of the configured options (so call options
+// win), without modifying the configured options.
+func (r *Retain) Configure(opts ...Option) {
+	for _, opt := range opts {
+		opt(&r.opts)
+	}
+}
+
+// with returns a copy of o with opts applied. Maps and slices are copied,
+// so applying opts doesn't modify o.
+func (o options) with(opts []Option) options {
+	o.migrations = maps.Clone(o.migrations)
+	o.discriminators = maps.Clone(o.discriminators)
+	o.validators = maps.Clone(o.validators)
+	o.fieldDefaults = maps.Clone(o.fieldDefaults)
+	o.populate = maps.Clone(o.populate)
+	o.retainedSets = slices.Clip(o.retainedSets)
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithCoerceScalarToSlice allows a known slice field to be decoded from
+// a single non-array JSON value, which is treated as a one-element array.
+// For example, "tag" is decoded into a []string field as ["tag"].
+func WithCoerceScalarToSlice() Option {
+	return func(o *options) {
+		o.coerceScalarToSlice = true
+	}
+}
+
+// WithRetainedAsKnown makes ToJSON emit retained fields that have the same
+// name as a known field (such as fields added with SetUnknown, or retained
+// before the field was added to the struct) through the known field. The
+// retained value is decoded into a copy of the field as FromJSON would, and
+// emitted using the field's marshalling (including MarshalJSON methods and
+// options such as WithUnixTime) instead of the raw retained value.
+//
+// The retained value takes precedence over the value of the known field, and
+// the key is only emitted through the known field, so it's omitted if the
+// decoded value is omitted (such as by omitempty). Without this option, the
+// known field's value replaces the retained value, which is never emitted,
+// even if the known field is omitted (see CheckCollisions).
+func WithRetainedAsKnown() Option {
+	return func(o *options) {
+		o.retainedAsKnown = true
+	}
+}
@@ -0,0 +1,128 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallOptions(t *testing.T) {
+	type Obj struct {
+		Name    string `json:"name"`
+		Retries int    `json:"retries"`
+	}
+
+	var r Retain
+	r.Configure(
+		WithFieldDefault("retries", json.RawMessage(`3`)),
+		WithOutputKeyCase(SnakeCase),
+	)
+
+	var obj Obj
+	require.NoError(t, r.FromJSON([]byte(`{"name": "foo", "userId": 1}`), &obj,
+		WithFieldDefault("retries", json.RawMessage(`5`)),
+	))
+	assert.Equal(t, Obj{Name: "foo", Retries: 5}, obj, "call options should win")
+
+	got, err := r.ToJSON(obj, WithIndent("", " "), WithOutputKeyCase(KebabCase))
+	require.NoError(t, err)
+	assert.Equal(t, "{\n \"name\": \"foo\",\n \"user-id\": 1,\n \"retries\": 5\n}", string(got))
+
+	// Configured options are unchanged by the per-call options.
+	got, err = r.ToJSON(Obj{Name: "foo", Retries: 3})
+	require.NoError(t, err)
+	assert.Equal(t, `{"name":"foo","user_id":1}`, string(got))
+
+	obj = Obj{}
+	require.NoError(t, r.FromJSON([]byte(`{"name": "bar"}`), &obj))
+	assert.Equal(t, Obj{Name: "bar", Retries: 3}, obj)
+}
+
+func TestCallOptions_RetainsState(t *testing.T) {
+	var (
+		r Retain
+		s S
+	)
+	r.Consume("skip")
+	require.NoError(t, r.FromJSON([]byte(`{"name": "foo", "skip": 1, "keep": 2}`), &s, WithPresenceTracking()))
+
+	_, ok := r.GetUnknown("keep")
+	assert.True(t, ok, "retained fields should be stored in r")
+	_, ok = r.GetUnknown("skip")
+	assert.False(t, ok, "consumed fields should be dropped")
+	assert.Nil(t, r.consumed, "consumed keys should be reset")
+	assert.NotNil(t, r.PresenceBitset(), "presence should be stored in r")
+	assert.False(t, r.opts.trackPresence, "configured options should be unchanged")
+}
+
+type upperString string
+
+func (s upperString) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strings.ToUpper(string(s)))
+}
+
+func TestWithRetainedAsKnown(t *testing.T) {
+	type Obj struct {
+		Name    upperString `json:"name"`
+		Created time.Time   `json:"created"`
+		Count   int         `json:"count,omitempty"`
+	}
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		retained map[string]string
+		obj      Obj
+		want     string
+		wantErr  string
+	}{
+		{
+			name:     "disabled, known fields win",
+			retained: map[string]string{"name": `"retained"`, "count": `2`},
+			obj:      Obj{Name: "known"},
+			want:     `{"name": "KNOWN", "created": "0001-01-01T00:00:00Z"}`,
+		},
+		{
+			name:     "retained values use field marshalling",
+			opts:     []Option{WithRetainedAsKnown(), WithUnixTime(time.Second)},
+			retained: map[string]string{"name": `"retained"`, "created": `"2024-01-02T03:04:05Z"`, "other": `1`},
+			obj:      Obj{Name: "known", Count: 1},
+			want:     `{"name": "RETAINED", "created": 1704164645, "count": 1, "other": 1}`,
+		},
+		{
+			name:     "retained zero value with omitempty",
+			opts:     []Option{WithRetainedAsKnown()},
+			retained: map[string]string{"count": `0`},
+			obj:      Obj{Count: 3},
+			want:     `{"name": "", "created": "0001-01-01T00:00:00Z"}`,
+		},
+		{
+			name:     "invalid retained value",
+			opts:     []Option{WithRetainedAsKnown()},
+			retained: map[string]string{"count": `"x"`},
+			wantErr:  `retained field "count"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r Retain
+			r.Configure(tt.opts...)
+			for k, v := range tt.retained {
+				r.SetUnknown(k, json.RawMessage(v))
+			}
+
+			got, err := r.ToJSON(tt.obj)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+}
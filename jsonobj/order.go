@@ -0,0 +1,382 @@
+package jsonobj
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"reflect"
+	"slices"
+	"sort"
+	"unicode/utf8"
+)
+
+// WithHashOrder orders the keys output by ToJSON by the 64-bit FNV-1a hash of
+// the key, with ties broken by comparing the keys. Both known and retained
+// keys are ordered this way.
+//
+// This is only intended for compatibility with existing data (such as cache
+// keys) that was generated using this ordering. The default ordering (see
+// ToJSON) is already deterministic.
+func WithHashOrder() Option {
+	return func(o *options) {
+		o.hashOrder = true
+	}
+}
+
+func hashOrder(m map[string]any) []string {
+	type hashedKey struct {
+		key  string
+		hash uint64
+	}
+
+	hashed := make([]hashedKey, 0, len(m))
+	for k := range m {
+		hashed = append(hashed, hashedKey{k, keyHash(k)})
+	}
+
+	sort.Slice(hashed, func(i, j int) bool {
+		if hashed[i].hash != hashed[j].hash {
+			return hashed[i].hash < hashed[j].hash
+		}
+		return hashed[i].key < hashed[j].key
+	})
+
+	keys := make([]string, len(hashed))
+	for i, hk := range hashed {
+		keys[i] = hk.key
+	}
+	return keys
+}
+
+// keyHash returns the 64-bit FNV-1a hash of the key k.
+func keyHash(k string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(k))
+	return h.Sum64()
+}
+
+// marshalOrdered marshals the values in m using r.marshal, as a JSON object
+// with keys in the specified order. json.RawMessage values (such as retained
+// fields) are validated and written as-is, rather than re-encoded, unless
+// a Codec is configured.
+//
+// The object is written to a pooled buffer, and the returned slice is a copy
+// owned by the caller.
+func (r *Retain) marshalOrdered(keys []string, m map[string]any) ([]byte, error) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	if err := r.writeOrdered(buf, keys, m); err != nil {
+		return nil, err
+	}
+	return bytes.Clone(buf.Bytes()), nil
+}
+
+// byteWriter is implemented by bytes.Buffer and bufio.Writer.
+type byteWriter interface {
+	io.Writer
+	io.ByteWriter
+	io.StringWriter
+}
+
+// writeOrdered is similar to marshalOrdered, but writes the object to w.
+// Errors writing to w are not returned, since bytes.Buffer doesn't return
+// errors, and bufio.Writer returns them from Flush.
+func (r *Retain) writeOrdered(w byteWriter, keys []string, m map[string]any) error {
+	w.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+
+		// Keys are only written directly by the default encoder, so
+		// a Codec can marshal all keys.
+		if r.opts.codec != nil || !writeSimpleKey(w, k) {
+			keyJSON, err := r.marshal(k)
+			if err != nil {
+				return err
+			}
+			w.Write(keyJSON)
+		}
+		w.WriteByte(':')
+
+		if raw, ok := m[k].(json.RawMessage); ok && r.opts.codec == nil {
+			// Retained values are written verbatim, so they're byte-exact.
+			// A Codec marshals them below, so it controls their formatting.
+			if !json.Valid(raw) {
+				return fmt.Errorf("key %q: invalid JSON value %q", k, raw)
+			}
+			w.Write(raw)
+			continue
+		}
+
+		valueJSON, err := r.marshal(m[k])
+		if err != nil {
+			return err
+		}
+		w.Write(valueJSON)
+	}
+	w.WriteByte('}')
+	return nil
+}
+
+// WithInsertionOrder orders the keys output by ToJSON by insertion order,
+// rather than input order. Known fields are output first, in declaration
+// order (as encoding/json does), followed by retained fields in the order
+// they were added: retained fields decoded by FromJSON are in input order,
+// followed by fields added with SetUnknown in the order they were first set.
+// Replacing an existing retained field keeps its position. Any other keys
+// (such as the checksum field) are output last, sorted.
+//
+// WithHashOrder takes precedence over WithInsertionOrder.
+func WithInsertionOrder() Option {
+	return func(o *options) {
+		o.insertionOrder = true
+	}
+}
+
+// decodeObject decodes the JSON object data into r.raw, and records its keys
+// in input order in r.order. Duplicate keys keep their first position and
+// their last value (as with encoding/json), unless WithDisallowDuplicateKeys
+// is used.
+//
+// Valid objects are decoded in a single pass that records the order, while
+// other input is decoded by codec (or encoding/json if codec is nil), so
+// errors match. The order of input decoded by codec is only recorded if it's
+// also valid JSON.
+func (r *Retain) decodeObject(data []byte, codec Codec) error {
+	r.raw = nil
+	r.order = nil
+
+	valid := json.Valid(data)
+	if codec != nil || !valid || !isObject(data) {
+		unmarshal := json.Unmarshal
+		if codec != nil {
+			unmarshal = codec.Unmarshal
+		}
+		if err := unmarshal(data, &r.raw); err != nil {
+			return err
+		}
+		if !valid || !isObject(data) {
+			return nil
+		}
+	}
+
+	// If codec decoded the values, the keys are still recorded for the
+	// order and duplicate checks.
+	decoded := r.raw != nil
+	seen := make(map[string]struct{})
+	if !decoded {
+		r.raw = make(map[string]json.RawMessage)
+	}
+	return forObjectMembers(data, func(key string, value []byte) error {
+		if _, ok := seen[key]; !ok {
+			seen[key] = struct{}{}
+			r.order = append(r.order, key)
+		} else if r.opts.disallowDuplicates {
+			return &DuplicateKeyError{Key: key}
+		}
+		if !decoded {
+			r.raw[key] = slices.Clone(value)
+		}
+		return nil
+	})
+}
+
+// isObject returns whether the valid JSON value data is an object.
+func isObject(data []byte) bool {
+	i := skipSpace(data, 0)
+	return i < len(data) && data[i] == '{'
+}
+
+// forObjectMembers calls fn with each key and raw value of the valid JSON
+// object data, in input order.
+func forObjectMembers(data []byte, fn func(key string, value []byte) error) error {
+	i := skipSpace(data, 0) + 1 // skip '{'
+	for {
+		i = skipSpace(data, i)
+		switch data[i] {
+		case '}':
+			return nil
+		case ',':
+			i = skipSpace(data, i+1)
+		}
+
+		keyEnd := stringEnd(data, i)
+		key, err := unquoteKey(data[i:keyEnd])
+		if err != nil {
+			return err
+		}
+
+		i = skipSpace(data, keyEnd) + 1 // skip ':'
+		i = skipSpace(data, i)
+		valueEnd := valueEnd(data, i)
+		if err := fn(key, data[i:valueEnd]); err != nil {
+			return err
+		}
+		i = valueEnd
+	}
+}
+
+func skipSpace(data []byte, i int) int {
+	for i < len(data) {
+		switch data[i] {
+		case ' ', '\t', '\r', '\n':
+			i++
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// stringEnd returns the index after the valid JSON string starting at i.
+func stringEnd(data []byte, i int) int {
+	for i++; ; i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+}
+
+// valueEnd returns the index after the valid JSON value starting at i.
+func valueEnd(data []byte, i int) int {
+	switch data[i] {
+	case '"':
+		return stringEnd(data, i)
+	case '{', '[':
+		depth := 0
+		for ; ; i++ {
+			switch data[i] {
+			case '"':
+				i = stringEnd(data, i) - 1
+			case '{', '[':
+				depth++
+			case '}', ']':
+				if depth--; depth == 0 {
+					return i + 1
+				}
+			}
+		}
+	default:
+		// Numbers and literals end at the next delimiter or space.
+		for i < len(data) {
+			switch data[i] {
+			case ',', '}', ']', ' ', '\t', '\r', '\n':
+				return i
+			}
+			i++
+		}
+		return i
+	}
+}
+
+// unquoteKey returns the value of the quoted JSON string key.
+func unquoteKey(quoted []byte) (string, error) {
+	for _, c := range quoted {
+		if c == '\\' || c >= utf8.RuneSelf {
+			// Escapes and invalid UTF-8 are decoded as encoding/json does.
+			var key string
+			err := json.Unmarshal(quoted, &key)
+			return key, err
+		}
+	}
+	return string(quoted[1 : len(quoted)-1]), nil
+}
+
+// sortInputOrder sorts keys by their order in the input to the last FromJSON.
+// Keys that weren't in the input are sorted first.
+func (r *Retain) sortInputOrder(keys []string) {
+	idx := make(map[string]int, len(r.order))
+	for i, k := range r.order {
+		idx[k] = i
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		iIdx, iOK := idx[keys[i]]
+		jIdx, jOK := idx[keys[j]]
+		if !iOK || !jOK {
+			return !iOK && jOK
+		}
+		return iIdx < jIdx
+	})
+}
+
+// addOrder records the retained field name if it's new.
+func (r *Retain) addOrder(name string) {
+	if _, ok := r.raw[name]; !ok {
+		r.order = append(r.order, r.retainedKey(name))
+	}
+}
+
+// deleteOrder removes the retained field name from the order.
+func (r *Retain) deleteOrder(name string) {
+	key := r.retainedKey(name)
+	r.order = slices.DeleteFunc(r.order, func(k string) bool {
+		return k == key
+	})
+}
+
+// orderedKeys returns the keys of all, the object for the struct rv, in the
+// order they should be output.
+//
+// By default, keys are output in input order, followed by retained fields
+// added using SetUnknown (in the order they were added), and then known fields
+// that weren't in the input (in declaration order). Any other keys are output
+// last, sorted.
+func (r *Retain) orderedKeys(rv reflect.Value, all map[string]any) []string {
+	if r.opts.hashOrder {
+		return hashOrder(all)
+	}
+
+	keys := make([]string, 0, len(all))
+	seen := make(map[string]struct{}, len(all))
+	add := func(k string) {
+		if r.opts.outputKeyCase != nil {
+			k = r.opts.outputKeyCase.Convert(k)
+		}
+		if _, ok := all[k]; !ok {
+			return
+		}
+		if _, ok := seen[k]; ok {
+			return
+		}
+		seen[k] = struct{}{}
+		keys = append(keys, k)
+	}
+	addOrdered := func() {
+		// r.order contains output keys of retained fields.
+		for _, k := range r.order {
+			add(k)
+		}
+	}
+
+	if !r.opts.insertionOrder {
+		addOrdered()
+	}
+	forJSONField(rv, r.tagKey(), func(t jsonTag, v reflect.Value) bool {
+		if prefix, ok := fieldPrefix(t.field); ok && v.Kind() == reflect.Struct {
+			forJSONField(v, r.tagKey(), func(st jsonTag, _ reflect.Value) bool {
+				add(prefix + st.name())
+				return false
+			})
+			return false
+		}
+		add(t.name())
+		return false
+	})
+	addOrdered()
+
+	var remaining []string
+	for k := range all {
+		if _, ok := seen[k]; !ok {
+			remaining = append(remaining, k)
+		}
+	}
+	sort.Strings(remaining)
+	return append(keys, remaining...)
+}
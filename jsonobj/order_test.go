@@ -0,0 +1,253 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithHashOrder(t *testing.T) {
+	var s S
+	s.raw.Configure(WithHashOrder())
+	require.NoError(t, json.Unmarshal([]byte(`{"name": "foo", "a": 1, "b": {"y": 1, "x": 2}, "c": "<>"}`), &s))
+
+	got := mustMarshal(t, &s)
+	assert.Equal(t, `{"a":1,"c":"\u003c\u003e","b":{"y":1,"x":2},"name":"foo"}`, got)
+
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, got, mustMarshal(t, &s), "output should be stable")
+	}
+}
+
+func TestHashOrder(t *testing.T) {
+	m := map[string]any{"a": 1, "b": 2, "c": 3, "name": 4, "": 5}
+	assert.Equal(t, []string{"a", "c", "b", "name", ""}, hashOrder(m))
+}
+
+func TestWithInsertionOrder(t *testing.T) {
+	type Obj struct {
+		Z     string      `json:"z"`
+		A     string      `json:"a,omitempty"`
+		Owner prefixOwner `jsonobj:"prefix=owner_"`
+	}
+
+	tests := []struct {
+		name   string
+		json   string
+		opts   []Option
+		toOpts []Option
+		update func(*Retain)
+		want   string
+	}{
+		{
+			name: "set on empty object",
+			json: `{}`,
+			update: func(r *Retain) {
+				r.SetUnknown("y", json.RawMessage(`1`))
+				r.SetUnknown("b", json.RawMessage(`2`))
+				r.SetUnknown("x", json.RawMessage(`3`))
+			},
+			want: `{"z":"","owner_id":0,"y":1,"b":2,"x":3}`,
+		},
+		{
+			name: "decoded keys in input order",
+			json: `{"q": 1, "owner_id": 2, "z": "z", "p": 3, "a": "a", "q": 4}`,
+			want: `{"z":"z","a":"a","owner_id":2,"q":4,"p":3}`,
+		},
+		{
+			name: "decoded then mutated",
+			json: `{"q": 1, "p": 2, "o": 3}`,
+			update: func(r *Retain) {
+				r.SetUnknown("n", json.RawMessage(`4`))
+				r.SetUnknown("q", json.RawMessage(`5`))
+				r.DeleteUnknown("p")
+				r.SetUnknown("p", json.RawMessage(`6`))
+			},
+			want: `{"z":"","owner_id":0,"q":5,"o":3,"n":4,"p":6}`,
+		},
+		{
+			name: "with key case and stripped prefix",
+			json: `{"ext_second": 1, "firstKey": 2}`,
+			opts: []Option{WithOutputKeyCase(SnakeCase), WithStripPrefix("ext_")},
+			want: `{"z":"","owner_id":0,"ext_second":1,"first_key":2}`,
+		},
+		{
+			name:   "other keys sorted last",
+			json:   `{"q": 1}`,
+			toOpts: []Option{WithChecksumField("_sum")},
+			want:   `{"z":"","owner_id":0,"q":1,"_sum":"e5a516552a588361a93dac67c1de53433f3e15ada660f140087c3a790a5e78fb"}`,
+		},
+		{
+			name: "hash order takes precedence",
+			json: `{"q": 1, "p": 2}`,
+			opts: []Option{WithHashOrder()},
+			want: `{"q":1,"p":2,"z":"","owner_id":0}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				r   Retain
+				obj Obj
+			)
+			r.Configure(append([]Option{WithInsertionOrder()}, tt.opts...)...)
+			require.NoError(t, r.FromJSON([]byte(tt.json), &obj))
+			if tt.update != nil {
+				tt.update(&r)
+			}
+
+			got, err := r.ToJSON(obj, tt.toOpts...)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestRetain_InputOrder(t *testing.T) {
+	type Obj struct {
+		A string `json:"a"`
+		B string `json:"b,omitempty"`
+		C int    `json:"c"`
+	}
+
+	tests := []struct {
+		name   string
+		json   string
+		update func(*Obj, *Retain)
+		want   string
+	}{
+		{
+			name: "interleaved known and unknown",
+			json: `{"z": 1, "c": 2, "y": 3, "a": "a", "b": "b"}`,
+			want: `{"z":1,"c":2,"y":3,"a":"a","b":"b"}`,
+		},
+		{
+			name: "omitempty field dropped",
+			json: `{"b": "b", "z": 1, "a": "a", "c": 1}`,
+			update: func(o *Obj, _ *Retain) {
+				o.B = ""
+			},
+			want: `{"z":1,"a":"a","c":1}`,
+		},
+		{
+			name: "new fields appended",
+			json: `{"z": 1, "b": "b"}`,
+			update: func(_ *Obj, r *Retain) {
+				r.SetUnknown("y", json.RawMessage(`2`))
+				r.SetUnknown("x", json.RawMessage(`3`))
+				r.SetUnknown("z", json.RawMessage(`4`))
+			},
+			want: `{"z":4,"b":"b","y":2,"x":3,"a":"","c":0}`,
+		},
+		{
+			name: "deleted and re-added",
+			json: `{"z": 1, "y": 2, "a": ""}`,
+			update: func(_ *Obj, r *Retain) {
+				r.DeleteUnknown("z")
+				r.SetUnknown("z", json.RawMessage(`3`))
+			},
+			want: `{"y":2,"a":"","z":3,"c":0}`,
+		},
+		{
+			name: "not decoded",
+			update: func(o *Obj, r *Retain) {
+				r.SetUnknown("y", json.RawMessage(`1`))
+				r.SetUnknown("x", json.RawMessage(`2`))
+				o.B = "b"
+			},
+			want: `{"y":1,"x":2,"a":"","b":"b","c":0}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				r   Retain
+				obj Obj
+			)
+			if tt.json != "" {
+				require.NoError(t, r.FromJSON([]byte(tt.json), &obj))
+			}
+			if tt.update != nil {
+				tt.update(&obj, &r)
+			}
+
+			got, err := r.ToJSON(obj)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestDecodeObject(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantOrder []string
+		wantErr   string
+	}{
+		{
+			name:      "values",
+			input:     ` { "s" : "a\"}]" , "n":-1.5e3,"t":true,"o":{"k":["}",{}]},"a":[[],"]"],"z":null} `,
+			wantOrder: []string{"s", "n", "t", "o", "a", "z"},
+		},
+		{
+			name:      "escaped keys",
+			input:     `{"a\"b": 1, "é": 2, "\\": 3, "é": 4}`,
+			wantOrder: []string{`a"b`, "é", `\`},
+		},
+		{
+			name:      "invalid UTF-8 key",
+			input:     "{\"\xff\": 1}",
+			wantOrder: []string{"�"},
+		},
+		{
+			name:      "duplicate keys",
+			input:     `{"b": 1, "a": 2, "b": 3}`,
+			wantOrder: []string{"b", "a"},
+		},
+		{
+			name:  "empty",
+			input: "{}",
+		},
+		{
+			name:  "null",
+			input: "null",
+		},
+		{
+			name:    "not an object",
+			input:   "[]",
+			wantErr: "json: cannot unmarshal array",
+		},
+		{
+			name:    "invalid",
+			input:   `{"a": }`,
+			wantErr: "invalid character '}'",
+		},
+		{
+			name:    "trailing data",
+			input:   `{"a": 1} {}`,
+			wantErr: "invalid character '{' after top-level value",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r Retain
+			err := r.decodeObject([]byte(tt.input), nil /* codec */)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			var want map[string]json.RawMessage
+			require.NoError(t, json.Unmarshal([]byte(tt.input), &want))
+			assert.Equal(t, want, r.raw)
+			assert.Equal(t, tt.wantOrder, r.order)
+		})
+	}
+}
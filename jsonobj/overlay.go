@@ -0,0 +1,64 @@
+package jsonobj
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// OverlayKnown copies the known fields of src that are non-empty (using the
+// same rules as omitempty) to dst, which is useful for layering defaults over
+// parsed objects. The retained fields of both objects are unmodified.
+//
+// Known fields that retain their own unknown fields (see FromJSON) are copied
+// with a clone of their Retain, so dst and src don't share retained fields.
+// Other values are copied as with assignment, so pointers, slices and maps are
+// shared.
+//
+// dst must be a struct pointer, and src must be a struct or struct pointer of
+// the same type. opts are used to read the known fields, such as WithTagKey.
+func OverlayKnown(dst, src any, opts ...Option) error {
+	dv, ok := ensureStruct(dst, true /* requirePtr */)
+	if !ok {
+		return fmt.Errorf("OverlayKnown requires a struct pointer dst, got %T", dst)
+	}
+
+	sv, ok := ensureStruct(src, false /* requirePtr */)
+	if !ok {
+		return fmt.Errorf("OverlayKnown requires a struct src, got %T", src)
+	}
+
+	if dv.Type() != sv.Type() {
+		return fmt.Errorf("OverlayKnown requires the same types, got %T and %T", dst, src)
+	}
+
+	tagKey := options{}.with(opts).structTagKey()
+	return forJSONField(sv, tagKey, func(t jsonTag, v reflect.Value) error {
+		if isZero(v) {
+			return nil
+		}
+
+		dst, err := settableField(dv, t)
+		if err != nil {
+			return err
+		}
+		dst.Set(v)
+		cloneNestedRetain(dst)
+		return nil
+	})
+}
+
+// cloneNestedRetain replaces the Retain of the nested retain value v (or of
+// each element, for arrays) with a clone, so v doesn't share retained fields
+// with the value it was copied from. v must be addressable.
+func cloneNestedRetain(v reflect.Value) {
+	switch {
+	case v.Kind() == reflect.Array && isNestedRetainElems(v.Type()):
+		for i := 0; i < v.Len(); i++ {
+			cloneNestedRetain(v.Index(i))
+		}
+	case v.Kind() == reflect.Struct && isNestedRetain(v.Type()):
+		if r, ok := retainOf(v); ok {
+			*r = r.Clone()
+		}
+	}
+}
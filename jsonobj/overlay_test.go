@@ -0,0 +1,128 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type overlayS struct {
+	raw Retain
+
+	Name  string            `json:"name"`
+	Count int               `json:"count"`
+	Tags  []string          `json:"tags"`
+	Attrs map[string]string `json:"attrs"`
+}
+
+func (s *overlayS) UnmarshalJSON(data []byte) error {
+	return s.raw.FromJSON(data, s)
+}
+
+func (s *overlayS) MarshalJSON() ([]byte, error) {
+	return s.raw.ToJSON(s)
+}
+
+func TestOverlayKnown(t *testing.T) {
+	var dst, src overlayS
+	require.NoError(t, json.Unmarshal([]byte(`{"name": "default", "count": 1, "tags": ["a"], "dstOnly": 1}`), &dst))
+	require.NoError(t, json.Unmarshal([]byte(`{"name": "override", "count": 0, "tags": [], "attrs": {"k": "v"}, "srcOnly": 2}`), &src))
+
+	require.NoError(t, OverlayKnown(&dst, &src))
+	assert.Equal(t, "override", dst.Name)
+	assert.Equal(t, 1, dst.Count, "zero values should not overlay")
+	assert.Equal(t, []string{"a"}, dst.Tags, "empty slices should not overlay")
+	assert.Equal(t, map[string]string{"k": "v"}, dst.Attrs)
+
+	assert.JSONEq(t, `{"name": "override", "count": 1, "tags": ["a"], "attrs": {"k": "v"}, "dstOnly": 1}`, mustMarshal(t, &dst))
+	assert.JSONEq(t, `{"name": "override", "count": 0, "tags": [], "attrs": {"k": "v"}, "srcOnly": 2}`, mustMarshal(t, &src))
+
+	t.Run("src value", func(t *testing.T) {
+		var dst overlayS
+		require.NoError(t, OverlayKnown(&dst, overlayS{Name: "value"}))
+		assert.Equal(t, "value", dst.Name)
+	})
+}
+
+type overlayInner struct {
+	Name string `json:"name"`
+}
+
+func TestOverlayKnown_Options(t *testing.T) {
+	t.Run("tag key", func(t *testing.T) {
+		type apiS struct {
+			Name  string `api:"name"`
+			Other string `json:"other" api:"-"`
+		}
+
+		dst := apiS{Name: "a", Other: "a"}
+		require.NoError(t, OverlayKnown(&dst, apiS{Name: "b", Other: "b"}, WithTagKey("api")))
+		assert.Equal(t, apiS{Name: "b", Other: "a"}, dst)
+	})
+
+	t.Run("nested Retain is cloned", func(t *testing.T) {
+		type obj struct {
+			Meta nestedMeta `json:"meta"`
+		}
+
+		var (
+			src obj
+			r   Retain
+		)
+		require.NoError(t, r.FromJSON([]byte(`{"meta": {"a": 1, "future": 2}}`), &src))
+
+		var dst obj
+		require.NoError(t, OverlayKnown(&dst, &src))
+		dst.Meta.raw.SetUnknown("future", json.RawMessage(`3`))
+
+		got, ok := src.Meta.raw.GetUnknown("future")
+		require.True(t, ok)
+		assert.Equal(t, `2`, string(got), "src retained fields should be unmodified")
+	})
+}
+
+func TestOverlayKnown_Errors(t *testing.T) {
+	type embeddedS struct {
+		*overlayInner
+	}
+
+	tests := []struct {
+		name    string
+		dst     any
+		src     any
+		wantErr string
+	}{
+		{
+			name:    "dst not pointer",
+			dst:     overlayS{},
+			src:     overlayS{},
+			wantErr: "OverlayKnown requires a struct pointer dst, got jsonobj.overlayS",
+		},
+		{
+			name:    "src not struct",
+			dst:     &overlayS{},
+			src:     "str",
+			wantErr: "OverlayKnown requires a struct src, got string",
+		},
+		{
+			name:    "different types",
+			dst:     &overlayS{},
+			src:     &S{},
+			wantErr: "OverlayKnown requires the same types, got *jsonobj.overlayS and *jsonobj.S",
+		},
+		{
+			name:    "field can't be set",
+			dst:     &embeddedS{},
+			src:     embeddedS{&overlayInner{Name: "n"}},
+			wantErr: `field "name": cannot set embedded pointer to unexported struct jsonobj.overlayInner`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.EqualError(t, OverlayKnown(tt.dst, tt.src), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package jsonobj
+
+import (
+	"bytes"
+	"sync"
+)
+
+// maxPooledBuffer is the largest buffer capacity returned to bufferPool,
+// so occasional large objects don't keep large buffers alive.
+const maxPooledBuffer = 64 << 10
+
+var bufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+// putBuffer returns buf to the pool. buf's contents must not be used after
+// it's returned, so callers must copy any data they return.
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBuffer {
+		return
+	}
+	buf.Reset()
+	bufferPool.Put(buf)
+}
+
+// writeSimpleKey writes k as a JSON string if it only contains printable
+// ASCII characters that encoding/json never escapes, returning false if k
+// must be marshalled. Most keys are simple, and marshalling each key is a
+// large part of the allocations of ToJSON.
+func writeSimpleKey(w byteWriter, k string) bool {
+	for i := 0; i < len(k); i++ {
+		switch c := k[i]; {
+		case c < 0x20 || c > 0x7e:
+			return false
+		case c == '"' || c == '\\' || c == '<' || c == '>' || c == '&':
+			return false
+		}
+	}
+
+	w.WriteByte('"')
+	w.WriteString(k)
+	w.WriteByte('"')
+	return true
+}
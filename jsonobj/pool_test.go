@@ -0,0 +1,60 @@
+package jsonobj
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteSimpleKey(t *testing.T) {
+	tests := []struct {
+		key    string
+		simple bool
+	}{
+		{key: "", simple: true},
+		{key: "name", simple: true},
+		{key: "with space", simple: true},
+		{key: "snake_case-and.dots~", simple: true},
+		{key: "quote\""},
+		{key: `back\slash`},
+		{key: "<html>"},
+		{key: "a&b"},
+		{key: "tab\t"},
+		{key: "del\x7f"},
+		{key: "unicode é"},
+		{key: "invalid \xff"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.Equal(t, tt.simple, writeSimpleKey(&buf, tt.key))
+			if !tt.simple {
+				assert.Zero(t, buf.Len(), "nothing should be written for keys that aren't simple")
+				return
+			}
+
+			want, err := json.Marshal(tt.key)
+			require.NoError(t, err)
+			assert.Equal(t, string(want), buf.String(), "simple keys should match encoding/json")
+		})
+	}
+}
+
+func TestRetain_ToJSON_OwnsOutput(t *testing.T) {
+	var s S
+	require.NoError(t, s.raw.FromJSON([]byte(`{"name": "foo", "x": 1}`), &s))
+
+	first, err := s.raw.ToJSON(&s)
+	require.NoError(t, err)
+
+	s.Name = "updated name that reuses the pooled buffer"
+	second, err := s.raw.ToJSON(&s)
+	require.NoError(t, err)
+
+	assert.Equal(t, `{"name":"foo","x":1}`, string(first), "output should not alias pooled buffers")
+	assert.Equal(t, `{"name":"updated name that reuses the pooled buffer","x":1}`, string(second))
+}
@@ -0,0 +1,79 @@
+package jsonobj
+
+import (
+	"cmp"
+	"encoding/json"
+	"slices"
+	"strings"
+)
+
+// WithStripPrefix strips prefix from retained keys that have it, so they are
+// retained (and accessed with GetUnknown) without the prefix. ToJSON re-adds
+// the prefix to those keys, so objects round-trip without loss. Known fields,
+// and retained keys without the prefix, are unaffected. If the input has a key
+// both with and without the prefix, the prefixed key isn't stripped.
+//
+// Keys added using SetUnknown are emitted as-is, unless they replace a key
+// that had the prefix stripped.
+func WithStripPrefix(prefix string) Option {
+	return func(o *options) {
+		o.stripPrefix = prefix
+	}
+}
+
+// stripRetainedPrefix strips the configured prefix from retained keys,
+// recording which keys were stripped.
+//
+// A key keeps the prefix if the stripped key is already retained, such as
+// "ext_icon" when the input also has "icon", so both keys round-trip. Shorter
+// keys are stripped first, so "ext_a" is stripped to "a" before "ext_ext_a"
+// is stripped to "ext_a".
+func (r *Retain) stripRetainedPrefix() {
+	r.stripped = nil
+
+	prefix := r.opts.stripPrefix
+	if prefix == "" {
+		return
+	}
+
+	var prefixed []string
+	retained := make(map[string]json.RawMessage, len(r.raw))
+	for k, v := range r.raw {
+		if stripped, ok := strings.CutPrefix(k, prefix); ok && stripped != "" {
+			prefixed = append(prefixed, k)
+			continue
+		}
+		retained[k] = v
+	}
+	slices.SortFunc(prefixed, func(a, b string) int {
+		return cmp.Or(cmp.Compare(len(a), len(b)), strings.Compare(a, b))
+	})
+
+	for _, k := range prefixed {
+		key := strings.TrimPrefix(k, prefix)
+		if _, ok := retained[key]; ok {
+			retained[k] = r.raw[k]
+			continue
+		}
+
+		if r.stripped == nil {
+			r.stripped = make(map[string]struct{})
+		}
+		r.stripped[key] = struct{}{}
+		retained[key] = r.raw[k]
+	}
+	r.raw = retained
+}
+
+// retainedKey returns the output key for the retained key k, after
+// applying the output mapping (see WithRetainedKeyMapping).
+func (r *Retain) retainedKey(k string) string {
+	key := k
+	if r.opts.retainedKeyOutput != nil {
+		key = r.opts.retainedKeyOutput(k)
+	}
+	if _, ok := r.stripped[k]; ok {
+		return r.opts.stripPrefix + key
+	}
+	return key
+}
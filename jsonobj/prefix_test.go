@@ -0,0 +1,110 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithStripPrefix(t *testing.T) {
+	type Obj struct {
+		Name   string `json:"name"`
+		ExtKey string `json:"ext_key"`
+	}
+
+	tests := []struct {
+		name        string
+		json        string
+		want        Obj
+		wantUnknown map[string]string
+	}{
+		{
+			name: "prefixed and unprefixed keys",
+			json: `{"name": "n", "ext_key": "known", "ext_icon": "i", "color": "c"}`,
+			want: Obj{Name: "n", ExtKey: "known"},
+			wantUnknown: map[string]string{
+				"icon":  `"i"`,
+				"color": `"c"`,
+			},
+		},
+		{
+			name: "nested prefix",
+			json: `{"ext_a": 1, "ext_ext_a": 2}`,
+			wantUnknown: map[string]string{
+				"a":     `1`,
+				"ext_a": `2`,
+			},
+		},
+		{
+			name: "key equal to prefix",
+			json: `{"ext_": 1}`,
+			wantUnknown: map[string]string{
+				"ext_": `1`,
+			},
+		},
+		{
+			name: "keys with and without prefix",
+			json: `{"ext_icon": 1, "icon": 2}`,
+			wantUnknown: map[string]string{
+				"icon":     `2`,
+				"ext_icon": `1`,
+			},
+		},
+		{
+			name: "keys with and without nested prefix",
+			json: `{"ext_ext_icon": 1, "icon": 2, "ext_icon": 3}`,
+			wantUnknown: map[string]string{
+				"icon":         `2`,
+				"ext_icon":     `3`,
+				"ext_ext_icon": `1`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				r   Retain
+				obj Obj
+			)
+			r.Configure(WithStripPrefix("ext_"))
+			require.NoError(t, r.FromJSON([]byte(tt.json), &obj))
+			assert.Equal(t, tt.want, obj)
+
+			for k, v := range tt.wantUnknown {
+				got, ok := r.GetUnknown(k)
+				require.True(t, ok, "missing unknown %v", k)
+				assert.Equal(t, v, string(got))
+			}
+
+			out, err := r.ToJSON(obj)
+			require.NoError(t, err)
+
+			var wantOut map[string]any
+			require.NoError(t, json.Unmarshal([]byte(tt.json), &wantOut))
+			wantOut["name"] = obj.Name
+			wantOut["ext_key"] = obj.ExtKey
+			assert.JSONEq(t, mustMarshal(t, wantOut), string(out))
+		})
+	}
+}
+
+func TestWithStripPrefix_SetUnknown(t *testing.T) {
+	var (
+		r Retain
+		s S
+	)
+	r.Configure(WithStripPrefix("ext_"))
+	require.NoError(t, r.FromJSON([]byte(`{"ext_icon": "i", "ext_gone": 1}`), &s))
+
+	r.SetUnknown("icon", json.RawMessage(`"updated"`))
+	r.SetUnknown("added", json.RawMessage(`true`))
+	r.DeleteUnknown("gone")
+	r.SetUnknown("gone", json.RawMessage(`2`))
+
+	out, err := r.ToJSON(&s)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"ext_icon": "updated", "added": true, "gone": 2}`, string(out))
+}
@@ -0,0 +1,124 @@
+package jsonobj
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// prefixDirective flattens the fields of a nested struct field into the
+// parent object, with the given prefix:
+//
+//	Owner Owner `jsonobj:"prefix=owner_"`
+//
+// FromJSON decodes the top-level key "owner_id" into Owner's "id" field, and
+// ToJSON emits Owner's fields with the prefix. Prefixed keys that don't match
+// a field of the nested struct are retained. The nested struct's fields are
+// decoded and encoded directly, without calling its JSON methods.
+const prefixDirective = "prefix="
+
+// fieldPrefix returns the prefix of a field with the prefix directive.
+func fieldPrefix(ft reflect.StructField) (string, bool) {
+	for _, d := range strings.Split(ft.Tag.Get("jsonobj"), ",") {
+		if prefix, ok := strings.CutPrefix(d, prefixDirective); ok {
+			return prefix, true
+		}
+	}
+	return "", false
+}
+
+// decodePrefixed decodes prefixed keys into the fields of the nested struct v,
+// and returns whether any field was present. Keys are matched and decoded as
+// they are for other known fields, see lookupField and decodeField.
+func (r *Retain) decodePrefixed(t jsonTag, prefix string, v reflect.Value, known map[string]struct{}, folded map[string][]string, trace *decodeTrace) (bool, error) {
+	if v.Kind() != reflect.Struct {
+		return false, fmt.Errorf("field %q: prefix directive requires a struct, got %v", t.field.Name, v.Type())
+	}
+
+	found := false
+	err := forJSONField(v, r.tagKey(), func(st jsonTag, sv reflect.Value) error {
+		// Match and decode the field using its prefixed name.
+		st.jsonName = prefix + st.name()
+		st.aliases = prefixAll(prefix, st.aliases)
+
+		keys, rule := r.lookupField(st, known, folded)
+		if len(keys) == 0 {
+			return nil
+		}
+		if rule == matchExact {
+			rule = matchPrefix
+		}
+
+		key, fieldJSON := r.takeField(st, keys, rule, t.field.Name+"."+st.field.Name, trace)
+		found = true
+		if st.noRead {
+			return nil
+		}
+		sv, err := settableField(v, st)
+		if err != nil {
+			return err
+		}
+		return r.decodeField(st, key, fieldJSON, sv)
+	})
+	return found, err
+}
+
+func prefixAll(prefix string, names []string) []string {
+	if len(names) == 0 {
+		return nil
+	}
+
+	prefixed := make([]string, len(names))
+	for i, name := range names {
+		prefixed[i] = prefix + name
+	}
+	return prefixed
+}
+
+// addPrefixedFields adds the fields of the nested struct v to m with prefix.
+// Fields are encoded as they are for other known fields, see encodeField.
+func (r *Retain) addPrefixedFields(m map[string]any, t jsonTag, prefix string, v reflect.Value) error {
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("field %q: prefix directive requires a struct, got %v", t.field.Name, v.Type())
+	}
+
+	return forJSONField(v, r.tagKey(), func(st jsonTag, sv reflect.Value) error {
+		st.jsonName = prefix + st.name()
+		if st.noEmit || st.omitted(sv) {
+			// Known fields take precedence over retained fields.
+			delete(m, st.name())
+			return nil
+		}
+
+		fv, err := r.encodeField(st, sv)
+		if err != nil {
+			return err
+		}
+		m[st.name()] = fv
+		return nil
+	})
+}
+
+// prefixedFieldNames returns the JSON names and aliases of the fields of the
+// struct rv, with the fields of prefix fields flattened, erroring for invalid
+// prefix fields.
+func prefixedFieldNames(rv reflect.Value, tagKey string) ([]string, error) {
+	var names []string
+	err := forAllJSONFields(rv, tagKey, func(t jsonTag, v reflect.Value) error {
+		prefix, ok := fieldPrefix(t.field)
+		if !ok {
+			names = append(names, t.name())
+			names = append(names, t.aliases...)
+			return nil
+		}
+
+		if v.Kind() != reflect.Struct {
+			return fmt.Errorf("field %q: prefix directive requires a struct, got %v", t.field.Name, v.Type())
+		}
+		return forAllJSONFields(v, tagKey, func(st jsonTag, _ reflect.Value) error {
+			names = append(names, prefix+st.name())
+			return nil
+		})
+	})
+	return names, err
+}
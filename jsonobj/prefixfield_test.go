@@ -0,0 +1,137 @@
+package jsonobj
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type prefixOwner struct {
+	ID   int    `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+type prefixS struct {
+	raw Retain
+
+	Title string      `json:"title"`
+	Owner prefixOwner `jsonobj:"prefix=owner_"`
+}
+
+func (s *prefixS) UnmarshalJSON(data []byte) error {
+	return s.raw.FromJSON(data, s)
+}
+
+func (s prefixS) MarshalJSON() ([]byte, error) {
+	return s.raw.ToJSON(s)
+}
+
+func TestPrefixDirective(t *testing.T) {
+	tests := []struct {
+		name        string
+		json        string
+		want        prefixS
+		wantUnknown []string
+		wantOut     string
+	}{
+		{
+			name:    "modeled prefixed keys",
+			json:    `{"title": "t", "owner_id": 1, "owner_name": "n"}`,
+			want:    prefixS{Title: "t", Owner: prefixOwner{ID: 1, Name: "n"}},
+			wantOut: `{"title": "t", "owner_id": 1, "owner_name": "n"}`,
+		},
+		{
+			name:        "extra prefixed keys retained",
+			json:        `{"title": "t", "owner_id": 1, "owner_email": "e", "Owner": {"id": 2}}`,
+			want:        prefixS{Title: "t", Owner: prefixOwner{ID: 1}},
+			wantUnknown: []string{"Owner", "owner_email"},
+			wantOut:     `{"title": "t", "owner_id": 1, "owner_email": "e", "Owner": {"id": 2}}`,
+		},
+		{
+			name:    "missing prefixed keys",
+			json:    `{"title": "t"}`,
+			want:    prefixS{Title: "t"},
+			wantOut: `{"title": "t", "owner_id": 0}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got prefixS
+			require.NoError(t, got.UnmarshalJSON([]byte(tt.json)))
+			assert.Equal(t, tt.want.Title, got.Title)
+			assert.Equal(t, tt.want.Owner, got.Owner)
+
+			for _, k := range tt.wantUnknown {
+				_, ok := got.raw.GetUnknown(k)
+				assert.True(t, ok, "missing unknown %v", k)
+			}
+
+			assert.JSONEq(t, tt.wantOut, mustMarshal(t, got))
+		})
+	}
+}
+
+func TestPrefixDirective_FieldDecoding(t *testing.T) {
+	type details struct {
+		ID    int        `json:"id,string"`
+		Email string     `json:"email" jsonobj_aliases:"mail"`
+		Meta  nestedMeta `json:"meta"`
+	}
+	type obj struct {
+		Details details `jsonobj:"prefix=d_"`
+	}
+
+	var (
+		r   Retain
+		got obj
+	)
+	input := `{"D_ID": "3", "d_mail": "m", "d_meta": {"a": 1, "extra": 2}}`
+	require.NoError(t, r.FromJSON([]byte(input), &got))
+
+	assert.Equal(t, 3, got.Details.ID, "keys should match case-insensitively, with ,string")
+	assert.Equal(t, "m", got.Details.Email, "keys should match prefixed aliases")
+	assert.Equal(t, 1, got.Details.Meta.A)
+	assert.Empty(t, r.UnknownKeys())
+
+	out, err := r.ToJSON(got)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"d_id": "3", "d_email": "m", "d_meta": {"a": 1, "extra": 2}}`, string(out),
+		"nested retain types should retain unknown fields")
+}
+
+func TestPrefixDirective_PresenceAndTrace(t *testing.T) {
+	var (
+		r     Retain
+		s     prefixS
+		trace bytes.Buffer
+	)
+	r.Configure(WithPresenceTracking(), WithDecodeTrace(&trace))
+	require.NoError(t, r.FromJSON([]byte(`{"owner_name": "n"}`), &s))
+
+	missing, err := r.MissingKnownFields([]string{"title", "Owner"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"title"}, missing)
+	assert.Contains(t, trace.String(), `jsonobj: key "owner_name": known field Owner.Name (prefix)`)
+}
+
+func TestPrefixDirective_Retainable(t *testing.T) {
+	assert.NoError(t, Retainable(&prefixS{}))
+
+	type conflict struct {
+		S
+
+		OwnerID int         `json:"owner_id"`
+		Owner   prefixOwner `jsonobj:"prefix=owner_"`
+	}
+	assert.ErrorContains(t, Retainable(&conflict{}), `duplicate JSON field "owner_id"`)
+
+	type notStruct struct {
+		S
+
+		Owner int `jsonobj:"prefix=owner_"`
+	}
+	assert.ErrorContains(t, Retainable(&notStruct{}), `field "Owner": prefix directive requires a struct, got int`)
+}
@@ -0,0 +1,123 @@
+package jsonobj
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// WithPresenceTracking records which known fields were present in the input
+// to FromJSON, which can be queried using PresenceBitset.
+func WithPresenceTracking() Option {
+	return func(o *options) {
+		o.trackPresence = true
+	}
+}
+
+// PresenceBitset returns a bitset of the known fields present in the input to
+// the last FromJSON, or nil if presence tracking is disabled. Bit i is set if
+// the i-th known field (in declaration order) was present, with bit i stored in
+// element i/64 as 1<<(i%64). Use PresenceIndex to get the bit for a field.
+//
+// The returned bitset is owned by r and must not be modified.
+func (r *Retain) PresenceBitset() []uint64 {
+	return r.presence
+}
+
+// PresenceIndex returns the index of the known field jsonName in the bitset
+// returned by PresenceBitset, or -1 if jsonName is not a known field of the
+// last decoded type.
+func (r *Retain) PresenceIndex(jsonName string) int {
+	if r.presenceType == nil {
+		return -1
+	}
+
+	idx, ok := cachedFields(r.presenceType, r.tagKey()).index[jsonName]
+	if !ok {
+		return -1
+	}
+	return idx
+}
+
+// WasPresent returns whether the known field jsonName was present in the
+// input to the last FromJSON, including with a null value. For example, a
+// pointer field is nil both if it was absent and if it was null, and
+// WasPresent distinguishes the two.
+//
+// WasPresent requires WithPresenceTracking, and returns false if presence
+// tracking is disabled, or if jsonName is not a known field.
+func (r *Retain) WasPresent(jsonName string) bool {
+	return BitsetHas(r.presence, r.PresenceIndex(jsonName))
+}
+
+// MissingKnownFields returns the sorted JSON names in required that were not
+// present in the input to the last FromJSON. It requires presence tracking,
+// and returns an error if any name in required isn't a known field.
+func (r *Retain) MissingKnownFields(required []string) ([]string, error) {
+	if r.presenceType == nil {
+		return nil, errors.New("MissingKnownFields requires WithPresenceTracking")
+	}
+
+	var missing []string
+	for _, name := range required {
+		idx := r.PresenceIndex(name)
+		if idx < 0 {
+			return nil, fmt.Errorf("%q is not a known field of %v", name, r.presenceType)
+		}
+		if !BitsetHas(r.presence, idx) {
+			missing = append(missing, name)
+		}
+	}
+
+	slices.Sort(missing)
+	return slices.Compact(missing), nil
+}
+
+// BitsetHas returns whether bit i is set in bits.
+func BitsetHas(bits []uint64, i int) bool {
+	if i < 0 || i/64 >= len(bits) {
+		return false
+	}
+	return bits[i/64]&(1<<(i%64)) != 0
+}
+
+// presenceTracker sets bits for fields as they're decoded.
+// A nil *presenceTracker is valid, and tracks nothing.
+type presenceTracker struct {
+	bits  []uint64
+	field int
+}
+
+// resetPresence resets presence tracking for decoding into rt, returning
+// the tracker for the decode.
+func (r *Retain) resetPresence(rt reflect.Type) *presenceTracker {
+	r.presence = nil
+	r.presenceType = nil
+	if !r.opts.trackPresence {
+		return nil
+	}
+
+	numFields := len(cachedFields(rt, r.tagKey()).dominant)
+	r.presence = make([]uint64, (numFields+63)/64)
+	r.presenceType = rt
+	return &presenceTracker{bits: r.presence}
+}
+
+// next returns the index of the next field.
+func (p *presenceTracker) next() int {
+	if p == nil {
+		return -1
+	}
+
+	idx := p.field
+	p.field++
+	return idx
+}
+
+func (p *presenceTracker) set(i int) {
+	if p == nil {
+		return
+	}
+	p.bits[i/64] |= 1 << (i % 64)
+}
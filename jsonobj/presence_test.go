@@ -0,0 +1,199 @@
+package jsonobj
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithPresenceTracking(t *testing.T) {
+	type Obj struct {
+		A string `json:"a"`
+		B *int   `json:"b"`
+		C bool   `json:"-"`
+		D int    `json:"d"`
+	}
+
+	tests := []struct {
+		name        string
+		json        string
+		wantPresent []string
+	}{
+		{
+			name: "empty",
+			json: `{}`,
+		},
+		{
+			name:        "some fields",
+			json:        `{"a": "", "d": 1, "other": 2}`,
+			wantPresent: []string{"a", "d"},
+		},
+		{
+			name:        "null is present",
+			json:        `{"b": null}`,
+			wantPresent: []string{"b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				r   Retain
+				obj Obj
+			)
+			r.Configure(WithPresenceTracking())
+			require.NoError(t, r.FromJSON([]byte(tt.json), &obj))
+
+			bits := r.PresenceBitset()
+			require.Len(t, bits, 1)
+
+			var present []string
+			for _, name := range []string{"a", "b", "C", "d", "other"} {
+				if BitsetHas(bits, r.PresenceIndex(name)) {
+					present = append(present, name)
+				}
+			}
+			assert.Equal(t, tt.wantPresent, present)
+		})
+	}
+}
+
+func TestWithPresenceTracking_WideStruct(t *testing.T) {
+	// Build a struct with 100 fields, F0 to F99.
+	fields := make([]reflect.StructField, 100)
+	for i := range fields {
+		fields[i] = reflect.StructField{
+			Name: fmt.Sprintf("F%v", i),
+			Type: reflect.TypeOf(0),
+		}
+	}
+	obj := reflect.New(reflect.StructOf(fields)).Interface()
+
+	var r Retain
+	r.Configure(WithPresenceTracking())
+	require.NoError(t, r.FromJSON([]byte(`{"F0": 1, "F63": 1, "F64": 1, "F99": 1}`), obj))
+
+	bits := r.PresenceBitset()
+	assert.Equal(t, []uint64{1 | 1<<63, 1 | 1<<35}, bits)
+
+	var present []string
+	for i := range fields {
+		name := fields[i].Name
+		if BitsetHas(bits, r.PresenceIndex(name)) {
+			present = append(present, name)
+		}
+	}
+	assert.Equal(t, "F0,F63,F64,F99", strings.Join(present, ","))
+}
+
+func TestPresenceBitset_Disabled(t *testing.T) {
+	var s S
+	require.NoError(t, s.raw.FromJSON([]byte(`{"name": "foo"}`), &s))
+	assert.Nil(t, s.raw.PresenceBitset())
+	assert.Equal(t, -1, s.raw.PresenceIndex("name"))
+	assert.False(t, BitsetHas(nil, 0))
+}
+
+func TestMissingKnownFields(t *testing.T) {
+	type Obj struct {
+		A string `json:"a"`
+		B *int   `json:"b"`
+		C bool   `json:"c"`
+	}
+
+	tests := []struct {
+		name     string
+		json     string
+		required []string
+		want     []string
+		wantErr  string
+	}{
+		{
+			name:     "all present",
+			json:     `{"a": "", "b": null, "c": false}`,
+			required: []string{"a", "b", "c"},
+		},
+		{
+			name:     "missing sorted",
+			json:     `{"b": 1, "other": 1}`,
+			required: []string{"c", "b", "a", "c"},
+			want:     []string{"a", "c"},
+		},
+		{
+			name:     "no required",
+			json:     `{}`,
+			required: nil,
+		},
+		{
+			name:     "unknown name",
+			json:     `{"other": 1}`,
+			required: []string{"a", "other"},
+			wantErr:  `"other" is not a known field of jsonobj.Obj`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				r   Retain
+				obj Obj
+			)
+			r.Configure(WithPresenceTracking())
+			require.NoError(t, r.FromJSON([]byte(tt.json), &obj))
+
+			got, err := r.MissingKnownFields(tt.required)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMissingKnownFields_Disabled(t *testing.T) {
+	var (
+		r Retain
+		s S
+	)
+	require.NoError(t, r.FromJSON([]byte(`{}`), &s))
+
+	_, err := r.MissingKnownFields([]string{"name"})
+	assert.EqualError(t, err, "MissingKnownFields requires WithPresenceTracking")
+}
+
+func TestWasPresent(t *testing.T) {
+	type Obj struct {
+		Name *string `json:"name"`
+		Age  *int    `json:"age"`
+		Nick *string `json:"nick"`
+	}
+
+	var (
+		r   Retain
+		obj Obj
+	)
+	r.Configure(WithPresenceTracking())
+	require.NoError(t, r.FromJSON([]byte(`{"name": null, "AGE": 3, "other": 1}`), &obj))
+
+	assert.Nil(t, obj.Name)
+	assert.True(t, r.WasPresent("name"), "null field should be present")
+	assert.True(t, r.WasPresent("age"), "case-insensitive match should be present")
+	assert.False(t, r.WasPresent("nick"), "absent field")
+	assert.False(t, r.WasPresent("other"), "unknown fields are not known fields")
+	assert.False(t, r.WasPresent("missing"), "missing field")
+
+	allocs := testing.AllocsPerRun(10, func() {
+		r.WasPresent("age")
+	})
+	assert.Zero(t, allocs, "WasPresent should not allocate")
+
+	var disabled Retain
+	require.NoError(t, disabled.FromJSON([]byte(`{"name": null}`), &obj))
+	assert.False(t, disabled.WasPresent("name"), "presence tracking is disabled")
+}
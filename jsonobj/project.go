@@ -0,0 +1,21 @@
+package jsonobj
+
+// FromJSONProject is like FromJSON, but only decodes the known fields named
+// in populate (by JSON name). The input keys of all other known fields are
+// retained, and can be decoded later, such as using GetUnknown.
+//
+// ToJSON emits the retained value of known fields that weren't populated,
+// rather than the field's value, until the retained value is removed using
+// DeleteUnknown.
+func (r *Retain) FromJSONProject(data []byte, obj any, populate []string) error {
+	return r.FromJSON(data, obj, withPopulate(populate))
+}
+
+func withPopulate(names []string) Option {
+	return func(o *options) {
+		o.populate = make(map[string]struct{}, len(names))
+		for _, name := range names {
+			o.populate[name] = struct{}{}
+		}
+	}
+}
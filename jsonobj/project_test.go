@@ -0,0 +1,55 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromJSONProject(t *testing.T) {
+	type Doc struct {
+		ID    int            `json:"id"`
+		Title string         `json:"title"`
+		Body  map[string]any `json:"body"`
+		Tags  []string       `json:"tags,omitempty"`
+	}
+
+	input := `{"id": 1, "title": "t", "body": {"z": 1, "a": [1.50]}, "extra": true}`
+
+	var (
+		r   Retain
+		doc Doc
+	)
+	require.NoError(t, r.FromJSONProject([]byte(input), &doc, []string{"id", "title"}))
+	assert.Equal(t, Doc{ID: 1, Title: "t"}, doc)
+
+	body, ok := r.GetUnknown("body")
+	require.True(t, ok, "unpopulated known field should be retained")
+	assert.Equal(t, `{"z": 1, "a": [1.50]}`, string(body))
+
+	doc.Title = "updated"
+	got, err := r.ToJSON(doc)
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":1,"title":"updated","body":{"z": 1, "a": [1.50]},"extra":true}`, string(got))
+
+	// Once the retained value is removed, the field is emitted.
+	require.NoError(t, json.Unmarshal(body, &doc.Body))
+	r.DeleteUnknown("body")
+	got, err = r.ToJSON(doc)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"body":{"z":1,"a":[1.5]},"extra":true,"id":1,"title":"updated"}`, string(got))
+
+	// Missing unpopulated fields are emitted from the struct.
+	doc.Tags = []string{"x"}
+	got, err = r.ToJSON(doc)
+	require.NoError(t, err)
+	assert.Contains(t, string(got), `"tags":["x"]`)
+
+	// A later FromJSON decodes all fields.
+	require.NoError(t, r.FromJSON([]byte(input), &doc))
+	_, ok = r.GetUnknown("body")
+	assert.False(t, ok, "FromJSON should decode all known fields")
+	assert.Equal(t, map[string]any{"z": 1.0, "a": []any{1.5}}, doc.Body)
+}
@@ -0,0 +1,56 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// rawInputDirective marks a json.RawMessage field that is populated with
+// the full input in FromJSON, and is excluded from ToJSON:
+//
+//	Raw json.RawMessage `jsonobj:",rawinput"`
+//
+// A struct may have at most one rawinput field.
+const rawInputDirective = "rawinput"
+
+var rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+
+// rawInputField returns the rawinput field of the struct rv if it has one,
+// or an invalid value otherwise.
+func rawInputField(rv reflect.Value) (reflect.Value, error) {
+	var (
+		rt    = rv.Type()
+		found reflect.Value
+		name  string
+	)
+	for f := 0; f < rt.NumField(); f++ {
+		ft := rt.Field(f)
+		if !ft.IsExported() || !hasDirective(ft, rawInputDirective) {
+			continue
+		}
+
+		if ft.Type != rawMessageType {
+			return reflect.Value{}, fmt.Errorf("rawinput field %q must be json.RawMessage, got %v", ft.Name, ft.Type)
+		}
+		if found.IsValid() {
+			return reflect.Value{}, fmt.Errorf("multiple rawinput fields %q and %q", name, ft.Name)
+		}
+
+		found = rv.Field(f)
+		name = ft.Name
+	}
+	return found, nil
+}
+
+// setRawInput sets a copy of data in the rawinput field of rv, if present.
+func setRawInput(rv reflect.Value, data []byte) error {
+	fv, err := rawInputField(rv)
+	if err != nil || !fv.IsValid() {
+		return err
+	}
+
+	// data may be reused by the caller after UnmarshalJSON returns.
+	fv.SetBytes(append([]byte(nil), data...))
+	return nil
+}
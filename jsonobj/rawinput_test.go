@@ -0,0 +1,107 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type rawInputS struct {
+	raw Retain
+
+	Name  string          `json:"name"`
+	Input json.RawMessage `jsonobj:",rawinput"`
+}
+
+func (s *rawInputS) UnmarshalJSON(data []byte) error {
+	return s.raw.FromJSON(data, s)
+}
+
+func (s *rawInputS) MarshalJSON() ([]byte, error) {
+	return s.raw.ToJSON(s)
+}
+
+func TestRawInput(t *testing.T) {
+	input := `{"name": "foo", "Input": "not a field", "other": [1, 2]}`
+
+	var s rawInputS
+	require.NoError(t, json.Unmarshal([]byte(input), &s))
+	assert.Equal(t, "foo", s.Name)
+	assert.Equal(t, input, string(s.Input))
+
+	t.Run("excluded from ToJSON", func(t *testing.T) {
+		s.Name = "bar"
+		assert.JSONEq(t, `{"name": "bar", "Input": "not a field", "other": [1, 2]}`, mustMarshal(t, &s))
+	})
+
+	t.Run("input is copied", func(t *testing.T) {
+		data := []byte(`{"name": "foo"}`)
+
+		var s rawInputS
+		require.NoError(t, s.UnmarshalJSON(data))
+		data[2] = 'N'
+		assert.Equal(t, `{"name": "foo"}`, string(s.Input))
+	})
+}
+
+func TestRawInput_Retainable(t *testing.T) {
+	type base struct {
+		json.Marshaler
+		json.Unmarshaler
+
+		raw Retain
+	}
+
+	type Valid struct {
+		base
+		Input json.RawMessage `jsonobj:",rawinput"`
+	}
+
+	type WrongType struct {
+		base
+		Input []byte `jsonobj:",rawinput"`
+	}
+
+	type Multiple struct {
+		base
+		Input1 json.RawMessage `jsonobj:",rawinput"`
+		Input2 json.RawMessage `jsonobj:",rawinput"`
+	}
+
+	tests := []struct {
+		v interface {
+			json.Marshaler
+			json.Unmarshaler
+		}
+		wantErr string
+	}{
+		{
+			v: &Valid{},
+		},
+		{
+			v:       &WrongType{},
+			wantErr: `rawinput field "Input" must be json.RawMessage, got []uint8`,
+		},
+		{
+			v:       &Multiple{},
+			wantErr: `multiple rawinput fields "Input1" and "Input2"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%T", tt.v), func(t *testing.T) {
+			err := Retainable(tt.v)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, fmt.Sprintf("%T not Retainable: %v", tt.v, tt.wantErr))
+
+			var r Retain
+			assert.EqualError(t, r.FromJSON([]byte(`{}`), tt.v), tt.wantErr)
+		})
+	}
+}
@@ -5,73 +5,640 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"slices"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 // Retain preserves unknown fields when marshalling JSON.
 // It should be added as an unexported field in a struct,
 // with UnmarshalJSON / MarshalJSON methods that call
 // FromJSON and ToJSON.
+//
+// UnmarshalJSON must have a pointer receiver. MarshalJSON should have a value
+// receiver, so the object is marshalled correctly when stored by value in
+// maps or other containers, see LintValueTypes.
+//
+// Methods that modify the Retain must not be called concurrently with any
+// other method. These are the methods that decode or patch objects (such as
+// FromJSON and ApplyMergePatches), configure the Retain (Configure and the
+// Register methods), or modify retained fields (such as SetUnknown and
+// Consume). All other methods, such as ToJSON and GetUnknown, only read the
+// Retain, and are safe for concurrent use with each other, as long as obj
+// isn't concurrently modified.
+// To modify copies of an object concurrently, copy the Retain using Clone.
 type Retain struct {
-	raw map[string]json.RawMessage
+	raw  map[string]json.RawMessage
+	opts options
+
+	// consumed is the set of keys to drop in the next FromJSON.
+	consumed map[string]struct{}
+
+	// presence tracks the known fields present in the last FromJSON,
+	// see WithPresenceTracking.
+	presence     []uint64
+	presenceType reflect.Type
+
+	// envelope holds the sibling keys of the envelope key, and envelopeOrder
+	// is the input order of the envelope's keys, see WithEnvelopeKey.
+	envelope      map[string]json.RawMessage
+	envelopeOrder []string
+
+	// stripped is the set of retained keys that had the prefix stripped,
+	// see WithStripPrefix.
+	stripped map[string]struct{}
+
+	// projected is the set of known fields that were retained rather than
+	// decoded, see FromJSONProject.
+	projected map[string]struct{}
+
+	// order is the output order of keys: the keys of the last FromJSON input
+	// in input order, followed by keys added using SetUnknown. It may contain
+	// keys that are no longer present.
+	order []string
+
+	// snapshot holds the encoded known fields of the last FromJSON, and
+	// unchanged is the set of known fields to omit from ToJSONChanges,
+	// see WithChangeTracking.
+	snapshot  map[string]json.RawMessage
+	unchanged map[string]struct{}
 }
 
 // FromJSON should be called from obj.UnmarshalJSON where obj is the struct for
 // which unknown fields should be retained.
-func (r *Retain) FromJSON(data []byte, obj any) error {
+//
+// Only known fields present in the input are decoded, as with encoding/json,
+// so fields set before calling FromJSON (such as defaults set in
+// obj.UnmarshalJSON) are preserved if they're absent from the input, unless
+// WithFieldDefault is used for the field. Retained fields are always replaced
+// by the unknown fields of the input.
+//
+// As with encoding/json, input keys match known fields case-insensitively,
+// preferring an exact match. All keys that match a known field are consumed,
+// so they're not retained.
+//
+// Renamed fields can list their former names in a `jsonobj_aliases` tag,
+// such as `json:"path" jsonobj_aliases:"slug,url"`. Input keys that match an
+// alias exactly are decoded into the field (if the current name is absent)
+// and consumed, so ToJSON only outputs the current name.
+//
+// Known fields with a struct type (or struct pointer type) that has its own
+// exported Retain field or implements RetainAccessor, but has no UnmarshalJSON
+// or MarshalJSON methods, retain their
+// unknown fields in their own Retain, and ToJSON outputs them. Slices and
+// arrays of these types retain unknown fields for each element. Recursion is
+// limited to these fields: other fields, including maps of these types, are
+// decoded using encoding/json.
+//
+// opts override the configured options for this call only, see Configure.
+func (r *Retain) FromJSON(data []byte, obj any, opts ...Option) error {
+	if len(opts) > 0 {
+		rc := *r
+		rc.opts = r.opts.with(opts)
+		err := rc.FromJSON(data, obj)
+
+		rc.opts = r.opts
+		*r = rc
+		return err
+	}
+
+	if isNilObj(obj) {
+		return errors.New("FromJSON requires a non-nil struct pointer")
+	}
 	rv, ok := ensureStruct(obj, true /* requirePtr */)
 	if !ok {
 		return fmt.Errorf("FromJSON requires a struct pointer, got %T", obj)
 	}
+	defer r.resetConsumed()
+
+	if r.opts.maxInputSize > 0 {
+		if err := checkInputSize(data, r.opts.maxInputSize); err != nil {
+			return err
+		}
+	}
+	if r.opts.workLimit > 0 {
+		if err := checkWorkLimit(data, r.opts.workLimit); err != nil {
+			return err
+		}
+	}
+	if r.opts.disallowTrailing {
+		if err := checkTrailingData(data); err != nil {
+			return err
+		}
+	}
 
-	if err := json.Unmarshal(data, &r.raw); err != nil {
+	input := data
+	if r.opts.envelopeKey != "" {
+		var err error
+		if data, err = r.unwrapEnvelope(data); err != nil {
+			return err
+		}
+	}
+
+	// Retained fields are replaced by the input's unknown fields, rather than
+	// merged with fields retained by an earlier FromJSON (see WithMergeDecode).
+	prev := r.retained()
+	if err := r.decodeObject(data, r.opts.codec); err != nil {
 		return err
 	}
 
-	if err := forJSONField(rv, func(t jsonTag, v reflect.Value) error {
-		fieldJSON, ok := r.raw[t.name()]
-		if !ok {
+	if err := setRawInput(rv, input); err != nil {
+		return err
+	}
+
+	return r.decodeRetained(rv, prev)
+}
+
+// decodeRetained decodes the known fields of rv from r.raw, which holds all
+// fields of the input, and retains the remaining fields. prev is the retained
+// state before decoding, see WithMergeDecode.
+func (r *Retain) decodeRetained(rv reflect.Value, prev retainedState) error {
+	if err := r.checkSampleRate(); err != nil {
+		return err
+	}
+	if err := r.verifyChecksum(); err != nil {
+		return err
+	}
+
+	for k := range r.consumed {
+		delete(r.raw, k)
+	}
+
+	if err := r.migrate(); err != nil {
+		return err
+	}
+
+	if r.opts.maxStructDepth > 0 {
+		if err := checkStructDepth(rv.Type(), r.tagKey(), r.raw, r.opts.maxStructDepth); err != nil {
+			return err
+		}
+	}
+
+	fields := cachedFields(rv.Type(), r.tagKey())
+	folded := foldKeys(r.raw)
+	if err := r.checkUnknownLimit(fields, folded); err != nil {
+		return err
+	}
+	if r.opts.shadow != nil {
+		if err := r.decodeShadow(folded); err != nil {
+			return err
+		}
+	}
+
+	r.projected = nil
+	r.snapshot = nil
+	if r.opts.populate != nil {
+		r.projected = make(map[string]struct{})
+	}
+
+	trace := newDecodeTrace(r.opts.trace, r.raw)
+	presence := r.resetPresence(rv.Type())
+	if err := forFields(rv, fields.dominant, func(t jsonTag, v reflect.Value) error {
+		fieldIdx := presence.next()
+		if r.opts.populate != nil {
+			if _, ok := r.opts.populate[t.name()]; !ok {
+				r.projected[t.name()] = struct{}{}
+				return nil
+			}
+		}
+		settable := func() (reflect.Value, error) {
+			if v.CanSet() {
+				return v, nil
+			}
+			// Nil embedded pointers are only allocated if the field is decoded.
+			return settableField(rv, t)
+		}
+		if prefix, ok := fieldPrefix(t.field); ok {
+			v, err := settable()
+			if err != nil {
+				return err
+			}
+			found, err := r.decodePrefixed(t, prefix, v, fields.known, folded, trace)
+			if found {
+				presence.set(fieldIdx)
+			}
+			return err
+		}
+
+		keys, rule := r.lookupField(t, fields.known, folded)
+		if len(keys) == 0 {
+			if def, ok := r.opts.fieldDefaults[t.name()]; ok && !r.opts.mergeDecode && !t.noRead {
+				v, err := settable()
+				if err != nil {
+					return err
+				}
+				return r.decodeField(t, t.name(), def, v)
+			}
 			return nil
 		}
 
-		delete(r.raw, t.name())
-		return json.Unmarshal(fieldJSON, v.Addr().Interface())
+		key, fieldJSON := r.takeField(t, keys, rule, t.field.Name, trace)
+		presence.set(fieldIdx)
+		if t.noRead {
+			return nil
+		}
+		v, err := settable()
+		if err != nil {
+			return err
+		}
+		return r.decodeField(t, key, fieldJSON, v)
 	}); err != nil {
 		return err
 	}
+	if err := r.decodeExtensions(trace); err != nil {
+		return err
+	}
+	trace.write()
 
+	if err := r.checkUnknown(); err != nil {
+		return err
+	}
+	r.filterUnknown()
+	r.sampleUnknown()
+	r.stripRetainedPrefix()
+	if err := r.mapRetainedKeys(); err != nil {
+		return err
+	}
+	if err := r.runUnknownFieldHook(); err != nil {
+		return err
+	}
+	if r.opts.mergeDecode {
+		r.mergeRetained(prev)
+	}
 	if len(r.raw) == 0 {
 		r.raw = nil
 	}
 
-	return nil
+	return r.snapshotKnown(rv)
+}
+
+// lookupField returns the keys in r.raw that match the known field t, ending
+// with the key to decode, and the rule used to match that key. folded indexes
+// the keys of the input, see foldKeys.
+//
+// As with encoding/json, keys match case-insensitively unless they're the
+// exact name (or alias) of another known field in known. Keys also match the
+// field's aliases exactly. An exact match is preferred, followed by an alias,
+// and otherwise the last matching key in the input is decoded.
+func (r *Retain) lookupField(t jsonTag, known map[string]struct{}, folded map[string][]string) (keys []string, rule matchRule) {
+	name := t.name()
+	var aliases []string
+	for _, alias := range t.aliases {
+		if _, ok := r.raw[alias]; ok && alias != name {
+			aliases = append(aliases, alias)
+		}
+	}
+	for _, k := range folded[foldKey(name)] {
+		if k == name || t.isAlias(k) {
+			continue
+		}
+		if _, ok := known[k]; ok {
+			continue
+		}
+		if _, ok := r.raw[k]; !ok {
+			// Already decoded into another field.
+			continue
+		}
+		keys = append(keys, k)
+	}
+	if len(keys) > 1 {
+		r.sortInputOrder(keys)
+	}
+	if len(aliases) > 1 {
+		r.sortInputOrder(aliases)
+	}
+	keys = append(keys, aliases...)
+
+	if _, ok := r.raw[name]; ok {
+		return append(keys, name), matchExact
+	}
+	if len(aliases) > 0 {
+		return keys, matchAlias
+	}
+	return keys, matchFold
+}
+
+// takeField removes the keys matched to the known field t by lookupField from
+// r.raw, recording them in trace with fieldName, and returns the key to decode
+// and its value.
+func (r *Retain) takeField(t jsonTag, keys []string, rule matchRule, fieldName string, trace *decodeTrace) (string, json.RawMessage) {
+	key := keys[len(keys)-1]
+	fieldJSON := r.raw[key]
+	for _, k := range keys {
+		delete(r.raw, k)
+		switch {
+		case k == key:
+			trace.matched(k, fieldName, rule)
+		case t.isAlias(k):
+			trace.matched(k, fieldName, matchAlias)
+		default:
+			trace.matched(k, fieldName, matchFold)
+		}
+	}
+	return key, fieldJSON
+}
+
+// foldKeys indexes the keys of raw by foldKey, so known fields can be matched
+// case-insensitively without comparing each field to every key.
+func foldKeys(raw map[string]json.RawMessage) map[string][]string {
+	folded := make(map[string][]string, len(raw))
+	for k := range raw {
+		fk := foldKey(k)
+		folded[fk] = append(folded[fk], k)
+	}
+	return folded
+}
+
+// foldKey returns the same key for strings that are equal under
+// strings.EqualFold, by replacing each rune with the smallest rune
+// that it folds to.
+func foldKey(s string) string {
+	ascii := true
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		// Upper case letters are the smallest in their fold orbits.
+		return strings.ToUpper(s)
+	}
+
+	var sb strings.Builder
+	for _, c := range s {
+		smallest := c
+		for f := unicode.SimpleFold(c); f != c; f = unicode.SimpleFold(f) {
+			smallest = min(smallest, f)
+		}
+		sb.WriteRune(smallest)
+	}
+	return sb.String()
+}
+
+// decodeField decodes fieldJSON from the input key into the known field v.
+func (r *Retain) decodeField(t jsonTag, key string, fieldJSON json.RawMessage, v reflect.Value) error {
+	if d, ok := r.opts.discriminators[t.name()]; ok {
+		return r.decodeDiscriminated(t, d, key, fieldJSON, v)
+	}
+	if r.opts.coerceScalarToSlice {
+		fieldJSON = coerceToSlice(fieldJSON, v.Type())
+	}
+	if r.opts.strictArrays != nil {
+		if err := checkArrayLength(t.name(), fieldJSON, v.Type(), *r.opts.strictArrays); err != nil {
+			return err
+		}
+	}
+	if r.opts.unixTime != 0 && v.Type() == timeType && isJSONNumber(fieldJSON) {
+		tv, err := unixTimeFromJSON(fieldJSON, r.opts.unixTime)
+		if err != nil {
+			return fieldError(t, key, err)
+		}
+		v.Set(reflect.ValueOf(tv))
+		return nil
+	}
+	if t.quoted {
+		return decodeQuoted(t, fieldJSON, v)
+	}
+	if ok, err := decodeNested(fieldJSON, v); ok {
+		return fieldError(t, key, err)
+	}
+	return fieldError(t, key, r.unmarshal(fieldJSON, v.Addr().Interface()))
+}
+
+// fieldError adds the input key and struct field of t to the error err from
+// decoding the field, or returns nil if err is nil.
+func fieldError(t jsonTag, key string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("field %q (%v): %w", key, t.field.Name, err)
 }
 
 // ToJSON should be called from obj.MarshalJSON where obj is the struct being
 // marshalled with unknown fields (retained in FromJSON).
-func (r *Retain) ToJSON(obj any) ([]byte, error) {
+//
+// Keys are output in the order of the input to the last FromJSON, so decoded
+// objects round-trip with minimal changes. Keys that weren't in the input are
+// output after the input keys: retained fields in the order they were added
+// using SetUnknown, followed by known fields in declaration order.
+//
+// Retained fields are written exactly as they were in the input (or as set by
+// SetUnknown), without reformatting whitespace or re-escaping strings, so
+// unmodified values are byte-exact. If WithCodec is used, retained values are
+// marshalled by the codec instead.
+// Known fields take precedence over retained fields with the same name, even
+// if the known field is omitted, see CheckCollisions.
+//
+// Known fields are encoded (and decoded by FromJSON) using encoding/json, so
+// they round-trip as they do with encoding/json. For example, map fields with
+// integer or encoding.TextMarshaler keys use string object keys.
+//
+// opts override the configured options for this call only, see Configure.
+func (r *Retain) ToJSON(obj any, opts ...Option) ([]byte, error) {
+	if len(opts) > 0 {
+		rc := *r
+		rc.opts = r.opts.with(opts)
+		return rc.ToJSON(obj)
+	}
+
+	keys, all, err := r.outputFields("ToJSON", obj)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := r.marshalOrdered(keys, all)
+	if err == nil && r.opts.envelopeKey != "" {
+		out, err = r.wrapEnvelope(out)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := r.validateSchema(out); err != nil {
+		return nil, err
+	}
+	return r.formatOutput(out)
+}
+
+// outputFields returns the keys and values of the object output for obj,
+// with keys in output order. method is the calling method, for errors.
+func (r *Retain) outputFields(method string, obj any) ([]string, map[string]any, error) {
+	if isNilObj(obj) {
+		return nil, nil, fmt.Errorf("%v requires a non-nil struct", method)
+	}
 	rv, ok := ensureStruct(obj, false /* requirePtr */)
 	if !ok {
-		return nil, fmt.Errorf("ToJSON requires a struct, got %T", obj)
+		return nil, nil, fmt.Errorf("%v requires a struct, got %T", method, obj)
 	}
 
 	// Create a copy since we mutate the map below
 	// and ToJSON should be safe for concurrent-use.
 	all := make(map[string]any, len(r.raw))
-	for k, v := range r.raw {
-		all[k] = v
+	if err := r.addRetainedFields(all); err != nil {
+		return nil, nil, err
+	}
+
+	if err := r.salvageRetained(all); err != nil {
+		return nil, nil, err
+	}
+	if err := r.normalizeSets(all); err != nil {
+		return nil, nil, err
+	}
+	if err := r.addKnownFields(all, rv); err != nil {
+		return nil, nil, err
+	}
+	for k := range r.unchanged {
+		delete(all, k)
+	}
+	if err := r.omitEmptyFields(all); err != nil {
+		return nil, nil, err
+	}
+	if r.opts.outputKeyCase != nil {
+		var err error
+		if all, err = convertKeys(all, r.opts.outputKeyCase); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := r.addChecksum(all); err != nil {
+		return nil, nil, err
 	}
 
-	forJSONField(rv, func(t jsonTag, v reflect.Value) struct{} {
-		if t.omitEmpty() && isZero(v) {
-			return struct{}{}
+	return r.orderedKeys(rv, all), all, nil
+}
+
+// marshalObject marshals the object m using the configured key order,
+// or sorted keys (as json.Marshal does).
+func (r *Retain) marshalObject(m map[string]any) ([]byte, error) {
+	if r.opts.hashOrder {
+		return r.marshalOrdered(hashOrder(m), m)
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return r.marshalOrdered(keys, m)
+}
+
+// addKnownFields adds the JSON fields of the struct rv to m.
+//
+// Known fields take precedence over retained fields with the same name (which
+// can be added using SetUnknown): the known field replaces the retained field,
+// and if the known field is omitted, so is the retained field. The exceptions
+// are known fields whose retained value is intentionally output instead, see
+// retainedInPlace.
+func (r *Retain) addKnownFields(m map[string]any, rv reflect.Value) error {
+	return forJSONField(rv, r.tagKey(), func(t jsonTag, v reflect.Value) error {
+		if r.retainedInPlace(t, v) {
+			return nil
+		}
+		if _, ok := fieldByIndex(rv, t); !ok {
+			// As with encoding/json, fields of nil embedded pointers are omitted.
+			delete(m, t.name())
+			return nil
+		}
+		if t.noEmit {
+			delete(m, t.name())
+			return nil
+		}
+		if prefix, ok := fieldPrefix(t.field); ok {
+			return r.addPrefixedFields(m, t, prefix, v)
+		}
+		if r.opts.retainedAsKnown {
+			retainedV, ok, err := r.retainedFieldValue(t, v)
+			if err != nil {
+				return err
+			}
+			if ok {
+				// Only emit the key using the known field.
+				delete(m, t.name())
+				v = retainedV
+			}
+		}
+		if t.omitted(v) {
+			delete(m, t.name())
+			return nil
+		}
+
+		fv, err := r.encodeField(t, v)
+		if err != nil {
+			return err
 		}
 
-		all[t.name()] = v.Interface()
-		return struct{}{}
+		if def, ok := r.opts.fieldDefaults[t.name()]; ok {
+			isDefault, err := equalsDefault(fv, def)
+			if err != nil {
+				return fmt.Errorf("field %q: %w", t.name(), err)
+			}
+			if isDefault {
+				delete(m, t.name())
+				return nil
+			}
+		}
+
+		m[t.name()] = fv
+		return nil
 	})
+}
+
+// retainedInPlace returns whether the retained value of the known field t
+// (with value v) is output instead of the field: fields that weren't
+// populated by FromJSONProject, and discriminated fields with an unknown
+// type (see WithRetainUnknownTypes).
+func (r *Retain) retainedInPlace(t jsonTag, v reflect.Value) bool {
+	if _, retained := r.raw[t.name()]; !retained {
+		return false
+	}
+	if _, ok := r.projected[t.name()]; ok {
+		return true
+	}
+	_, ok := r.opts.discriminators[t.name()]
+	return ok && v.Kind() == reflect.Interface && v.IsNil()
+}
+
+// retainedFieldValue returns the retained value for the known field v
+// decoded into a new value, if there's a retained value.
+func (r *Retain) retainedFieldValue(t jsonTag, v reflect.Value) (reflect.Value, bool, error) {
+	fieldJSON, ok := r.raw[t.name()]
+	if !ok {
+		return reflect.Value{}, false, nil
+	}
+	if _, ok := r.stripped[t.name()]; ok {
+		// The retained key is emitted with a prefix, so it's not the field.
+		return reflect.Value{}, false, nil
+	}
+
+	// Decode using a copy of r, since decoding may retain values,
+	// and ToJSON must not modify r.
+	rc := *r
+	rc.raw = nil
+	rc.order = nil
+
+	nv := reflect.New(v.Type()).Elem()
+	if err := rc.decodeField(t, t.name(), fieldJSON, nv); err != nil {
+		return reflect.Value{}, false, fmt.Errorf("retained field %q: %w", t.name(), err)
+	}
+	return nv, true, nil
+}
 
-	return json.Marshal(all)
+// encodeField returns the value to marshal for the known field v.
+func (r *Retain) encodeField(t jsonTag, v reflect.Value) (any, error) {
+	if d, ok := r.opts.discriminators[t.name()]; ok {
+		return d.encode(v)
+	}
+	if r.opts.unixTime != 0 && v.Type() == timeType {
+		return unixTimeJSON(v.Interface().(time.Time), r.opts.unixTime), nil
+	}
+	if t.quoted {
+		return encodeQuoted(v)
+	}
+	if fv, ok, err := encodeNested(v); ok {
+		return fv, err
+	}
+	return v.Interface(), nil
 }
 
 // MustRetainable panics if the passed in object is not Retainable.
@@ -82,64 +649,130 @@ func (r *Retain) ToJSON(obj any) ([]byte, error) {
 func MustRetainable(obj interface {
 	json.Marshaler
 	json.Unmarshaler
-}) any {
-	if err := Retainable(obj); err != nil {
+}, opts ...Option) any {
+	if err := Retainable(obj, opts...); err != nil {
 		panic(err)
 	}
 	return obj
 }
 
+// AssertRetainable panics if *T (or T, if it's a pointer type) is not
+// Retainable. See CheckRetainable.
+func AssertRetainable[T any](opts ...Option) {
+	if err := CheckRetainable[T](opts...); err != nil {
+		panic(err)
+	}
+}
+
+// CheckRetainable is similar to Retainable, but checks the type T without
+// requiring an instance, so types can be listed in a table-driven test, or
+// checked by generic code:
+//
+//	jsonobj.CheckRetainable[Page]()
+//
+// T should be the struct type, whose pointer type implements json.Marshaler
+// and json.Unmarshaler. A struct pointer type is also accepted.
+func CheckRetainable[T any](opts ...Option) error {
+	rt := reflect.TypeFor[T]()
+	if rt.Kind() != reflect.Pointer {
+		rt = reflect.PointerTo(rt)
+	}
+
+	obj, ok := reflect.New(rt.Elem()).Interface().(interface {
+		json.Marshaler
+		json.Unmarshaler
+	})
+	if !ok {
+		return fmt.Errorf("%v not Retainable: requires MarshalJSON and UnmarshalJSON methods", rt)
+	}
+	return Retainable(obj, opts...)
+}
+
 // Retainable checks that the provided type is supported for Retain marshalling
 // by checking that:
 //  * The type is a struct pointer (for `UnmarshalJSON` to work correctly).
-//  * The type has no duplicate JSON field names.
+//  * The type has no duplicate JSON field names, including the fields of
+//    `jsonobj:"prefix=..."` struct fields, and fields promoted from embedded
+//    structs that aren't shadowed by a shallower field.
 //  * The type has no unsupported json tags.
+//  * The type has at most one json.RawMessage `jsonobj:",rawinput"` field.
+//  * The type has exactly one Retain field (or field of a type embedding
+//    Retain, such as Extensions), including fields of embedded structs.
+//
+// opts should include any WithTagKey option used to decode the type.
 func Retainable(obj interface {
 	json.Marshaler
 	json.Unmarshaler
-}) (retErr error) {
+}, opts ...Option) (retErr error) {
 	defer func() {
 		if retErr != nil {
 			retErr = fmt.Errorf("%T not Retainable: %v", obj, retErr)
 		}
 	}()
 
+	if err := verifyRetainable(obj, options{}.with(opts).structTagKey()); err != nil {
+		return err
+	}
+
+	return verifyOneRetainField(reflect.TypeOf(obj).Elem())
+}
+
+// verifyRetainable runs the checks of Retainable, without requiring
+// obj to implement json.Marshaler and json.Unmarshaler.
+func verifyRetainable(obj any, tagKey string) error {
+	if isNilObj(obj) {
+		return errors.New("requires a non-nil struct pointer")
+	}
 	rv, ok := ensureStruct(obj, true /* requirePtr */)
 	if !ok {
 		return errors.New("requires struct pointer")
 	}
 
-	if err := verifyNoDuplicateFieldNames(rv); err != nil {
+	if err := verifyNoDuplicateFieldNames(rv, tagKey); err != nil {
+		return err
+	}
+
+	if err := verifyNoUnsupportedTags(rv, tagKey); err != nil {
 		return err
 	}
 
-	if err := verifyNoUnsupportedTags(rv); err != nil {
+	if _, err := rawInputField(rv); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func verifyNoDuplicateFieldNames(rv reflect.Value) error {
+func verifyNoDuplicateFieldNames(rv reflect.Value, tagKey string) error {
+	names, err := prefixedFieldNames(rv, tagKey)
+	if err != nil {
+		return err
+	}
+
 	exists := make(map[string]struct{})
-	return forJSONField(rv, func(t jsonTag, v reflect.Value) error {
-		name := t.name()
+	for _, name := range names {
 		if _, ok := exists[name]; ok {
 			return fmt.Errorf("duplicate JSON field %q", name)
 		}
 		exists[name] = struct{}{}
-		return nil
-	})
+	}
+	return nil
 }
 
-func verifyNoUnsupportedTags(rv reflect.Value) error {
-	return forJSONField(rv, func(jt jsonTag, v reflect.Value) error {
+func verifyNoUnsupportedTags(rv reflect.Value, tagKey string) error {
+	return forAllJSONFields(rv, tagKey, func(jt jsonTag, v reflect.Value) error {
 		if len(jt.tag) <= 1 {
 			return nil
 		}
 
 		for _, t := range jt.tag[1:] {
-			if t != "" && t != "omitempty" {
+			if t == stringTagOption {
+				if !canQuote(jt.field.Type) {
+					return fmt.Errorf("field %q has tag %q, which requires a bool, number or string type, got %v", jt.name(), t, jt.field.Type)
+				}
+				continue
+			}
+			if t != "" && t != "omitempty" && t != omitZeroTagOption {
 				return fmt.Errorf("field %q has unsupported tag %q", jt.name(), t)
 			}
 		}
@@ -148,6 +781,13 @@ func verifyNoUnsupportedTags(rv reflect.Value) error {
 	})
 }
 
+// isNilObj returns whether obj is nil or a nil pointer, which can't be
+// decoded into or encoded.
+func isNilObj(obj any) bool {
+	rv := reflect.ValueOf(obj)
+	return !rv.IsValid() || (rv.Kind() == reflect.Pointer && rv.IsNil())
+}
+
 func ensureStruct(obj any, requirePtr bool) (reflect.Value, bool) {
 	rv := reflect.ValueOf(obj)
 	if rv.Kind() == reflect.Pointer {
@@ -158,52 +798,218 @@ func ensureStruct(obj any, requirePtr bool) (reflect.Value, bool) {
 	return rv, rv.Kind() == reflect.Struct
 }
 
-func forJSONField[R comparable](rv reflect.Value, fn func(t jsonTag, v reflect.Value) R) R {
+// forJSONField calls fn for each JSON field of the struct rv, stopping at the
+// first non-zero return value. Fields with conflicting JSON names are resolved
+// as encoding/json does: a tagged field is preferred over untagged fields, and
+// otherwise all of the conflicting fields are ignored.
+//
+// Field names and options are read from the struct tag tagKey.
+func forJSONField[R comparable](rv reflect.Value, tagKey string, fn func(t jsonTag, v reflect.Value) R) R {
+	return forFields(rv, cachedFields(rv.Type(), tagKey).dominant, fn)
+}
+
+// forAllJSONFields is similar to forJSONField, but includes conflicting fields
+// at the same depth.
+func forAllJSONFields[R comparable](rv reflect.Value, tagKey string, fn func(t jsonTag, v reflect.Value) R) R {
+	return forFields(rv, cachedFields(rv.Type(), tagKey).all, fn)
+}
+
+func forFields[R comparable](rv reflect.Value, fields []jsonTag, fn func(t jsonTag, v reflect.Value) R) R {
 	var zeroRet R
-	rt := rv.Type()
+	for _, jt := range fields {
+		v, ok := fieldByIndex(rv, jt)
+		if !ok {
+			// Fields of a nil embedded pointer have the zero value.
+			v = reflect.Zero(jt.field.Type)
+		}
+		if ret := fn(jt, v); ret != zeroRet {
+			return ret
+		}
+	}
+	return zeroRet
+}
 
+// jsonFields returns the JSON fields of the struct type rt, including fields
+// promoted from embedded structs. As with encoding/json, a promoted field is
+// shadowed by fields with the same name at a shallower depth.
+func jsonFields(rt reflect.Type, tagKey string) []jsonTag {
+	return visibleFields(appendJSONFields(nil, tagKey, rt, nil, false, nil))
+}
+
+// appendJSONFields appends the JSON fields of the struct type rt, which is
+// embedded at index (nil for the top-level struct), to fields.
+// visiting contains the embedded struct types being walked, to avoid cycles.
+func appendJSONFields(fields []jsonTag, tagKey string, rt reflect.Type, index []int, viaPointer bool, visiting map[reflect.Type]struct{}) []jsonTag {
 	for f := 0; f < rt.NumField(); f++ {
 		ft := rt.Field(f)
+		ft.Index = append(slices.Clip(index), f)
+
+		tagValue := ft.Tag.Get(tagKey)
+		if tagValue == "-" {
+			// json package ignores tag with "-"
+			continue
+		}
+
+		tag := strings.Split(tagValue, ",")
+		if ft.Anonymous {
+			et := ft.Type
+			if et.Kind() == reflect.Pointer {
+				et = et.Elem()
+			}
+			if et.Kind() == reflect.Struct && !isValidTagName(tag[0]) {
+				// Promote the fields of untagged embedded structs, even if
+				// the struct type is unexported.
+				if _, ok := visiting[et]; ok {
+					continue
+				}
+				if visiting == nil {
+					visiting = make(map[reflect.Type]struct{})
+				}
+				visiting[et] = struct{}{}
+				fields = appendJSONFields(fields, tagKey, et, ft.Index, viaPointer || ft.Type.Kind() == reflect.Pointer, visiting)
+				delete(visiting, et)
+				continue
+			}
+		}
 		if !ft.IsExported() {
 			continue
 		}
 
-		tagValue := ft.Tag.Get("json")
-		if tagValue == "-" {
-			// json package ignores tag with "-"
+		if hasDirective(ft, rawInputDirective) {
+			// rawinput fields hold the input, and are not JSON fields.
 			continue
 		}
 
 		jt := jsonTag{
-			tag:   strings.Split(tagValue, ","),
-			field: ft,
+			tag:        tag,
+			field:      ft,
+			omitempty:  slices.Contains(tag[1:], "omitempty"),
+			omitzero:   slices.Contains(tag[1:], omitZeroTagOption),
+			quoted:     slices.Contains(tag[1:], stringTagOption) && canQuote(ft.Type),
+			noEmit:     hasDirective(ft, noEmitDirective),
+			noRead:     hasDirective(ft, noReadDirective),
+			aliases:    fieldAliases(ft),
+			viaPointer: viaPointer,
 		}
+		jt.jsonName = ft.Name
+		if jt.tagged() {
+			jt.jsonName = tag[0]
+		}
+		fields = append(fields, jt)
+	}
+	return fields
+}
 
-		if ret := fn(jt, rv.Field(f)); ret != zeroRet {
-			return ret
+// visibleFields removes fields that are shadowed by a field with the same
+// name at a shallower depth.
+func visibleFields(fields []jsonTag) []jsonTag {
+	minDepth := make(map[string]int, len(fields))
+	for _, jt := range fields {
+		if d, ok := minDepth[jt.name()]; !ok || jt.depth() < d {
+			minDepth[jt.name()] = jt.depth()
 		}
 	}
 
-	return zeroRet
+	return slices.DeleteFunc(fields, func(jt jsonTag) bool {
+		return jt.depth() > minDepth[jt.name()]
+	})
+}
+
+// dominantFields removes fields with conflicting names, keeping the tagged
+// field if exactly one of the conflicting fields is tagged.
+func dominantFields(fields []jsonTag) []jsonTag {
+	byName := make(map[string][]jsonTag, len(fields))
+	for _, jt := range fields {
+		byName[jt.name()] = append(byName[jt.name()], jt)
+	}
+
+	dominant := make([]jsonTag, 0, len(fields))
+	for _, jt := range fields {
+		conflicts := byName[jt.name()]
+		if len(conflicts) == 1 {
+			dominant = append(dominant, jt)
+			continue
+		}
+
+		var tagged []jsonTag
+		for _, c := range conflicts {
+			if c.tagged() {
+				tagged = append(tagged, c)
+			}
+		}
+		if len(tagged) == 1 && slices.Equal(tagged[0].field.Index, jt.field.Index) {
+			dominant = append(dominant, jt)
+		}
+	}
+	return dominant
 }
 
 type jsonTag struct {
 	tag   []string
 	field reflect.StructField
+
+	// jsonName and the tag options are resolved from the tag by jsonFields.
+	jsonName  string
+	omitempty bool
+	omitzero  bool
+	quoted    bool
+
+	// noEmit and noRead are set by the noemit and noread directives.
+	noEmit bool
+	noRead bool
+
+	// aliases are the former names of the field, see aliasesTagKey.
+	aliases []string
+
+	// viaPointer is set for promoted fields of an embedded struct pointer.
+	viaPointer bool
+}
+
+// depth returns the embedding depth of the field, which is 1 for fields
+// that are not promoted.
+func (t jsonTag) depth() int {
+	return len(t.field.Index)
+}
+
+// tagged returns whether the field's name comes from its tag.
+func (t jsonTag) tagged() bool {
+	return isValidTagName(t.tag[0])
 }
 
 func (t jsonTag) name() string {
-	if name := t.tag[0]; name != "" {
-		return name
-	}
-	return t.field.Name
+	return t.jsonName
 }
 
-func (t jsonTag) omitEmpty() bool {
-	if len(t.tag) < 2 {
+// isValidTagName matches the encoding/json rules for tag names,
+// which fall back to the field name if the tag name is invalid.
+func isValidTagName(s string) bool {
+	if s == "" {
 		return false
 	}
-	return t.tag[1] == "omitempty"
+	for _, c := range s {
+		switch {
+		case strings.ContainsRune("!#$%&()*+-./:;<=>?@[]^_{|}~ ", c):
+			// Backslash and quote chars are reserved, but
+			// otherwise any punctuation chars are allowed.
+		case !unicode.IsLetter(c) && !unicode.IsDigit(c):
+			return false
+		}
+	}
+	return true
+}
+
+// hasDirective returns whether the field's `jsonobj` tag contains directive.
+func hasDirective(ft reflect.StructField, directive string) bool {
+	for _, d := range strings.Split(ft.Tag.Get("jsonobj"), ",") {
+		if d == directive {
+			return true
+		}
+	}
+	return false
+}
+
+func (t jsonTag) omitEmpty() bool {
+	return t.omitempty
 }
 
 func isZero(v reflect.Value) bool {
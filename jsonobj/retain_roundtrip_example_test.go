@@ -40,8 +40,30 @@ func Example_retainRoundtrip() {
 	}
 
 	// Note that output includes the updated "slug"
-	// and retains the unknown "icon" field.
+	// and retains the unknown "icon" field, in the input order.
 	fmt.Println(string(marshalled))
 	// Output:
-	// {"icon":"email","slug":"contact-us","title":"Contact Us"}
+	// {"title":"Contact Us","slug":"contact-us","icon":"email"}
+}
+
+func Example_valueContainers() {
+	// Page has a value receiver MarshalJSON, so unknown fields are retained
+	// even when pages are stored by value in a map.
+	if errs := jsonobj.LintValueTypes(&Page{}); len(errs) > 0 {
+		log.Fatalf("LintValueTypes failed: %v", errs)
+	}
+
+	var pages map[string]Page
+	input := `{"home":{"title":"Home","slug":"","icon":"house"}}`
+	if err := json.Unmarshal([]byte(input), &pages); err != nil {
+		log.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	marshalled, err := json.Marshal(pages)
+	if err != nil {
+		log.Fatalf("Marshal failed: %v", err)
+	}
+	fmt.Println(string(marshalled))
+	// Output:
+	// {"home":{"title":"Home","slug":"","icon":"house"}}
 }
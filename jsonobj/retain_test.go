@@ -3,6 +3,8 @@ package jsonobj
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -198,6 +200,16 @@ func TestRetain_FromJSON_Types(t *testing.T) {
 			obj:     s,
 			wantErr: "FromJSON requires a struct pointer, got jsonobj.S",
 		},
+		{
+			name:    "nil struct pointer",
+			obj:     (*S)(nil),
+			wantErr: "FromJSON requires a non-nil struct pointer",
+		},
+		{
+			name:    "nil",
+			obj:     nil,
+			wantErr: "FromJSON requires a non-nil struct pointer",
+		},
 		{
 			name: "struct pointer",
 			obj:  &s,
@@ -234,6 +246,16 @@ func TestRetain_ToJSON_Types(t *testing.T) {
 			obj:     ptr("str"),
 			wantErr: "ToJSON requires a struct, got *string",
 		},
+		{
+			name:    "nil struct pointer",
+			obj:     (*S)(nil),
+			wantErr: "ToJSON requires a non-nil struct",
+		},
+		{
+			name:    "nil",
+			obj:     nil,
+			wantErr: "ToJSON requires a non-nil struct",
+		},
 	}
 
 	for _, tt := range tests {
@@ -256,6 +278,8 @@ func TestRetainable(t *testing.T) {
 	type base struct {
 		json.Marshaler
 		json.Unmarshaler
+
+		raw Retain
 	}
 
 	type Valid struct {
@@ -281,20 +305,64 @@ func TestRetainable(t *testing.T) {
 		Name2 string `json:"name"`
 	}
 
-	type UnsupportedStringTag struct {
+	type StringTag struct {
 		base
 		Age int `json:",string"`
 	}
 
+	type UnsupportedStringTag struct {
+		base
+		Tags []string `json:"tags,string"`
+	}
+
 	type InlineStruct struct {
 		Name string
 	}
 
-	type UnsupportedInlineTag struct {
+	type InlineTag struct {
 		base
 		InlineStruct `json:",inline"`
 	}
 
+	type DuplicatePromoted struct {
+		base
+		InlineStruct
+		Other struct {
+			Name string
+		}
+		OtherName string `json:"Name"`
+	}
+
+	type Dup1 struct {
+		Name string
+	}
+	type Dup2 struct {
+		Name string
+	}
+	type DuplicatePromotedSameDepth struct {
+		base
+		Dup1
+		Dup2
+	}
+
+	type NoRetain struct {
+		json.Marshaler
+		json.Unmarshaler
+
+		Name string
+	}
+
+	type MultipleRetain struct {
+		base
+
+		ext Extensions[struct{}]
+	}
+
+	type Composed struct {
+		base
+		S
+	}
+
 	tests := []struct {
 		v interface {
 			json.Marshaler
@@ -309,6 +377,14 @@ func TestRetainable(t *testing.T) {
 			v:       Valid{},
 			wantErr: `jsonobj.Valid not Retainable: requires struct pointer`,
 		},
+		{
+			v:       (*Valid)(nil),
+			wantErr: `*jsonobj.Valid not Retainable: requires a non-nil struct pointer`,
+		},
+		{
+			v:       nil,
+			wantErr: `<nil> not Retainable: requires a non-nil struct pointer`,
+		},
 		{
 			v:       &DuplicateNameWithTag{},
 			wantErr: `*jsonobj.DuplicateNameWithTag not Retainable: duplicate JSON field "Name"`,
@@ -317,13 +393,36 @@ func TestRetainable(t *testing.T) {
 			v:       &DuplicateNameTags{},
 			wantErr: `*jsonobj.DuplicateNameTags not Retainable: duplicate JSON field "name"`,
 		},
+		{
+			v: &StringTag{},
+		},
 		{
 			v:       &UnsupportedStringTag{},
-			wantErr: `*jsonobj.UnsupportedStringTag not Retainable: field "Age" has unsupported tag "string"`,
+			wantErr: `*jsonobj.UnsupportedStringTag not Retainable: field "tags" has tag "string", which requires a bool, number or string type, got []string`,
+		},
+		{
+			// Untagged embedded structs are promoted, as with encoding/json.
+			v: &InlineTag{},
+		},
+		{
+			// Promoted fields are shadowed by shallower fields.
+			v: &DuplicatePromoted{},
+		},
+		{
+			v:       &DuplicatePromotedSameDepth{},
+			wantErr: `*jsonobj.DuplicatePromotedSameDepth not Retainable: duplicate JSON field "Name"`,
 		},
 		{
-			v:       &UnsupportedInlineTag{},
-			wantErr: `*jsonobj.UnsupportedInlineTag not Retainable: field "InlineStruct" has unsupported tag "inline"`,
+			v:       &NoRetain{},
+			wantErr: `*jsonobj.NoRetain not Retainable: no Retain field, unknown fields are not retained`,
+		},
+		{
+			v:       &MultipleRetain{},
+			wantErr: `*jsonobj.MultipleRetain not Retainable: found 2 Retain fields (base.raw, ext), expected exactly one`,
+		},
+		{
+			v:       &Composed{},
+			wantErr: `*jsonobj.Composed not Retainable: found 2 Retain fields (base.raw, S.raw), expected exactly one`,
 		},
 	}
 
@@ -346,6 +445,65 @@ func TestRetainable(t *testing.T) {
 	}
 }
 
+func TestCheckRetainable(t *testing.T) {
+	type noMethods struct {
+		raw Retain
+	}
+
+	tests := []struct {
+		name    string
+		check   func(...Option) error
+		opts    []Option
+		wantErr string
+	}{
+		{
+			name:  "struct type",
+			check: CheckRetainable[S],
+		},
+		{
+			name:  "pointer type",
+			check: CheckRetainable[*S],
+		},
+		{
+			name:    "not Retainable",
+			check:   CheckRetainable[lintDuplicate],
+			wantErr: `*jsonobj.lintDuplicate not Retainable: duplicate JSON field "A"`,
+		},
+		{
+			name:    "no methods",
+			check:   CheckRetainable[noMethods],
+			wantErr: `*jsonobj.noMethods not Retainable: requires MarshalJSON and UnmarshalJSON methods`,
+		},
+		{
+			name:    "not a struct",
+			check:   CheckRetainable[int],
+			wantErr: `*int not Retainable: requires MarshalJSON and UnmarshalJSON methods`,
+		},
+		{
+			name:    "options",
+			check:   CheckRetainable[unsupportedAPITagS],
+			opts:    []Option{WithTagKey("api")},
+			wantErr: `*jsonobj.unsupportedAPITagS not Retainable: field "name" has unsupported tag "inline"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.check(tt.opts...)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				assert.NotPanics(t, func() { AssertRetainable[S]() })
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+
+	assert.PanicsWithError(t, `*jsonobj.lintDuplicate not Retainable: duplicate JSON field "A"`, func() {
+		AssertRetainable[lintDuplicate]()
+	})
+}
+
 func TestRetain_FromJSON_Errors(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -365,7 +523,12 @@ func TestRetain_FromJSON_Errors(t *testing.T) {
 		{
 			name:    "wrong field type",
 			json:    `{"name": 1}`,
-			wantErr: "cannot unmarshal number into",
+			wantErr: `field "name" (Name): json: cannot unmarshal number into`,
+		},
+		{
+			name:    "wrong field type with case-insensitive key",
+			json:    `{"NAME": [true]}`,
+			wantErr: `field "NAME" (Name): json: cannot unmarshal array into`,
 		},
 	}
 
@@ -394,3 +557,381 @@ func checkToJSON(t *testing.T, name string, obj any) {
 		assert.JSONEq(t, want, string(got))
 	})
 }
+
+func TestRetain_SpecialFieldNames(t *testing.T) {
+	type Special struct {
+		raw Retain
+
+		Dotted  string `json:"user.name,omitempty"`
+		Slash   string `json:"a/b,omitempty"`
+		Space   string `json:"with space,omitempty"`
+		Unicode string `json:"名前,omitempty"`
+		Quote   string `json:"a\"b,omitempty"`
+		Escape  string `json:"a\\b,omitempty"`
+	}
+
+	input := `{
+		"user.name": "dotted",
+		"a/b": "slash",
+		"with space": "space",
+		"名前": "unicode",
+		"Quote": "quote",
+		"Escape": "escape",
+		"user": {"name": "nested"},
+		"a\"b": "retained quote"
+	}`
+
+	var s Special
+	require.NoError(t, s.raw.FromJSON([]byte(input), &s))
+	assert.Equal(t, "dotted", s.Dotted)
+	assert.Equal(t, "slash", s.Slash)
+	assert.Equal(t, "space", s.Space)
+	assert.Equal(t, "unicode", s.Unicode)
+
+	// Invalid tag names are ignored in favour of the field name,
+	// as documented by encoding/json.
+	assert.Equal(t, "quote", s.Quote)
+	assert.Equal(t, "escape", s.Escape)
+
+	got, err := s.raw.ToJSON(s)
+	require.NoError(t, err)
+	assert.JSONEq(t, input, string(got))
+
+	// Verify the valid names match encoding/json.
+	type Valid struct {
+		Dotted  string `json:"user.name"`
+		Slash   string `json:"a/b"`
+		Space   string `json:"with space"`
+		Unicode string `json:"名前"`
+	}
+	v := Valid{s.Dotted, s.Slash, s.Space, s.Unicode}
+	checkToJSON(t, "valid names", v)
+}
+
+func TestRetain_CaseInsensitiveFields(t *testing.T) {
+	type Obj struct {
+		Title string `json:"title"`
+		Upper string `json:"UPPER"`
+		Lower string `json:"upper"`
+	}
+
+	tests := []struct {
+		name       string
+		json       string
+		want       Obj
+		matchesStd bool
+	}{
+		{
+			name:       "inexact match",
+			json:       `{"Title": "x", "other": 1}`,
+			want:       Obj{Title: "x"},
+			matchesStd: true,
+		},
+		{
+			name: "exact match preferred",
+			json: `{"Title": "inexact", "title": "exact", "TITLE": "inexact"}`,
+			want: Obj{Title: "exact"},
+		},
+		{
+			name:       "last inexact match",
+			json:       `{"TITLE": "first", "Title": "last"}`,
+			want:       Obj{Title: "last"},
+			matchesStd: true,
+		},
+		{
+			name:       "exact name of another field",
+			json:       `{"UPPER": "upper", "upper": "lower"}`,
+			want:       Obj{Upper: "upper", Lower: "lower"},
+			matchesStd: true,
+		},
+		{
+			name:       "first field matches",
+			json:       `{"Upper": "u"}`,
+			want:       Obj{Upper: "u"},
+			matchesStd: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				r   Retain
+				got Obj
+			)
+			require.NoError(t, r.FromJSON([]byte(tt.json), &got))
+			assert.Equal(t, tt.want, got)
+
+			_, ok := r.GetUnknown("other")
+			assert.Equal(t, strings.Contains(tt.json, "other"), ok)
+			assert.LessOrEqual(t, len(r.UnknownKeys()), 1, "matched keys should not be retained")
+
+			if tt.matchesStd {
+				var std Obj
+				require.NoError(t, json.Unmarshal([]byte(tt.json), &std))
+				assert.Equal(t, std, got, "should match encoding/json")
+			}
+		})
+	}
+}
+
+func TestFoldKey(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{"title", "TITLE"},
+		{"title", "Title"},
+		{"key", "\u212Aey"},    // Kelvin sign
+		{"ss", "\u017F\u017F"}, // long s
+		{"straße", "STRASSE"},
+		{"Σίσυφος", "ΣΊΣΥΦΟΣ"},
+		{"a", "b"},
+		{"title", "titles"},
+		{"\xff", "\xfe"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			assert.Equal(t, strings.EqualFold(tt.a, tt.b), foldKey(tt.a) == foldKey(tt.b))
+		})
+	}
+}
+
+func BenchmarkRetain_ManyFields(b *testing.B) {
+	const numFields = 300
+
+	fields := make([]reflect.StructField, numFields)
+	var sb strings.Builder
+	sb.WriteString("{")
+	for i := range fields {
+		fields[i] = reflect.StructField{
+			Name: fmt.Sprintf("F%d", i),
+			Type: reflect.TypeOf(0),
+			Tag:  reflect.StructTag(fmt.Sprintf(`json:"field%d"`, i)),
+		}
+		// Each field has an exact and case-insensitive match.
+		fmt.Fprintf(&sb, `"field%d": %d, "FIELD%d": %d, `, i, i, i, i)
+	}
+	sb.WriteString(`"unknown": true}`)
+	input := []byte(sb.String())
+	rt := reflect.StructOf(fields)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var r Retain
+		if err := r.FromJSON(input, reflect.New(rt).Interface()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestRetain_UntaggedFieldNames(t *testing.T) {
+	type Untagged struct {
+		Name    string
+		UserID  int
+		Options string `json:",omitempty"`
+	}
+
+	t.Run("exact Go field name", func(t *testing.T) {
+		var (
+			r Retain
+			u Untagged
+		)
+		input := `{"Name": "foo", "UserID": 1, "Options": "opts", "name": "lower"}`
+		require.NoError(t, r.FromJSON([]byte(input), &u))
+		assert.Equal(t, Untagged{Name: "foo", UserID: 1, Options: "opts"}, u)
+
+		_, ok := r.GetUnknown("name")
+		assert.False(t, ok, "differently cased key should match the field")
+
+		got, err := r.ToJSON(u)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"Name": "foo", "UserID": 1, "Options": "opts"}`, string(got))
+		checkToJSON(t, "matches encoding/json", u)
+	})
+
+	t.Run("tagged field dominates", func(t *testing.T) {
+		type Conflict struct {
+			Name  string
+			Other string `json:"Name"`
+			Lower string `json:"name"`
+		}
+
+		c := Conflict{Name: "untagged", Other: "tagged", Lower: "lower"}
+		checkToJSON(t, "ToJSON", c)
+
+		input := `{"Name": "tagged", "name": "lower"}`
+
+		var (
+			r   Retain
+			got Conflict
+		)
+		require.NoError(t, r.FromJSON([]byte(input), &got))
+
+		var want Conflict
+		require.NoError(t, json.Unmarshal([]byte(input), &want))
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("conflicting tagged fields are ignored", func(t *testing.T) {
+		// The type is built using reflect, since go vet reports duplicate tags.
+		conflictType := reflect.StructOf([]reflect.StructField{
+			{Name: "A", Type: reflect.TypeOf(""), Tag: `json:"x"`},
+			{Name: "B", Type: reflect.TypeOf(""), Tag: `json:"x"`},
+			{Name: "C", Type: reflect.TypeOf("")},
+		})
+		newConflict := func(a, b, c string) reflect.Value {
+			v := reflect.New(conflictType)
+			v.Elem().Field(0).SetString(a)
+			v.Elem().Field(1).SetString(b)
+			v.Elem().Field(2).SetString(c)
+			return v
+		}
+
+		checkToJSON(t, "ToJSON", newConflict("a", "b", "c").Elem().Interface())
+
+		var r Retain
+		got := reflect.New(conflictType)
+		require.NoError(t, r.FromJSON([]byte(`{"x": "x", "C": "c"}`), got.Interface()))
+		assert.Equal(t, newConflict("", "", "c").Interface(), got.Interface())
+
+		x, ok := r.GetUnknown("x")
+		assert.True(t, ok, "ambiguous field should be retained")
+		assert.Equal(t, `"x"`, string(x))
+	})
+}
+
+// Retained values are never decoded, so scalars keep their original literal
+// form, which matters for byte-sensitive consumers.
+func TestRetain_RetainedLiterals(t *testing.T) {
+	literals := []string{
+		`1e3`,
+		`1E+3`,
+		`1.0`,
+		`-0`,
+		`0.10`,
+		`12345678901234567890`,
+		`true`,
+		`false`,
+		`null`,
+		`"é"`,
+	}
+
+	for _, lit := range literals {
+		t.Run(lit, func(t *testing.T) {
+			var s S
+			input := `{"name":"foo","v":` + lit + `}`
+			require.NoError(t, json.Unmarshal([]byte(input), &s))
+
+			v, ok := s.raw.GetUnknown("v")
+			require.True(t, ok, "missing retained field")
+			assert.Equal(t, lit, string(v))
+			assert.Equal(t, input, mustMarshal(t, &s))
+		})
+	}
+}
+
+func TestRetain_RetainedByteExact(t *testing.T) {
+	const input = `{"name":"foo","obj":{ "a" : [1,  2],"b":"\u00e9\/<>" },"s":"a\tb\u2028"}`
+
+	var s S
+	require.NoError(t, json.Unmarshal([]byte(input), &s))
+
+	got, err := s.raw.ToJSON(&s)
+	require.NoError(t, err)
+	assert.Equal(t, input, string(got))
+
+	s.raw.SetUnknown("invalid", json.RawMessage(`{"a":`))
+	_, err = s.raw.ToJSON(&s)
+	assert.EqualError(t, err, `key "invalid": invalid JSON value "{\"a\":"`)
+}
+
+type textKey struct {
+	a, b string
+}
+
+func (k textKey) MarshalText() ([]byte, error) {
+	return []byte(k.a + ":" + k.b), nil
+}
+
+func (k *textKey) UnmarshalText(text []byte) error {
+	a, b, ok := strings.Cut(string(text), ":")
+	if !ok {
+		return fmt.Errorf("invalid key %q", text)
+	}
+	*k = textKey{a, b}
+	return nil
+}
+
+func TestRetain_MapKeys(t *testing.T) {
+	type Maps struct {
+		Ints  map[int]string     `json:"ints"`
+		Uints map[uint8]bool     `json:"uints,omitempty"`
+		Text  map[textKey]int    `json:"text"`
+		Empty map[int]string     `json:"empty"`
+		Nil   map[textKey]string `json:"nil"`
+	}
+
+	input := `{"ints": {"-1": "a", "10": "b"}, "uints": {"255": true}, "text": {"x:y": 1}, "empty": {}, "nil": null, "other": 1}`
+	want := Maps{
+		Ints:  map[int]string{-1: "a", 10: "b"},
+		Uints: map[uint8]bool{255: true},
+		Text:  map[textKey]int{{"x", "y"}: 1},
+		Empty: map[int]string{},
+	}
+
+	var (
+		r   Retain
+		got Maps
+	)
+	require.NoError(t, r.FromJSON([]byte(input), &got))
+	assert.Equal(t, want, got)
+
+	out, err := r.ToJSON(got)
+	require.NoError(t, err)
+	assert.JSONEq(t, input, string(out))
+	checkToJSON(t, "matches encoding/json", got)
+
+	require.Error(t, r.FromJSON([]byte(`{"ints": {"x": "a"}}`), &got), "invalid int key")
+	require.Error(t, r.FromJSON([]byte(`{"text": {"x": 1}}`), &got), "invalid text key")
+}
+
+type defaultedS struct {
+	raw Retain
+
+	Name    string `json:"name"`
+	Retries int    `json:"retries"`
+	Tags    []string
+}
+
+func (s *defaultedS) UnmarshalJSON(data []byte) error {
+	if s.Retries == 0 {
+		s.Retries = 3
+	}
+	if err := s.raw.FromJSON(data, s); err != nil {
+		return err
+	}
+	s.Name = strings.TrimSpace(s.Name)
+	return nil
+}
+
+func (s defaultedS) MarshalJSON() ([]byte, error) {
+	return s.raw.ToJSON(s)
+}
+
+func TestRetain_FromJSON_Prepopulated(t *testing.T) {
+	s := defaultedS{Name: "preset", Tags: []string{"t"}}
+	require.NoError(t, json.Unmarshal([]byte(`{"retries": 5, "a": 1}`), &s))
+	assert.Equal(t, "preset", s.Name, "absent fields should be preserved")
+	assert.Equal(t, 5, s.Retries)
+	assert.Equal(t, []string{"t"}, s.Tags)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"name": " foo ", "b": 2}`), &s))
+	assert.Equal(t, "foo", s.Name)
+	assert.Equal(t, 5, s.Retries, "absent fields should be preserved")
+	assert.Equal(t, `{"name":"foo","b":2,"retries":5,"Tags":["t"]}`, mustMarshal(t, s),
+		"retained fields should be replaced by later decodes")
+
+	var fresh defaultedS
+	require.NoError(t, json.Unmarshal([]byte(`{}`), &fresh))
+	assert.Equal(t, 3, fresh.Retries, "pre-decode defaults should apply")
+}
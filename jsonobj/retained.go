@@ -0,0 +1,77 @@
+package jsonobj
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// Retained wraps a struct type T, retaining unknown fields without T needing
+// a Retain field or its own UnmarshalJSON and MarshalJSON methods:
+//
+//	var page jsonobj.Retained[Page]
+//	err := json.Unmarshal(data, &page)
+//	page.Value().Title = "updated"
+//	data, err = json.Marshal(&page)
+//
+// T is checked using the same checks as Retainable when Retained[T] is first
+// marshalled or unmarshalled, and any failure is returned as an error.
+type Retained[T any] struct {
+	value T
+	raw   Retain
+}
+
+// retainedChecks caches the result of verifying the types used by Retained.
+var retainedChecks sync.Map // map[reflect.Type]retainedCheck
+
+// NewRetained returns a Retained[T] wrapping v, with no retained fields.
+func NewRetained[T any](v T) Retained[T] {
+	return Retained[T]{value: v}
+}
+
+// Value returns a pointer to the wrapped value, which can be used to read
+// or modify the known fields.
+func (r *Retained[T]) Value() *T {
+	return &r.value
+}
+
+// Retain returns the Retain used for the wrapped value, for access to the
+// retained fields and options.
+func (r *Retained[T]) Retain() *Retain {
+	return &r.raw
+}
+
+// UnmarshalJSON implements json.Unmarshaler using Retain.FromJSON.
+func (r *Retained[T]) UnmarshalJSON(data []byte) error {
+	if err := verifyRetained[T](); err != nil {
+		return err
+	}
+	return r.raw.FromJSON(data, &r.value)
+}
+
+// MarshalJSON implements json.Marshaler using Retain.ToJSON.
+func (r Retained[T]) MarshalJSON() ([]byte, error) {
+	if err := verifyRetained[T](); err != nil {
+		return nil, err
+	}
+	return r.raw.ToJSON(&r.value)
+}
+
+func verifyRetained[T any]() error {
+	rt := reflect.TypeOf((*T)(nil))
+	if err, ok := retainedChecks.Load(rt); ok {
+		return err.(retainedCheck).err
+	}
+
+	var check retainedCheck
+	if err := verifyRetainable(reflect.New(rt.Elem()).Interface(), defaultTagKey); err != nil {
+		check.err = fmt.Errorf("Retained[%v] requires a Retainable type: %v", rt.Elem(), err)
+	}
+	retainedChecks.Store(rt, check)
+	return check.err
+}
+
+// retainedCheck is the result of verifying a type used by Retained.
+type retainedCheck struct {
+	err error
+}
@@ -0,0 +1,98 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type page struct {
+	Title string `json:"title"`
+	Views int    `json:"views,omitempty"`
+}
+
+func TestRetained(t *testing.T) {
+	var p Retained[page]
+	require.NoError(t, json.Unmarshal([]byte(`{"title": "home", "author": "a", "tags": ["x"]}`), &p))
+	assert.Equal(t, page{Title: "home"}, *p.Value())
+	assert.Equal(t, []string{"author", "tags"}, p.Retain().UnknownKeys())
+
+	p.Value().Views = 3
+	assert.Equal(t, `{"title":"home","author":"a","tags":["x"],"views":3}`, mustMarshal(t, &p))
+	assert.Equal(t, `{"title":"home","author":"a","tags":["x"],"views":3}`, mustMarshal(t, p), "marshal by value")
+}
+
+func TestRetained_Nested(t *testing.T) {
+	type site struct {
+		Pages []Retained[page] `json:"pages"`
+	}
+
+	input := `{"pages":[{"title":"a","x":1},{"title":"b","y":2}]}`
+	var s site
+	require.NoError(t, json.Unmarshal([]byte(input), &s))
+	require.Len(t, s.Pages, 2)
+	assert.Equal(t, "b", s.Pages[1].Value().Title)
+	assert.Equal(t, input, mustMarshal(t, s))
+}
+
+func TestNewRetained(t *testing.T) {
+	p := NewRetained(page{Title: "new"})
+	assert.Equal(t, `{"title":"new"}`, mustMarshal(t, p))
+}
+
+func TestRetained_NotRetainable(t *testing.T) {
+	type dupA struct {
+		A string `json:"name"`
+	}
+	type dupB struct {
+		B string `json:"name"`
+	}
+
+	// The fields are embedded using pointers, since go vet reports
+	// duplicate tags in embedded structs.
+	type dup struct {
+		*dupA
+		*dupB
+	}
+
+	tests := []struct {
+		name    string
+		run     func() error
+		wantErr string
+	}{
+		{
+			name: "unmarshal duplicate",
+			run: func() error {
+				var v Retained[dup]
+				return json.Unmarshal([]byte(`{}`), &v)
+			},
+			wantErr: `Retained[jsonobj.dup] requires a Retainable type: duplicate JSON field "name"`,
+		},
+		{
+			name: "marshal duplicate",
+			run: func() error {
+				_, err := json.Marshal(Retained[dup]{})
+				return err
+			},
+			wantErr: `Retained[jsonobj.dup] requires a Retainable type: duplicate JSON field "name"`,
+		},
+		{
+			name: "not a struct",
+			run: func() error {
+				var v Retained[int]
+				return json.Unmarshal([]byte(`{}`), &v)
+			},
+			wantErr: "Retained[int] requires a Retainable type: requires struct pointer",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.run()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// FromJSONMap is the map-based equivalent of FromJSON, for objects that don't
+// have a fixed struct schema. special maps the JSON names of the keys handled
+// specially to pointers that the corresponding values are decoded into, while
+// all other keys are retained.
+//
+// Objects decoded with FromJSONMap should be marshalled using ToJSONMap with
+// the same special map, rather than ToJSON.
+//
+// With a nil special map, the whole object is retained, so FromJSONMap and
+// ToJSONMap round-trip an ordered map: retained values can be accessed using
+// methods such as GetUnknown and SetUnknown, and are output in input order.
+func (r *Retain) FromJSONMap(data []byte, special map[string]any) error {
+	if err := verifySpecial(special); err != nil {
+		return err
+	}
+
+	if err := r.decodeObject(data, nil /* codec */); err != nil {
+		return err
+	}
+
+	for name, v := range special {
+		fieldJSON, ok := r.raw[name]
+		if !ok {
+			continue
+		}
+
+		delete(r.raw, name)
+		if err := json.Unmarshal(fieldJSON, v); err != nil {
+			return fmt.Errorf("special key %q: %w", name, err)
+		}
+	}
+
+	if len(r.raw) == 0 {
+		r.raw = nil
+	}
+
+	return nil
+}
+
+// ToJSONMap marshals the values in special along with any fields retained in
+// FromJSONMap. Nil values in special are omitted.
+//
+// Keys are output in the order of the input to FromJSONMap, followed by
+// retained fields added using SetUnknown, and then any other special keys,
+// sorted. Retained values are written as-is, as with ToJSON.
+func (r *Retain) ToJSONMap(special map[string]any) ([]byte, error) {
+	all := make(map[string]any, len(r.raw)+len(special))
+	for k, v := range r.raw {
+		all[k] = v
+	}
+
+	for name, v := range special {
+		if v == nil || isNilPtr(reflect.ValueOf(v)) {
+			continue
+		}
+		all[name] = v
+	}
+
+	// An empty struct has no known fields, so only the order of retained
+	// fields is used.
+	return r.marshalOrdered(r.orderedKeys(reflect.ValueOf(struct{}{}), all), all)
+}
+
+func verifySpecial(special map[string]any) error {
+	for name, v := range special {
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Pointer || rv.IsNil() {
+			return fmt.Errorf("special key %q requires a non-nil pointer, got %T", name, v)
+		}
+	}
+	return nil
+}
+
+func isNilPtr(rv reflect.Value) bool {
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
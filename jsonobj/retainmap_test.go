@@ -0,0 +1,110 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetain_FromJSONMap(t *testing.T) {
+	var (
+		r       Retain
+		id      int
+		labels  map[string]string
+		missing string
+	)
+	special := map[string]any{
+		"id":      &id,
+		"labels":  &labels,
+		"missing": &missing,
+	}
+
+	input := `{"id": 1, "labels": {"env": "prod"}, "name": "foo", "list": [1, 2]}`
+	require.NoError(t, r.FromJSONMap([]byte(input), special))
+	assert.Equal(t, 1, id)
+	assert.Equal(t, map[string]string{"env": "prod"}, labels)
+	assert.Empty(t, missing)
+
+	t.Run("round trip", func(t *testing.T) {
+		got, err := r.ToJSONMap(special)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"id": 1, "labels": {"env": "prod"}, "missing": "", "name": "foo", "list": [1, 2]}`, string(got))
+	})
+
+	t.Run("update special", func(t *testing.T) {
+		id = 2
+		got, err := r.ToJSONMap(map[string]any{
+			"id":     &id,
+			"labels": (*map[string]string)(nil),
+		})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"id": 2, "name": "foo", "list": [1, 2]}`, string(got))
+	})
+}
+
+func TestRetain_FromJSONMap_Ordered(t *testing.T) {
+	var r Retain
+	input := `{"z": 1, "id": 2, "a": {"y": 1, "b": 2}, "m": [3]}`
+	require.NoError(t, r.FromJSONMap([]byte(input), nil))
+
+	got, err := r.ToJSONMap(nil)
+	require.NoError(t, err)
+	assert.Equal(t, `{"z":1,"id":2,"a":{"y": 1, "b": 2},"m":[3]}`, string(got))
+
+	id := 3
+	r.SetUnknown("new", json.RawMessage(`true`))
+	r.SetUnknown("z", json.RawMessage(`0`))
+	assert.True(t, r.DeleteUnknown("m"))
+	got, err = r.ToJSONMap(map[string]any{"id": &id, "extra": &id})
+	require.NoError(t, err)
+	assert.Equal(t, `{"z":0,"id":3,"a":{"y": 1, "b": 2},"new":true,"extra":3}`, string(got))
+
+	require.NoError(t, r.FromJSONMap([]byte(`{"b": 1}`), nil))
+	got, err = r.ToJSONMap(nil)
+	require.NoError(t, err)
+	assert.Equal(t, `{"b":1}`, string(got), "FromJSONMap replaces retained fields")
+}
+
+func TestRetain_FromJSONMap_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		special map[string]any
+		wantErr string
+	}{
+		{
+			name:    "non-pointer",
+			json:    `{}`,
+			special: map[string]any{"id": 1},
+			wantErr: `special key "id" requires a non-nil pointer, got int`,
+		},
+		{
+			name:    "nil pointer",
+			json:    `{}`,
+			special: map[string]any{"id": (*int)(nil)},
+			wantErr: `special key "id" requires a non-nil pointer, got *int`,
+		},
+		{
+			name:    "invalid JSON",
+			json:    `{"id"}`,
+			special: map[string]any{},
+			wantErr: "invalid character",
+		},
+		{
+			name:    "wrong type",
+			json:    `{"id": "1"}`,
+			special: map[string]any{"id": new(int)},
+			wantErr: `special key "id": json: cannot unmarshal string`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r Retain
+			err := r.FromJSONMap([]byte(tt.json), tt.special)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package jsonobj
+
+// WithRetainOnly makes FromJSON retain only the unknown fields named in
+// allowed, dropping all other unknown fields. This avoids persisting
+// arbitrary data injected by clients, while still round-tripping a curated
+// set of forward-compatible fields. Known fields are unaffected.
+//
+// Dropped fields are not emitted by ToJSON. Calling WithRetainOnly with no
+// keys drops all unknown fields.
+func WithRetainOnly(allowed ...string) Option {
+	set := make(map[string]struct{}, len(allowed))
+	for _, k := range allowed {
+		set[k] = struct{}{}
+	}
+
+	return func(o *options) {
+		o.retainOnly = set
+	}
+}
+
+// filterUnknown drops retained fields that aren't allowed by WithRetainOnly.
+func (r *Retain) filterUnknown() {
+	if r.opts.retainOnly == nil {
+		return
+	}
+
+	for k := range r.raw {
+		if _, ok := r.opts.retainOnly[k]; !ok {
+			delete(r.raw, k)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package jsonobj
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRetainOnly(t *testing.T) {
+	const input = `{"name": "foo", "icon": "email", "injected": {"a": 1}, "color": "red"}`
+
+	tests := []struct {
+		name     string
+		allowed  []string
+		wantKeys []string
+		want     string
+	}{
+		{
+			name:     "allowlist",
+			allowed:  []string{"icon", "color", "missing"},
+			wantKeys: []string{"color", "icon"},
+			want:     `{"name":"foo","icon":"email","color":"red"}`,
+		},
+		{
+			name:     "known field in allowlist",
+			allowed:  []string{"name"},
+			wantKeys: []string{},
+			want:     `{"name":"foo"}`,
+		},
+		{
+			name:     "empty allowlist",
+			wantKeys: []string{},
+			want:     `{"name":"foo"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s S
+			s.raw.Configure(WithRetainOnly(tt.allowed...))
+			require.NoError(t, s.raw.FromJSON([]byte(input), &s))
+			assert.Equal(t, "foo", s.Name, "known fields are unaffected")
+			assert.Equal(t, tt.wantKeys, s.raw.UnknownKeys())
+			assert.Equal(t, tt.want, mustMarshal(t, &s))
+		})
+	}
+}
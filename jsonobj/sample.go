@@ -0,0 +1,57 @@
+package jsonobj
+
+import (
+	"fmt"
+	"math"
+)
+
+// WithUnknownSampleRate makes FromJSON retain only a sample of unknown fields,
+// each with probability rate, which must be between 0 and 1. This reduces
+// memory use for objects with many unknown fields, while still surfacing new
+// fields over time.
+//
+// Sampling is deterministic per key: it uses the 64-bit FNV-1a hash of the
+// key, so the same key is always either retained or dropped. Dropped fields
+// are not emitted by ToJSON, so objects don't round-trip without loss.
+//
+// An invalid rate causes FromJSON to fail.
+func WithUnknownSampleRate(rate float64) Option {
+	var err error
+	if math.IsNaN(rate) || rate < 0 || rate > 1 {
+		err = fmt.Errorf("must be between 0 and 1, got %v", rate)
+	}
+
+	return func(o *options) {
+		o.unknownSampleRate = &rate
+		o.sampleRateErr = err
+	}
+}
+
+// checkSampleRate returns an error if the WithUnknownSampleRate rate is invalid.
+func (r *Retain) checkSampleRate() error {
+	if err := r.opts.sampleRateErr; err != nil {
+		return fmt.Errorf("invalid unknown sample rate: %w", err)
+	}
+	return nil
+}
+
+// sampleUnknown drops retained fields that aren't sampled.
+func (r *Retain) sampleUnknown() {
+	if r.opts.unknownSampleRate == nil || r.opts.sampleRateErr != nil {
+		return
+	}
+
+	rate := *r.opts.unknownSampleRate
+	for k := range r.raw {
+		if !sampled(k, rate) {
+			delete(r.raw, k)
+		}
+	}
+}
+
+// sampled returns whether key is in the sample with the given rate.
+func sampled(key string, rate float64) bool {
+	// Use the top 53 bits of the hash, which are exactly representable
+	// as a float64, to get a uniform value in [0, 1).
+	return float64(keyHash(key)>>11)/(1<<53) < rate
+}
@@ -0,0 +1,60 @@
+package jsonobj
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithUnknownSampleRate(t *testing.T) {
+	const numKeys = 1000
+
+	var sb strings.Builder
+	sb.WriteString(`{"name": "foo"`)
+	for i := 0; i < numKeys; i++ {
+		fmt.Fprintf(&sb, `, "k%d": %d`, i, i)
+	}
+	sb.WriteString("}")
+	input := []byte(sb.String())
+
+	retained := func(rate float64) map[string]bool {
+		var s S
+		s.raw.Configure(WithUnknownSampleRate(rate))
+		require.NoError(t, s.raw.FromJSON(input, &s))
+		assert.Equal(t, "foo", s.Name, "known fields are not sampled")
+
+		keys := make(map[string]bool)
+		for i := 0; i < numKeys; i++ {
+			k := fmt.Sprintf("k%d", i)
+			if _, ok := s.raw.GetUnknown(k); ok {
+				keys[k] = true
+			}
+		}
+		return keys
+	}
+
+	assert.Empty(t, retained(0))
+	assert.Len(t, retained(1), numKeys)
+
+	half := retained(0.5)
+	assert.InDelta(t, numKeys/2, len(half), numKeys/10)
+	assert.Equal(t, half, retained(0.5), "sampling should be deterministic")
+
+	// Keys sampled at a lower rate are also sampled at higher rates.
+	for k := range retained(0.1) {
+		assert.True(t, half[k], "key %v sampled at 0.1 but not 0.5", k)
+	}
+}
+
+func TestWithUnknownSampleRate_Invalid(t *testing.T) {
+	for _, rate := range []float64{-0.1, 1.1, math.NaN()} {
+		var s S
+		err := s.raw.FromJSON([]byte(`{"name": "foo", "a": 1}`), &s, WithUnknownSampleRate(rate))
+		assert.EqualError(t, err, fmt.Sprintf("invalid unknown sample rate: must be between 0 and 1, got %v", rate))
+		assert.Empty(t, s.Name, "object should not be modified")
+	}
+}
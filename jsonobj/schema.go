@@ -0,0 +1,480 @@
+package jsonobj
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// WithOutputSchema validates the output of ToJSON (including retained fields)
+// against the JSON Schema schema, and fails with a *SchemaError listing all
+// violations if the output doesn't conform.
+//
+// The following subset of JSON Schema is supported: boolean schemas, and the
+// type, enum, const, properties, required, additionalProperties, items,
+// minimum, maximum, minLength, maxLength, pattern, minItems and maxItems
+// keywords, and annotations that don't affect validation (such as title and
+// description). Other keywords (such as $ref, allOf and format) are rejected
+// rather than ignored, so output is never reported as valid without being
+// checked against the whole schema. An invalid or unsupported schema causes
+// ToJSON to fail.
+//
+// Validation decodes the output, so it's relatively expensive.
+func WithOutputSchema(schema []byte) Option {
+	s, err := parseSchema(schema)
+	return func(o *options) {
+		o.outputSchema = s
+		o.outputSchemaErr = err
+	}
+}
+
+// SchemaError is returned by ToJSON when the output doesn't match the schema
+// configured using WithOutputSchema.
+type SchemaError struct {
+	// Violations describes each violation, prefixed with the JSON Pointer
+	// of the value that violates the schema.
+	Violations []string
+}
+
+func (e *SchemaError) Error() string {
+	return "output does not match schema: " + strings.Join(e.Violations, "; ")
+}
+
+func (r *Retain) validateSchema(data []byte) error {
+	if r.opts.outputSchemaErr != nil {
+		return fmt.Errorf("invalid output schema: %w", r.opts.outputSchemaErr)
+	}
+	if r.opts.outputSchema == nil {
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return err
+	}
+
+	var violations []string
+	r.opts.outputSchema.validate("", v, &violations)
+	if len(violations) > 0 {
+		sort.Strings(violations)
+		return &SchemaError{Violations: violations}
+	}
+	return nil
+}
+
+type schema struct {
+	// never is set for the false schema, which nothing matches.
+	never bool
+
+	Type                 schemaTypes        `json:"type"`
+	Enum                 []json.RawMessage  `json:"enum"`
+	Const                json.RawMessage    `json:"const"`
+	Properties           map[string]*schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties *schema            `json:"additionalProperties"`
+	Items                *schema            `json:"items"`
+	Minimum              *json.Number       `json:"minimum"`
+	Maximum              *json.Number       `json:"maximum"`
+	MinLength            *int               `json:"minLength"`
+	MaxLength            *int               `json:"maxLength"`
+	Pattern              string             `json:"pattern"`
+	MinItems             *int               `json:"minItems"`
+	MaxItems             *int               `json:"maxItems"`
+
+	pattern *regexp.Regexp
+}
+
+func parseSchema(data []byte) (*schema, error) {
+	var s schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	if err := s.compile(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (s *schema) UnmarshalJSON(data []byte) error {
+	switch string(bytes.TrimSpace(data)) {
+	case "true":
+		*s = schema{}
+		return nil
+	case "false":
+		*s = schema{never: true}
+		return nil
+	}
+
+	var keywords map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keywords); err != nil {
+		return err
+	}
+	unsupported := make([]string, 0, len(keywords))
+	for k := range keywords {
+		if _, ok := supportedKeywords[k]; !ok {
+			unsupported = append(unsupported, strconv.Quote(k))
+		}
+	}
+	if len(unsupported) > 0 {
+		sort.Strings(unsupported)
+		return fmt.Errorf("unsupported keywords: %v", strings.Join(unsupported, ", "))
+	}
+
+	type plain schema
+	return json.Unmarshal(data, (*plain)(s))
+}
+
+// supportedKeywords are the keywords that are validated, or annotations that
+// don't affect validation.
+var supportedKeywords = map[string]struct{}{
+	"type":                 {},
+	"enum":                 {},
+	"const":                {},
+	"properties":           {},
+	"required":             {},
+	"additionalProperties": {},
+	"items":                {},
+	"minimum":              {},
+	"maximum":              {},
+	"minLength":            {},
+	"maxLength":            {},
+	"pattern":              {},
+	"minItems":             {},
+	"maxItems":             {},
+
+	// Annotations.
+	"$schema":     {},
+	"$id":         {},
+	"$comment":    {},
+	"title":       {},
+	"description": {},
+	"default":     {},
+	"examples":    {},
+	"deprecated":  {},
+	"readOnly":    {},
+	"writeOnly":   {},
+}
+
+// compile compiles patterns in s and all of its subschemas.
+func (s *schema) compile() error {
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+		s.pattern = re
+	}
+
+	for _, sub := range s.Properties {
+		if err := sub.compile(); err != nil {
+			return err
+		}
+	}
+	for _, sub := range []*schema{s.AdditionalProperties, s.Items} {
+		if sub == nil {
+			continue
+		}
+		if err := sub.compile(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// schemaTypes is the type keyword, which may be a single type or a list.
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = schemaTypes{single}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(t))
+}
+
+// validate appends violations of s by the value v at path to violations.
+func (s *schema) validate(path string, v any, violations *[]string) {
+	addf := func(format string, args ...any) {
+		p := path
+		if p == "" {
+			p = "/"
+		}
+		*violations = append(*violations, p+": "+fmt.Sprintf(format, args...))
+	}
+
+	if s.never {
+		addf("no value is allowed")
+		return
+	}
+
+	if len(s.Type) > 0 && !s.Type.matches(v) {
+		addf("expected type %v, got %v", strings.Join(s.Type, " or "), jsonType(v))
+		return
+	}
+
+	if len(s.Enum) > 0 && !matchesAny(v, s.Enum) {
+		addf("value is not one of the allowed values")
+	}
+	if s.Const != nil && !matchesAny(v, []json.RawMessage{s.Const}) {
+		addf("value does not match const %s", s.Const)
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		s.validateObject(path, v, addf, violations)
+	case []any:
+		s.validateArray(path, v, addf, violations)
+	case string:
+		n := utf8.RuneCountInString(v)
+		if s.MinLength != nil && n < *s.MinLength {
+			addf("length %v is less than minLength %v", n, *s.MinLength)
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			addf("length %v is greater than maxLength %v", n, *s.MaxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			addf("value does not match pattern %q", s.Pattern)
+		}
+	case json.Number:
+		if s.Minimum != nil && compareNumbers(v, *s.Minimum) < 0 {
+			addf("value %v is less than minimum %v", v, *s.Minimum)
+		}
+		if s.Maximum != nil && compareNumbers(v, *s.Maximum) > 0 {
+			addf("value %v is greater than maximum %v", v, *s.Maximum)
+		}
+	}
+}
+
+func (s *schema) validateObject(path string, obj map[string]any, addf func(string, ...any), violations *[]string) {
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			addf("missing required property %q", name)
+		}
+	}
+
+	for k, v := range obj {
+		sub, ok := s.Properties[k]
+		if !ok {
+			sub = s.AdditionalProperties
+		}
+		if sub != nil {
+			sub.validate(path+"/"+escapePointer(k), v, violations)
+		}
+	}
+}
+
+func (s *schema) validateArray(path string, arr []any, addf func(string, ...any), violations *[]string) {
+	if s.MinItems != nil && len(arr) < *s.MinItems {
+		addf("%v items is less than minItems %v", len(arr), *s.MinItems)
+	}
+	if s.MaxItems != nil && len(arr) > *s.MaxItems {
+		addf("%v items is greater than maxItems %v", len(arr), *s.MaxItems)
+	}
+	if s.Items != nil {
+		for i, v := range arr {
+			s.Items.validate(fmt.Sprintf("%v/%d", path, i), v, violations)
+		}
+	}
+}
+
+func (t schemaTypes) matches(v any) bool {
+	got := jsonType(v)
+	for _, want := range t {
+		if want == got || (want == "integer" && got == "number" && isInteger(v.(json.Number))) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType returns the JSON Schema type of a value decoded with UseNumber.
+func jsonType(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func isInteger(n json.Number) bool {
+	d, ok := parseDecimal(n)
+	return ok && int64(len(d.digits)) <= d.exp
+}
+
+// compareNumbers compares a and b, treating invalid numbers as equal.
+func compareNumbers(a, b json.Number) int {
+	da, okA := parseDecimal(a)
+	db, okB := parseDecimal(b)
+	if !okA || !okB {
+		return 0
+	}
+	return da.cmp(db)
+}
+
+// maxDecimalExp bounds the exponent of a decimal, so it doesn't overflow.
+// Larger exponents are clamped, so numbers with larger exponents (which are
+// far outside the range of a float64) may compare as equal.
+const maxDecimalExp = 1 << 62
+
+// decimal is an exact representation of a JSON number, as 0.digits × 10^exp.
+// Unlike big.Rat, which computes powers of 10, parsing and comparing decimals
+// is linear in the length of the number, even for numbers such as 1e999999999.
+type decimal struct {
+	neg bool
+
+	// digits has no leading or trailing zeros, and is empty for zero.
+	digits string
+	exp    int64
+}
+
+// parseDecimal parses the JSON number n, returning false if it's invalid.
+func parseDecimal(n json.Number) (decimal, bool) {
+	s := n.String()
+	var d decimal
+	if d.neg = strings.HasPrefix(s, "-"); d.neg {
+		s = s[1:]
+	}
+
+	mantissa, expStr, hasExp := strings.Cut(strings.ToLower(s), "e")
+	intPart, frac, _ := strings.Cut(mantissa, ".")
+	if intPart == "" || !isDigits(intPart) || !isDigits(frac) {
+		return decimal{}, false
+	}
+	if hasExp {
+		expStr = strings.TrimPrefix(expStr, "+")
+		exp, err := strconv.ParseInt(expStr, 10, 64)
+		if err != nil && !errors.Is(err, strconv.ErrRange) {
+			return decimal{}, false
+		}
+		d.exp = max(-maxDecimalExp, min(exp, maxDecimalExp))
+	}
+
+	digits := strings.TrimRight(intPart+frac, "0")
+	d.exp += int64(len(intPart))
+	trimmed := strings.TrimLeft(digits, "0")
+	d.exp -= int64(len(digits) - len(trimmed))
+	d.digits = trimmed
+	if d.digits == "" {
+		return decimal{}, true
+	}
+	return d, true
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func (d decimal) sign() int {
+	switch {
+	case d.digits == "":
+		return 0
+	case d.neg:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// cmp compares d and o, returning -1, 0 or 1.
+func (d decimal) cmp(o decimal) int {
+	if a, b := d.sign(), o.sign(); a != b || a == 0 {
+		return cmp.Compare(a, b)
+	}
+
+	// Compare the magnitudes, using the position of the leading digit,
+	// and then the digits.
+	c := cmp.Compare(d.exp, o.exp)
+	if c == 0 {
+		c = strings.Compare(d.digits, o.digits)
+	}
+	if d.neg {
+		return -c
+	}
+	return c
+}
+
+// matchesAny returns whether v is equal to any of the values.
+func matchesAny(v any, values []json.RawMessage) bool {
+	for _, value := range values {
+		dec := json.NewDecoder(bytes.NewReader(value))
+		dec.UseNumber()
+
+		var want any
+		if err := dec.Decode(&want); err == nil && schemaEqual(v, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaEqual returns whether the decoded values a and b are equal, comparing
+// numbers by value (so 2 and 2.0 are equal), as JSON Schema does.
+func schemaEqual(a, b any) bool {
+	switch a := a.(type) {
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		da, okA := parseDecimal(a)
+		db, okB := parseDecimal(b)
+		return okA && okB && da.cmp(db) == 0
+	case []any:
+		b, ok := b.([]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !schemaEqual(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, av := range a {
+			bv, ok := b[k]
+			if !ok || !schemaEqual(av, bv) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
+
+// escapePointer escapes a key for use in a JSON Pointer.
+func escapePointer(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
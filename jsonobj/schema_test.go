@@ -0,0 +1,232 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithOutputSchema(t *testing.T) {
+	const pageSchema = `{
+		"type": "object",
+		"required": ["title", "id"],
+		"properties": {
+			"id": {"type": "integer", "minimum": 1},
+			"title": {"type": "string", "minLength": 1, "maxLength": 5},
+			"slug": {"type": ["string", "null"], "pattern": "^[a-z-]+$"},
+			"kind": {"enum": ["page", "post"]},
+			"v": {"const": 2},
+			"n": {"type": "integer"},
+			"tags": {"type": "array", "maxItems": 2, "items": {"type": "string"}},
+			"owner": {
+				"type": "object",
+				"properties": {"a/b": {"maximum": 10}},
+				"additionalProperties": false
+			}
+		},
+		"additionalProperties": {"type": ["string", "number"]}
+	}`
+
+	type Page struct {
+		ID    int    `json:"id"`
+		Title string `json:"title"`
+	}
+
+	tests := []struct {
+		name     string
+		page     Page
+		retained string
+		want     []string
+	}{
+		{
+			name:     "valid",
+			page:     Page{ID: 1, Title: "t"},
+			retained: `{"slug": "a-b", "kind": "post", "v": 2.0, "tags": ["x"], "owner": {"a/b": 10}, "n": 1e2, "extra": "e"}`,
+		},
+		{
+			name:     "null allowed by type list",
+			page:     Page{ID: 1, Title: "t"},
+			retained: `{"slug": null}`,
+		},
+		{
+			name: "known field violations",
+			page: Page{ID: 0, Title: "toolong"},
+			want: []string{
+				"/id: value 0 is less than minimum 1",
+				"/title: length 7 is greater than maxLength 5",
+			},
+		},
+		{
+			name:     "retained field violations",
+			page:     Page{ID: 1, Title: "t"},
+			retained: `{"slug": "A", "kind": "other", "v": 3, "tags": ["x", 1, "z"], "owner": {"a/b": 11, "c": 1}, "extra": true}`,
+			want: []string{
+				"/extra: expected type string or number, got boolean",
+				"/kind: value is not one of the allowed values",
+				"/owner/a~1b: value 11 is greater than maximum 10",
+				"/owner/c: no value is allowed",
+				`/slug: value does not match pattern "^[a-z-]+$"`,
+				"/tags/1: expected type string, got number",
+				"/tags: 3 items is greater than maxItems 2",
+				"/v: value does not match const 2",
+			},
+		},
+		{
+			name:     "integer type",
+			page:     Page{ID: 1, Title: "t"},
+			retained: `{"n": 1.5}`,
+			want: []string{
+				"/n: expected type integer, got number",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r Retain
+			r.Configure(WithOutputSchema([]byte(pageSchema)))
+			if tt.retained != "" {
+				var p Page
+				require.NoError(t, r.FromJSON([]byte(tt.retained), &p))
+			}
+
+			out, err := r.ToJSON(tt.page)
+			if tt.want == nil {
+				require.NoError(t, err)
+				assert.NotEmpty(t, out)
+				return
+			}
+
+			var schemaErr *SchemaError
+			require.True(t, errors.As(err, &schemaErr), "expected SchemaError, got %v", err)
+			assert.Equal(t, tt.want, schemaErr.Violations)
+		})
+	}
+}
+
+func TestWithOutputSchema_Root(t *testing.T) {
+	var r Retain
+	r.Configure(WithOutputSchema([]byte(`{"required": ["a", "b"]}`)))
+	r.SetUnknown("a", []byte(`1`))
+
+	_, err := r.ToJSON(struct{}{})
+	assert.EqualError(t, err, `output does not match schema: /: missing required property "b"`)
+}
+
+func TestWithOutputSchema_LargeNumbers(t *testing.T) {
+	var r Retain
+	r.Configure(WithOutputSchema([]byte(`{
+		"properties": {
+			"big": {"type": "integer", "maximum": 10},
+			"small": {"type": "integer", "minimum": 0},
+			"precise": {"enum": [9007199254740993]}
+		}
+	}`)))
+	r.SetUnknown("big", []byte(`1e999999999`))
+	r.SetUnknown("small", []byte(`-1e-999999999`))
+	r.SetUnknown("precise", []byte(`9007199254740992`))
+
+	_, err := r.ToJSON(struct{}{})
+	assert.EqualError(t, err, "output does not match schema: "+
+		"/big: value 1e999999999 is greater than maximum 10; "+
+		"/precise: value is not one of the allowed values; "+
+		"/small: expected type integer, got number")
+}
+
+func TestCompareNumbers(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1", "1", 0},
+		{"1", "1.0", 0},
+		{"100", "1e2", 0},
+		{"0.05", "5E-2", 0},
+		{"0", "-0.0e5", 0},
+		{"1", "2", -1},
+		{"-1", "1", -1},
+		{"-2", "-1", -1},
+		{"0.5", "0.05", 1},
+		{"1.5", "1.45", 1},
+		{"-1e999999999", "0", -1},
+		{"1e999999999", "2e999999999", -1},
+		{"1e-999999999", "0", 1},
+		{"9007199254740993", "9007199254740992", 1},
+		{"invalid", "1", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			assert.Equal(t, tt.want, compareNumbers(json.Number(tt.a), json.Number(tt.b)))
+			assert.Equal(t, -tt.want, compareNumbers(json.Number(tt.b), json.Number(tt.a)))
+		})
+	}
+}
+
+func TestIsInteger(t *testing.T) {
+	tests := []struct {
+		n    string
+		want bool
+	}{
+		{"0", true},
+		{"-0.0", true},
+		{"1.0", true},
+		{"1.5e1", true},
+		{"1e999999999", true},
+		{"1.5", false},
+		{"15e-1", false},
+		{"1e-999999999", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.n, func(t *testing.T) {
+			assert.Equal(t, tt.want, isInteger(json.Number(tt.n)))
+		})
+	}
+}
+
+func TestWithOutputSchema_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		wantErr string
+	}{
+		{
+			name:    "invalid JSON",
+			schema:  `{`,
+			wantErr: "invalid output schema: unexpected end of JSON input",
+		},
+		{
+			name:    "invalid pattern",
+			schema:  `{"properties": {"a": {"pattern": "("}}}`,
+			wantErr: "invalid output schema: invalid pattern",
+		},
+		{
+			name:    "invalid type",
+			schema:  `{"type": 1}`,
+			wantErr: "invalid output schema",
+		},
+		{
+			name:    "unsupported keywords",
+			schema:  `{"title": "t", "allOf": [], "$ref": "#/a", "properties": {"a": {"format": "email"}}}`,
+			wantErr: `unsupported keywords: "$ref", "allOf"`,
+		},
+		{
+			name:    "unsupported nested keyword",
+			schema:  `{"properties": {"a": {"description": "d", "exclusiveMinimum": 1}}}`,
+			wantErr: `unsupported keywords: "exclusiveMinimum"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r Retain
+			r.Configure(WithOutputSchema([]byte(tt.schema)))
+			_, err := r.ToJSON(struct{}{})
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package jsonobj
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// WithRetainedSets treats the retained array fields keys as sets, so ToJSON
+// removes duplicate elements and sorts the remaining elements. Elements are
+// compared and emitted using their canonical JSON (see WithChecksumField),
+// so the output is the same regardless of the input order or formatting.
+//
+// Retained fields that aren't arrays, and known fields, are unaffected.
+func WithRetainedSets(keys ...string) Option {
+	return func(o *options) {
+		o.retainedSets = append(o.retainedSets, keys...)
+	}
+}
+
+func (r *Retain) normalizeSets(all map[string]any) error {
+	for _, k := range r.opts.retainedSets {
+		// all may contain values salvaged by WithRetainedFallback.
+		key := r.retainedKey(k)
+		v, ok := all[key].(json.RawMessage)
+		if !ok {
+			continue
+		}
+
+		set, err := normalizeSet(v)
+		if err != nil {
+			return fmt.Errorf("retained set %q: %w", k, err)
+		}
+		all[key] = set
+	}
+	return nil
+}
+
+// normalizeSet returns the sorted unique elements of a JSON array,
+// or the original value if it's not an array.
+func normalizeSet(v json.RawMessage) (json.RawMessage, error) {
+	trimmed := bytes.TrimSpace(v)
+	if len(trimmed) == 0 || trimmed[0] != '[' {
+		return v, nil
+	}
+
+	var elems []json.RawMessage
+	if err := json.Unmarshal(v, &elems); err != nil {
+		return nil, err
+	}
+
+	unique := make(map[string]struct{}, len(elems))
+	for _, elem := range elems {
+		canonical, err := canonicalJSON(elem)
+		if err != nil {
+			return nil, err
+		}
+		unique[string(canonical)] = struct{}{}
+	}
+
+	sorted := make([]string, 0, len(unique))
+	for elem := range unique {
+		sorted = append(sorted, elem)
+	}
+	sort.Strings(sorted)
+
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, elem := range sorted {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(elem)
+	}
+	buf.WriteByte(']')
+	return buf.Bytes(), nil
+}
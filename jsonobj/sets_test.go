@@ -0,0 +1,65 @@
+package jsonobj
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRetainedSets(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want string
+	}{
+		{
+			name: "strings",
+			json: `{"tags": ["b", "a", "b", "c", "a"]}`,
+			want: `{"tags": ["a", "b", "c"]}`,
+		},
+		{
+			name: "mixed elements",
+			json: `{"tags": [2, "a", {"y": 1, "x": 2}, 1, {"x": 2, "y": 1}, true, null]}`,
+			want: `{"tags": ["a", 1, 2, null, true, {"x": 2, "y": 1}]}`,
+		},
+		{
+			name: "empty array",
+			json: `{"tags": []}`,
+			want: `{"tags": []}`,
+		},
+		{
+			name: "not an array",
+			json: `{"tags": "b,a", "ids": {"a": 2, "b": 1}}`,
+			want: `{"tags": "b,a", "ids": {"a": 2, "b": 1}}`,
+		},
+		{
+			name: "other arrays unaffected",
+			json: `{"tags": ["b", "a"], "other": ["b", "a", "a"]}`,
+			want: `{"tags": ["a", "b"], "other": ["b", "a", "a"]}`,
+		},
+		{
+			name: "known fields unaffected",
+			json: `{"name": "foo"}`,
+			want: `{"name": "foo"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s S
+			s.raw.Configure(WithRetainedSets("tags", "ids", "name"))
+			require.NoError(t, json.Unmarshal([]byte(tt.json), &s))
+
+			got := mustMarshal(t, &s)
+			assert.JSONEq(t, tt.want, got)
+
+			// JSONEq ignores array order, so verify the exact output.
+			var want bytes.Buffer
+			require.NoError(t, json.Compact(&want, []byte(tt.want)))
+			assert.Equal(t, want.String(), got)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// WithShadowStruct layers stricter typing over the decoded struct.
+// shadow must be a struct pointer, and any of its fields present in the input
+// are decoded into shadow in addition to the main struct. FromJSON fails if
+// a value doesn't match the shadow field's type. Input keys match shadow
+// fields case-insensitively, as they match known fields.
+//
+// Only top-level scalar fields (bools, numbers and strings) are supported.
+//
+// Each FromJSON resets the fields of shadow before decoding, so fields absent
+// from the input are zero. Since FromJSON writes to shadow, a Retain using
+// this option (including copies made by Clone, which share shadow) must not
+// be used by concurrent FromJSON calls.
+func WithShadowStruct(shadow any) Option {
+	return func(o *options) {
+		o.shadow = shadow
+	}
+}
+
+// decodeShadow decodes the fields of r.raw into the shadow struct.
+// folded indexes the keys of r.raw, see foldKeys.
+func (r *Retain) decodeShadow(folded map[string][]string) error {
+	rv, ok := ensureStruct(r.opts.shadow, true /* requirePtr */)
+	if !ok {
+		return fmt.Errorf("shadow requires a struct pointer, got %T", r.opts.shadow)
+	}
+
+	fields := cachedFields(rv.Type(), r.tagKey())
+	return forJSONField(rv, r.tagKey(), func(t jsonTag, v reflect.Value) error {
+		if !isScalar(v.Kind()) {
+			return fmt.Errorf("shadow field %q has unsupported type %v", t.name(), v.Type())
+		}
+		if v.CanSet() {
+			v.SetZero()
+		}
+
+		keys, _ := r.lookupField(t, fields.names, folded)
+		if len(keys) == 0 {
+			return nil
+		}
+
+		v, err := settableField(rv, t)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(r.raw[keys[len(keys)-1]], v.Addr().Interface()); err != nil {
+			return fmt.Errorf("shadow field %q: %w", t.name(), err)
+		}
+		return nil
+	})
+}
+
+func isScalar(k reflect.Kind) bool {
+	switch k {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,131 @@
+package jsonobj
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithShadowStruct(t *testing.T) {
+	type Loose struct {
+		Count any `json:"count"`
+	}
+
+	type Shadow struct {
+		Count   int    `json:"count"`
+		Version string `json:"version"`
+	}
+
+	tests := []struct {
+		name       string
+		json       string
+		wantShadow Shadow
+		wantErr    string
+	}{
+		{
+			name: "empty",
+			json: `{}`,
+		},
+		{
+			name:       "known and unknown fields",
+			json:       `{"count": 3, "version": "v1"}`,
+			wantShadow: Shadow{Count: 3, Version: "v1"},
+		},
+		{
+			name:    "known field type mismatch",
+			json:    `{"count": "3"}`,
+			wantErr: `shadow field "count": json: cannot unmarshal string into Go value of type int`,
+		},
+		{
+			name:    "unknown field type mismatch",
+			json:    `{"version": 1}`,
+			wantErr: `shadow field "version": json: cannot unmarshal number into Go value of type string`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				loose  Loose
+				shadow Shadow
+				r      Retain
+			)
+			r.Configure(WithShadowStruct(&shadow))
+
+			err := r.FromJSON([]byte(tt.json), &loose)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantShadow, shadow)
+		})
+	}
+}
+
+func TestWithShadowStruct_Decodes(t *testing.T) {
+	type Shadow struct {
+		Count   int    `json:"count"`
+		Version string `json:"version"`
+	}
+
+	var (
+		loose  struct{ Count any }
+		shadow Shadow
+		r      Retain
+	)
+	r.Configure(WithShadowStruct(&shadow))
+
+	require.NoError(t, r.FromJSON([]byte(`{"COUNT": 3, "Version": "v1"}`), &loose))
+	assert.Equal(t, Shadow{Count: 3, Version: "v1"}, shadow, "keys should match case-insensitively")
+
+	require.NoError(t, r.FromJSON([]byte(`{"count": 4}`), &loose))
+	assert.Equal(t, Shadow{Count: 4}, shadow, "fields should be reset on each decode")
+
+	require.NoError(t, r.FromJSON([]byte(`{"count": 5, "Count": 6}`), &loose))
+	assert.Equal(t, 5, shadow.Count, "exact match should be preferred")
+}
+
+func TestWithShadowStruct_TagKey(t *testing.T) {
+	type Shadow struct {
+		Count int `json:"other" api:"count"`
+	}
+
+	var (
+		loose struct {
+			Count any `api:"count"`
+		}
+		shadow Shadow
+		r      Retain
+	)
+	r.Configure(WithTagKey("api"), WithShadowStruct(&shadow))
+
+	require.NoError(t, r.FromJSON([]byte(`{"count": 3, "other": 4}`), &loose))
+	assert.Equal(t, Shadow{Count: 3}, shadow)
+}
+
+func TestWithShadowStruct_Unsupported(t *testing.T) {
+	var loose struct {
+		Name string
+	}
+
+	t.Run("not struct pointer", func(t *testing.T) {
+		var r Retain
+		r.Configure(WithShadowStruct(struct{}{}))
+		err := r.FromJSON([]byte(`{}`), &loose)
+		assert.EqualError(t, err, "shadow requires a struct pointer, got struct {}")
+	})
+
+	t.Run("non-scalar field", func(t *testing.T) {
+		var shadow struct {
+			Tags []string `json:"tags"`
+		}
+
+		var r Retain
+		r.Configure(WithShadowStruct(&shadow))
+		err := r.FromJSON([]byte(`{}`), &loose)
+		assert.EqualError(t, err, `shadow field "tags" has unsupported type []string`)
+	})
+}
@@ -0,0 +1,67 @@
+package jsonobj
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// UnmarshalSlice decodes a JSON array of objects into dst, where each element
+// retains its own unknown fields:
+//
+//	var pages []Page
+//	err := jsonobj.UnmarshalSlice(data, &pages)
+//
+// T must be Retainable (see CheckRetainable). dst is replaced with newly
+// decoded elements, so elements never share retained fields with previous
+// values of dst. A JSON null sets dst to nil.
+func UnmarshalSlice[T any](data []byte, dst *[]T) error {
+	if err := CheckRetainable[T](); err != nil {
+		return err
+	}
+
+	var raws []json.RawMessage
+	if err := json.Unmarshal(data, &raws); err != nil {
+		return err
+	}
+	if raws == nil {
+		*dst = nil
+		return nil
+	}
+
+	elems := make([]T, len(raws))
+	for i, raw := range raws {
+		if err := json.Unmarshal(raw, &elems[i]); err != nil {
+			return fmt.Errorf("element %d: %w", i, err)
+		}
+	}
+	*dst = elems
+	return nil
+}
+
+// MarshalSlice is the inverse of UnmarshalSlice, encoding each element of s
+// with its retained fields. A nil slice is encoded as null, as with
+// encoding/json.
+func MarshalSlice[T any](s []T) ([]byte, error) {
+	if err := CheckRetainable[T](); err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return []byte("null"), nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i := range s {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		data, err := json.Marshal(&s[i])
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		buf.Write(data)
+	}
+	buf.WriteByte(']')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,80 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshalSlice(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantLen  int
+		wantNil  bool
+		wantJSON string
+	}{
+		{
+			name:     "null",
+			input:    `null`,
+			wantNil:  true,
+			wantJSON: `null`,
+		},
+		{
+			name:     "empty",
+			input:    ` [] `,
+			wantJSON: `[]`,
+		},
+		{
+			name:     "mixed unknowns",
+			input:    `[{"name": "a", "x": 1}, {"name": "b"}, {"y": [true], "name": "c"}]`,
+			wantLen:  3,
+			wantJSON: `[{"name":"a","x":1},{"name":"b"},{"y":[true],"name":"c"}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []S{{Name: "stale"}}
+			require.NoError(t, UnmarshalSlice([]byte(tt.input), &got))
+			assert.Len(t, got, tt.wantLen)
+			assert.Equal(t, tt.wantNil, got == nil, "nil slice")
+
+			out, err := MarshalSlice(got)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantJSON, string(out))
+		})
+	}
+}
+
+func TestUnmarshalSlice_PerElement(t *testing.T) {
+	var got []S
+	require.NoError(t, UnmarshalSlice([]byte(`[{"name": "a", "x": 1}, {"name": "b"}]`), &got))
+
+	x, ok := got[0].raw.GetUnknown("x")
+	assert.True(t, ok)
+	assert.Equal(t, `1`, string(x))
+	assert.False(t, got[1].raw.HasUnknown(), "unknowns should not be shared between elements")
+
+	got[1].Name = "updated"
+	got[1].raw.SetUnknown("z", json.RawMessage(`2`))
+	out, err := MarshalSlice(got)
+	require.NoError(t, err)
+	assert.Equal(t, `[{"name":"a","x":1},{"name":"updated","z":2}]`, string(out))
+}
+
+func TestUnmarshalSlice_Errors(t *testing.T) {
+	var got []S
+	assert.ErrorContains(t, UnmarshalSlice([]byte(`{}`), &got), "cannot unmarshal object")
+	assert.ErrorContains(t, UnmarshalSlice([]byte(`[{}, 1]`), &got), "element 1: json: cannot unmarshal number")
+
+	type noMethods struct {
+		Name string
+	}
+	var invalid []noMethods
+	assert.EqualError(t, UnmarshalSlice([]byte(`[]`), &invalid), "*jsonobj.noMethods not Retainable: requires MarshalJSON and UnmarshalJSON methods")
+	_, err := MarshalSlice(invalid)
+	assert.EqualError(t, err, "*jsonobj.noMethods not Retainable: requires MarshalJSON and UnmarshalJSON methods")
+}
@@ -0,0 +1,85 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SplitJSON marshals obj into two standalone JSON objects: known contains
+// only the fields modelled by obj, and unknown contains only the fields
+// retained in FromJSON. The two documents can be recombined using MergeJSON.
+//
+// The fields are output as ToJSON outputs them, with the same keys (such as
+// re-added prefixes, see WithStripPrefix), values and order, so merging the
+// two documents gives the same object as ToJSON. Keys added by ToJSON, such
+// as the checksum field, are in known.
+func (r *Retain) SplitJSON(obj any) (known []byte, unknown []byte, err error) {
+	keys, all, err := r.outputFields("SplitJSON", obj)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rv, _ := ensureStruct(obj, false /* requirePtr */)
+	fields := cachedFields(rv.Type(), r.tagKey())
+
+	// Retained fields with the name of a known field are replaced by it,
+	// see addKnownFields.
+	retained := make(map[string]struct{}, len(r.raw))
+	for k := range r.raw {
+		key := r.retainedKey(k)
+		if _, ok := fields.known[key]; ok {
+			continue
+		}
+		if r.opts.outputKeyCase != nil {
+			key = r.opts.outputKeyCase.Convert(key)
+		}
+		retained[key] = struct{}{}
+	}
+
+	var knownKeys, unknownKeys []string
+	for _, k := range keys {
+		if _, ok := retained[k]; ok {
+			unknownKeys = append(unknownKeys, k)
+		} else {
+			knownKeys = append(knownKeys, k)
+		}
+	}
+
+	if known, err = r.marshalOrdered(knownKeys, all); err != nil {
+		return nil, nil, err
+	}
+	if unknown, err = r.marshalOrdered(unknownKeys, all); err != nil {
+		return nil, nil, err
+	}
+	if known, err = r.formatOutput(known); err != nil {
+		return nil, nil, err
+	}
+	if unknown, err = r.formatOutput(unknown); err != nil {
+		return nil, nil, err
+	}
+	return known, unknown, nil
+}
+
+// MergeJSON combines the JSON objects known and unknown (as returned by
+// SplitJSON) into a single JSON object. If a field is present in both,
+// the value from known is used.
+func MergeJSON(known, unknown []byte) ([]byte, error) {
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(unknown, &all); err != nil {
+		return nil, fmt.Errorf("unmarshal unknown: %w", err)
+	}
+
+	var knownFields map[string]json.RawMessage
+	if err := json.Unmarshal(known, &knownFields); err != nil {
+		return nil, fmt.Errorf("unmarshal known: %w", err)
+	}
+
+	if all == nil {
+		all = make(map[string]json.RawMessage, len(knownFields))
+	}
+	for k, v := range knownFields {
+		all[k] = v
+	}
+
+	return json.Marshal(all)
+}
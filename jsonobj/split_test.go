@@ -0,0 +1,131 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetain_SplitJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		json        string
+		wantKnown   string
+		wantUnknown string
+	}{
+		{
+			name:        "empty",
+			json:        `{}`,
+			wantKnown:   `{}`,
+			wantUnknown: `{}`,
+		},
+		{
+			name:        "only known",
+			json:        `{"name": "foo"}`,
+			wantKnown:   `{"name": "foo"}`,
+			wantUnknown: `{}`,
+		},
+		{
+			name:        "only unknown",
+			json:        `{"num": 1, "obj": {"k": "v"}}`,
+			wantKnown:   `{}`,
+			wantUnknown: `{"num": 1, "obj": {"k": "v"}}`,
+		},
+		{
+			name:        "known and unknown",
+			json:        `{"name": "foo", "num": 1, "list": [1, 2]}`,
+			wantKnown:   `{"name": "foo"}`,
+			wantUnknown: `{"num": 1, "list": [1, 2]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s S
+			require.NoError(t, json.Unmarshal([]byte(tt.json), &s))
+
+			known, unknown, err := s.raw.SplitJSON(&s)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.wantKnown, string(known))
+			assert.JSONEq(t, tt.wantUnknown, string(unknown))
+
+			merged, err := MergeJSON(known, unknown)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.json, string(merged))
+		})
+	}
+}
+
+func TestRetain_SplitJSON_ToJSONPath(t *testing.T) {
+	var s S
+	s.raw.Configure(WithStripPrefix("x-"), WithChecksumField("_sum"))
+	input := `{"x-b": 1.50, "name": "foo", "a": {"z": 1, "y": 2}}`
+	require.NoError(t, s.raw.FromJSON([]byte(input), &s, WithChecksumField("")))
+
+	known, unknown, err := s.raw.SplitJSON(&s)
+	require.NoError(t, err)
+	assert.Equal(t, `{"name":"foo","_sum":"95029f4d4e828ee6387837e7488f0d3f9d50df2a59ba6973ee18b1bc0a375ccb"}`, string(known))
+	assert.Equal(t, `{"x-b":1.50,"a":{"z": 1, "y": 2}}`, string(unknown), "retained keys have the prefix, and values are byte-exact")
+
+	merged, err := MergeJSON(known, unknown)
+	require.NoError(t, err)
+	want, err := s.raw.ToJSON(&s)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(want), string(merged))
+}
+
+func TestRetain_SplitJSON_Types(t *testing.T) {
+	var r Retain
+	_, _, err := r.SplitJSON("str")
+	assert.EqualError(t, err, "SplitJSON requires a struct, got string")
+}
+
+func TestMergeJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		known   string
+		unknown string
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "known wins on collision",
+			known:   `{"name": "known"}`,
+			unknown: `{"name": "unknown", "other": 1}`,
+			want:    `{"name": "known", "other": 1}`,
+		},
+		{
+			name:    "null unknown",
+			known:   `{"name": "known"}`,
+			unknown: `null`,
+			want:    `{"name": "known"}`,
+		},
+		{
+			name:    "invalid known",
+			known:   `[]`,
+			unknown: `{}`,
+			wantErr: "unmarshal known: json: cannot unmarshal array",
+		},
+		{
+			name:    "invalid unknown",
+			known:   `{}`,
+			unknown: `{`,
+			wantErr: "unmarshal unknown: unexpected end of JSON input",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MergeJSON([]byte(tt.known), []byte(tt.unknown))
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+}
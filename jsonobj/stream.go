@@ -0,0 +1,204 @@
+package jsonobj
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// RetainHolder is a retainable object, with UnmarshalJSON and MarshalJSON
+// methods that call FromJSON and ToJSON.
+type RetainHolder interface {
+	json.Marshaler
+	json.Unmarshaler
+}
+
+// RetainAccessor is implemented by objects without UnmarshalJSON and
+// MarshalJSON methods that hold unknown fields in an unexported Retain field,
+// so that Decoder and nested known fields (see FromJSON) can access it:
+//
+//	func (o *Obj) JSONRetain() *jsonobj.Retain { return &o.raw }
+//
+// Objects with an exported Retain field (such as an embedded Retain) don't
+// need to implement RetainAccessor.
+type RetainAccessor interface {
+	JSONRetain() *Retain
+}
+
+var retainAccessorType = reflect.TypeOf((*RetainAccessor)(nil)).Elem()
+
+// DecodeStream decodes JSON Lines (newline-delimited JSON) from rd, with one
+// object per line, each decoded into a new object from newObj. Decoded objects
+// are sent on the returned objects channel, and blank lines are skipped.
+//
+// Decoding stops at the first error (including an incomplete last line),
+// which is sent on the errors channel. Both channels are closed once decoding
+// stops, so callers should receive from the objects channel until it's closed,
+// and then receive from the errors channel to check for an error.
+//
+// Callers that stop receiving objects early must cancel ctx, so decoding stops
+// and the channels are closed, with ctx.Err() sent on the errors channel.
+// Cancellation is checked between lines, so a blocked read of rd isn't
+// interrupted.
+func DecodeStream(ctx context.Context, rd io.Reader, newObj func() RetainHolder) (<-chan RetainHolder, <-chan error) {
+	objs := make(chan RetainHolder)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(objs)
+
+		if err := decodeLines(ctx, bufio.NewReader(rd), newObj, objs); err != nil {
+			errs <- err
+		}
+	}()
+
+	return objs, errs
+}
+
+func decodeLines(ctx context.Context, br *bufio.Reader, newObj func() RetainHolder, objs chan<- RetainHolder) error {
+	for lineNum := 1; ; lineNum++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// ReadBytes is used rather than a Scanner to support lines of any length.
+		line, err := br.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+
+		if trimmed := bytes.TrimSpace(line); len(trimmed) > 0 {
+			obj := newObj()
+			if err := json.Unmarshal(trimmed, obj); err != nil {
+				return fmt.Errorf("line %v: %w", lineNum, err)
+			}
+			select {
+			case objs <- obj:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		if err != nil {
+			// io.EOF after the last line.
+			return nil
+		}
+	}
+}
+
+// Decoder reads and decodes a stream of JSON values, such as JSON Lines,
+// retaining the unknown fields of each object.
+type Decoder struct {
+	dec *json.Decoder
+}
+
+// NewDecoder returns a Decoder that reads from rd.
+func NewDecoder(rd io.Reader) *Decoder {
+	return &Decoder{dec: json.NewDecoder(rd)}
+}
+
+// More returns whether there's another value to decode.
+func (d *Decoder) More() bool {
+	return d.dec.More()
+}
+
+// Decode reads the next JSON value and decodes it into obj, which must be
+// a struct pointer. If obj implements json.Unmarshaler (such as a
+// RetainHolder), its UnmarshalJSON method is used. Otherwise, obj must have
+// an exported Retain field (such as an embedded Retain) or implement
+// RetainAccessor, and FromJSON is called on its Retain to retain unknown
+// fields.
+//
+// As with json.Decoder, Decode returns io.EOF at the end of the input.
+func (d *Decoder) Decode(obj any) error {
+	if u, ok := obj.(json.Unmarshaler); ok {
+		var data json.RawMessage
+		if err := d.dec.Decode(&data); err != nil {
+			return err
+		}
+		return u.UnmarshalJSON(data)
+	}
+
+	rv, ok := ensureStruct(obj, true /* requirePtr */)
+	if !ok {
+		return fmt.Errorf("Decode requires a struct pointer, got %T", obj)
+	}
+	r, ok := retainField(rv)
+	if !ok {
+		return fmt.Errorf("Decode requires %T to have an exported Retain field, JSONRetain method or UnmarshalJSON method", obj)
+	}
+
+	var data json.RawMessage
+	if err := d.dec.Decode(&data); err != nil {
+		return err
+	}
+	return r.FromJSON(data, obj)
+}
+
+// retainer is implemented by Retain and types embedding it.
+type retainer interface {
+	FromJSON(data []byte, obj any, opts ...Option) error
+	ToJSON(obj any, opts ...Option) ([]byte, error)
+}
+
+// retainField returns the Retain of the addressable struct rv: the result of
+// its JSONRetain method (see RetainAccessor), or otherwise its first exported
+// field that holds unknown fields, such as an embedded Retain. Unexported
+// fields aren't accessed, so their types must implement RetainAccessor.
+func retainField(rv reflect.Value) (retainer, bool) {
+	if a, ok := rv.Addr().Interface().(RetainAccessor); ok {
+		return a.JSONRetain(), true
+	}
+
+	f, ok := exportedRetainField(rv.Type())
+	if !ok {
+		return nil, false
+	}
+	r, ok := rv.Field(f).Addr().Interface().(retainer)
+	return r, ok
+}
+
+// retainOf returns the Retain found by retainField, which is embedded in
+// types such as Extensions.
+func retainOf(rv reflect.Value) (*Retain, bool) {
+	if a, ok := rv.Addr().Interface().(RetainAccessor); ok {
+		return a.JSONRetain(), true
+	}
+
+	f, ok := exportedRetainField(rv.Type())
+	if !ok {
+		return nil, false
+	}
+	fv := rv.Field(f)
+	if fv.Type() != retainType {
+		fv = fv.FieldByName("Retain")
+	}
+	return fv.Addr().Interface().(*Retain), true
+}
+
+// exportedRetainField returns the index of the first exported field of the
+// struct st that holds unknown fields, see isRetainType.
+func exportedRetainField(st reflect.Type) (int, bool) {
+	for f := 0; f < st.NumField(); f++ {
+		if ft := st.Field(f); ft.IsExported() && isRetainType(ft.Type) {
+			return f, true
+		}
+	}
+	return 0, false
+}
+
+// hasAccessibleRetain returns whether retainField finds the Retain of values
+// of the struct st.
+func hasAccessibleRetain(st reflect.Type) bool {
+	if reflect.PointerTo(st).Implements(retainAccessorType) {
+		return true
+	}
+	_, ok := exportedRetainField(st)
+	return ok
+}
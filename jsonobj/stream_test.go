@@ -0,0 +1,248 @@
+package jsonobj
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeStream(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		wantErr string
+	}{
+		{
+			name:  "empty",
+			input: "",
+		},
+		{
+			name:  "lines",
+			input: `{"name": "a", "x": 1}` + "\n" + `{"name": "b"}` + "\n",
+			want:  []string{`{"name": "a", "x": 1}`, `{"name": "b"}`},
+		},
+		{
+			name:  "no trailing newline",
+			input: `{"name": "a"}` + "\n" + `{"name": "b", "y": [1]}`,
+			want:  []string{`{"name": "a"}`, `{"name": "b", "y": [1]}`},
+		},
+		{
+			name:  "blank lines and CRLF",
+			input: "\n" + `{"name": "a"}` + "\r\n  \r\n\n" + `{"name": "b"}` + "\r\n",
+			want:  []string{`{"name": "a"}`, `{"name": "b"}`},
+		},
+		{
+			name:    "partial last line",
+			input:   `{"name": "a"}` + "\n" + `{"name": "b`,
+			want:    []string{`{"name": "a"}`},
+			wantErr: "line 2: unexpected end of JSON input",
+		},
+		{
+			name:    "invalid line stops decoding",
+			input:   `{"name": "a"}` + "\n\n" + `[]` + "\n" + `{"name": "c"}`,
+			want:    []string{`{"name": "a"}`},
+			wantErr: "line 3: json: cannot unmarshal array",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objs, errs := DecodeStream(context.Background(), strings.NewReader(tt.input), func() RetainHolder {
+				return &S{}
+			})
+
+			var got []string
+			for obj := range objs {
+				got = append(got, mustMarshal(t, obj))
+			}
+
+			require.Len(t, got, len(tt.want))
+			for i := range got {
+				assert.JSONEq(t, tt.want[i], got[i])
+			}
+
+			err := <-errs
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			_, ok := <-errs
+			assert.False(t, ok, "errors channel should be closed")
+		})
+	}
+}
+
+func TestDecodeStream_ReadError(t *testing.T) {
+	readErr := errors.New("read failed")
+	objs, errs := DecodeStream(context.Background(), iotest.ErrReader(readErr), func() RetainHolder {
+		return &S{}
+	})
+
+	for range objs {
+		t.Fatal("unexpected object")
+	}
+	assert.ErrorIs(t, <-errs, readErr)
+}
+
+func TestDecodeStream_Cancel(t *testing.T) {
+	// An endless stream, so decoding only stops if it's cancelled.
+	line := strings.NewReader(`{"name": "a"}` + "\n")
+	endless := iotest.OneByteReader(readerFunc(func(p []byte) (int, error) {
+		if line.Len() == 0 {
+			line.Seek(0, io.SeekStart)
+		}
+		return line.Read(p)
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	objs, errs := DecodeStream(ctx, endless, func() RetainHolder {
+		return &S{}
+	})
+
+	obj := <-objs
+	assert.Equal(t, `{"name":"a"}`, mustMarshal(t, obj))
+
+	// Stop receiving objects, which would block decoding without cancelling.
+	cancel()
+	assert.ErrorIs(t, <-errs, context.Canceled)
+
+	_, ok := <-objs
+	assert.False(t, ok, "objects channel should be closed")
+}
+
+type readerFunc func(p []byte) (int, error)
+
+func (f readerFunc) Read(p []byte) (int, error) {
+	return f(p)
+}
+
+// noMethodsS embeds Retain, but has no UnmarshalJSON or MarshalJSON methods.
+type noMethodsS struct {
+	Retain
+	Name string `json:"name"`
+}
+
+// accessorS has an unexported Retain field, accessed using JSONRetain.
+type accessorS struct {
+	raw  Retain
+	Name string `json:"name"`
+}
+
+func (s *accessorS) JSONRetain() *Retain {
+	return &s.raw
+}
+
+func TestDecoder(t *testing.T) {
+	input := `{"name": "a", "x": 1}
+{"name": "b"}
+{"name": "c", "y": [1,2]}`
+
+	tests := []struct {
+		name   string
+		newObj func() any
+		toJSON func(t testing.TB, obj any) string
+	}{
+		{
+			name:   "UnmarshalJSON method",
+			newObj: func() any { return &S{} },
+			toJSON: mustMarshal,
+		},
+		{
+			name:   "embedded Retain",
+			newObj: func() any { return &noMethodsS{} },
+			toJSON: func(t testing.TB, obj any) string {
+				got, err := obj.(*noMethodsS).ToJSON(obj)
+				require.NoError(t, err)
+				return string(got)
+			},
+		},
+		{
+			name:   "JSONRetain method",
+			newObj: func() any { return &accessorS{} },
+			toJSON: func(t testing.TB, obj any) string {
+				got, err := obj.(*accessorS).raw.ToJSON(obj)
+				require.NoError(t, err)
+				return string(got)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dec := NewDecoder(strings.NewReader(input))
+
+			var got []string
+			for dec.More() {
+				obj := tt.newObj()
+				require.NoError(t, dec.Decode(obj))
+				got = append(got, tt.toJSON(t, obj))
+			}
+			assert.Equal(t, []string{
+				`{"name":"a","x":1}`,
+				`{"name":"b"}`,
+				`{"name":"c","y":[1,2]}`,
+			}, got)
+
+			assert.Equal(t, io.EOF, dec.Decode(tt.newObj()))
+		})
+	}
+}
+
+func TestDecoder_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		obj     any
+		wantErr string
+	}{
+		{
+			name:    "not a struct pointer",
+			input:   `{}`,
+			obj:     map[string]any{},
+			wantErr: "Decode requires a struct pointer, got map[string]interface {}",
+		},
+		{
+			name:    "no Retain field",
+			input:   `{}`,
+			obj:     &struct{ Name string }{},
+			wantErr: "to have an exported Retain field, JSONRetain method or UnmarshalJSON method",
+		},
+		{
+			name:  "unexported Retain field",
+			input: `{}`,
+			obj: &struct {
+				raw  Retain
+				Name string
+			}{},
+			wantErr: "to have an exported Retain field, JSONRetain method or UnmarshalJSON method",
+		},
+		{
+			name:    "invalid JSON",
+			input:   `{"name": }`,
+			obj:     &S{},
+			wantErr: "invalid character",
+		},
+		{
+			name:    "not an object",
+			input:   `[1]`,
+			obj:     &S{},
+			wantErr: "cannot unmarshal array",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewDecoder(strings.NewReader(tt.input)).Decode(tt.obj)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
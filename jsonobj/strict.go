@@ -0,0 +1,103 @@
+package jsonobj
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// WithDisallowUnknownFields makes FromJSON fail with an *UnknownFieldsError
+// if the input has any keys that don't match known fields, rather than
+// retaining them. This is similar to json.Decoder.DisallowUnknownFields,
+// but all unknown keys are reported.
+//
+// It can be passed to FromJSON for a single call, so the same type can be
+// decoded strictly where unknown fields must be rejected (such as to catch
+// typos in requests), and retain them elsewhere.
+func WithDisallowUnknownFields() Option {
+	return func(o *options) {
+		o.disallowUnknown = true
+	}
+}
+
+// WithDisallowDuplicateKeys makes FromJSON fail with a *DuplicateKeyError
+// if the input object has a duplicate key, such as {"a": 1, "a": 2}, rather
+// than using the last value as encoding/json does. Only the keys of the
+// top-level object (where known and unknown fields are split) are checked.
+func WithDisallowDuplicateKeys() Option {
+	return func(o *options) {
+		o.disallowDuplicates = true
+	}
+}
+
+// WithDisallowTrailingData makes FromJSON fail if the input has any data
+// other than whitespace after the JSON object, such as a second object
+// appended to the input. encoding/json already rejects trailing data, but
+// with a less specific error, and a Codec may not (see WithCodec).
+func WithDisallowTrailingData() Option {
+	return func(o *options) {
+		o.disallowTrailing = true
+	}
+}
+
+// checkTrailingData returns an error if data has anything other than
+// whitespace after the first JSON value. Invalid values are reported when
+// decoding.
+func checkTrailingData(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var skip json.RawMessage
+	if err := dec.Decode(&skip); err != nil {
+		return nil
+	}
+
+	offset := dec.InputOffset()
+	if _, err := dec.Token(); err != io.EOF {
+		return fmt.Errorf("unexpected trailing data after JSON object at offset %v", offset)
+	}
+	return nil
+}
+
+// DuplicateKeyError is returned by FromJSON when the input has a duplicate
+// key and WithDisallowDuplicateKeys is used.
+type DuplicateKeyError struct {
+	// Key is the first key that's duplicated in the input.
+	Key string
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return "duplicate key " + strconv.Quote(e.Key)
+}
+
+// UnknownFieldsError is returned by FromJSON when the input has unknown
+// fields and WithDisallowUnknownFields is used.
+type UnknownFieldsError struct {
+	// Fields are the sorted unknown keys of the input.
+	Fields []string
+}
+
+func (e *UnknownFieldsError) Error() string {
+	quoted := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		quoted[i] = strconv.Quote(f)
+	}
+	return "unknown fields: " + strings.Join(quoted, ", ")
+}
+
+// checkUnknown returns an error if there are unknown fields that are
+// disallowed.
+func (r *Retain) checkUnknown() error {
+	if !r.opts.disallowUnknown || len(r.raw) == 0 {
+		return nil
+	}
+
+	fields := make([]string, 0, len(r.raw))
+	for k := range r.raw {
+		fields = append(fields, k)
+	}
+	sort.Strings(fields)
+	return &UnknownFieldsError{Fields: fields}
+}
@@ -0,0 +1,167 @@
+package jsonobj
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithDisallowUnknownFields(t *testing.T) {
+	tests := []struct {
+		name       string
+		json       string
+		wantFields []string
+		wantErr    string
+	}{
+		{
+			name: "only known fields",
+			json: `{"name": "foo"}`,
+		},
+		{
+			name:       "unknown fields",
+			json:       `{"nmae": "foo", "name": "foo", "icon": "email", "Name": "bar"}`,
+			wantFields: []string{"icon", "nmae"},
+			wantErr:    `unknown fields: "icon", "nmae"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s S
+			err := s.raw.FromJSON([]byte(tt.json), &s, WithDisallowUnknownFields())
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				assert.Equal(t, "foo", s.Name)
+				return
+			}
+
+			require.Error(t, err)
+			assert.EqualError(t, err, tt.wantErr)
+
+			var unknownErr *UnknownFieldsError
+			require.True(t, errors.As(err, &unknownErr))
+			assert.Equal(t, tt.wantFields, unknownErr.Fields)
+		})
+	}
+}
+
+func TestWithDisallowUnknownFields_PerCall(t *testing.T) {
+	input := []byte(`{"name": "foo", "icon": "email"}`)
+
+	var s S
+	require.Error(t, s.raw.FromJSON(input, &s, WithDisallowUnknownFields()))
+	require.NoError(t, s.raw.FromJSON(input, &s), "retain unknown fields without the option")
+	assert.Equal(t, []string{"icon"}, s.raw.UnknownKeys())
+}
+
+func TestWithDisallowDuplicateKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		wantKey string
+	}{
+		{
+			name: "no duplicates",
+			json: `{"name": "foo", "icon": "email", "Name": "bar"}`,
+		},
+		{
+			name: "nested duplicates are not checked",
+			json: `{"name": "foo", "meta": {"a": 1, "a": 2}}`,
+		},
+		{
+			name:    "duplicate known field",
+			json:    `{"name": "a", "name": "b"}`,
+			wantKey: "name",
+		},
+		{
+			name:    "duplicate unknown field",
+			json:    `{"icon": "a", "name": "foo", "icon": "b", "x": 1, "x": 2}`,
+			wantKey: "icon",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s S
+			err := s.raw.FromJSON([]byte(tt.json), &s, WithDisallowDuplicateKeys())
+			if tt.wantKey == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			var dupErr *DuplicateKeyError
+			require.True(t, errors.As(err, &dupErr), "expected DuplicateKeyError, got %v", err)
+			assert.Equal(t, tt.wantKey, dupErr.Key)
+			assert.EqualError(t, err, `duplicate key "`+tt.wantKey+`"`)
+
+			var lenient S
+			require.NoError(t, lenient.raw.FromJSON([]byte(tt.json), &lenient), "duplicates are allowed by default")
+		})
+	}
+}
+
+// firstValueCodec is a Codec that only decodes the first JSON value of its
+// input, ignoring any trailing data.
+type firstValueCodec struct{}
+
+func (firstValueCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (firstValueCodec) Unmarshal(data []byte, v any) error {
+	return json.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+func TestWithDisallowTrailingData(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		wantErr string
+	}{
+		{
+			name: "trailing whitespace",
+			json: "{\"name\": \"foo\"} \n\t",
+		},
+		{
+			name:    "trailing garbage",
+			json:    `{"name": "foo"} garbage`,
+			wantErr: "unexpected trailing data after JSON object at offset 15",
+		},
+		{
+			name:    "second object",
+			json:    `{"name": "foo"}{"name": "bar"}`,
+			wantErr: "unexpected trailing data after JSON object at offset 15",
+		},
+		{
+			name:    "invalid object",
+			json:    `{"name": } garbage`,
+			wantErr: "invalid character",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, opts := range [][]Option{
+				{WithDisallowTrailingData()},
+				{WithDisallowTrailingData(), WithCodec(firstValueCodec{})},
+			} {
+				var s S
+				err := s.raw.FromJSON([]byte(tt.json), &s, opts...)
+				if tt.wantErr == "" {
+					require.NoError(t, err)
+					assert.Equal(t, "foo", s.Name)
+					continue
+				}
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+
+	var s S
+	require.NoError(t, s.raw.FromJSON([]byte(`{"name": "foo"} garbage`), &s, WithCodec(firstValueCodec{})),
+		"trailing data is only rejected by the codec by default")
+}
@@ -0,0 +1,72 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// stringTagOption is the json tag option that encodes a field as a JSON
+// string containing its JSON value.
+const stringTagOption = "string"
+
+// canQuote returns whether the `,string` option applies to the type t,
+// which matches encoding/json: scalar types, and unnamed pointers to them.
+func canQuote(t reflect.Type) bool {
+	if t.Name() == "" && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.String:
+		return true
+	default:
+		return false
+	}
+}
+
+// encodeQuoted returns the JSON value of v, quoted as a JSON string.
+// As with encoding/json, nil pointers are encoded as null.
+func encodeQuoted(v reflect.Value) (json.RawMessage, error) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return json.RawMessage("null"), nil
+		}
+		v = v.Elem()
+	}
+
+	b, err := json.Marshal(v.Interface())
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(b))
+}
+
+// decodeQuoted decodes fieldJSON, a JSON string containing a JSON value,
+// into v. As with encoding/json, null (or a quoted null) sets pointers to nil,
+// and leaves other values unchanged.
+func decodeQuoted(t jsonTag, fieldJSON json.RawMessage, v reflect.Value) error {
+	if string(fieldJSON) == "null" {
+		if v.Kind() == reflect.Pointer {
+			v.SetZero()
+		}
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(fieldJSON, &s); err != nil {
+		return fmt.Errorf("field %q: invalid use of ,string tag, trying to unmarshal %s into %v", t.name(), fieldJSON, v.Type())
+	}
+	if s == "null" && v.Kind() == reflect.Pointer {
+		v.SetZero()
+		return nil
+	}
+	if err := json.Unmarshal([]byte(s), v.Addr().Interface()); err != nil {
+		return fmt.Errorf("field %q: invalid use of ,string tag, trying to unmarshal %q into %v", t.name(), s, v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stringTagS struct {
+	raw Retain
+
+	ID      int64   `json:"id,string"`
+	Count   uint8   `json:"count,omitempty,string"`
+	Ratio   float64 `json:"ratio,string"`
+	Enabled bool    `json:"enabled,string"`
+	Name    string  `json:"name,string"`
+}
+
+func (s *stringTagS) UnmarshalJSON(data []byte) error {
+	return s.raw.FromJSON(data, s)
+}
+
+func (s *stringTagS) MarshalJSON() ([]byte, error) {
+	return s.raw.ToJSON(s)
+}
+
+func TestStringTag(t *testing.T) {
+	require.NoError(t, Retainable(&stringTagS{}))
+
+	tests := []struct {
+		name    string
+		json    string
+		want    stringTagS
+		wantOut string
+		wantErr string
+	}{
+		{
+			name: "quoted values",
+			json: `{"id": "9007199254740993", "count": "3", "ratio": "0.5", "enabled": "true", "name": "\"foo\"", "extra": 1}`,
+			want: stringTagS{
+				ID:      9007199254740993,
+				Count:   3,
+				Ratio:   0.5,
+				Enabled: true,
+				Name:    "foo",
+			},
+			wantOut: `{"id":"9007199254740993","count":"3","ratio":"0.5","enabled":"true","name":"\"foo\"","extra":1}`,
+		},
+		{
+			name:    "null and omitempty",
+			json:    `{"id": null, "count": "0"}`,
+			wantOut: `{"id":"0","ratio":"0","enabled":"false","name":"\"\""}`,
+		},
+		{
+			name:    "unquoted number",
+			json:    `{"id": 1}`,
+			wantErr: `field "id": invalid use of ,string tag, trying to unmarshal 1 into int64`,
+		},
+		{
+			name:    "invalid quoted value",
+			json:    `{"enabled": "yes"}`,
+			wantErr: `field "enabled": invalid use of ,string tag, trying to unmarshal "yes" into bool`,
+		},
+		{
+			name:    "string not quoted twice",
+			json:    `{"name": "foo"}`,
+			wantErr: `field "name": invalid use of ,string tag, trying to unmarshal "foo" into string`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got stringTagS
+			err := json.Unmarshal([]byte(tt.json), &got)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			tt.want.raw = got.raw
+			assert.Equal(t, tt.want, got)
+
+			out, err := json.Marshal(&got)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantOut, string(out))
+		})
+	}
+}
+
+func TestStringTag_MatchesEncodingJSON(t *testing.T) {
+	type plain struct {
+		ID      int64   `json:"id,string"`
+		Count   uint8   `json:"count,omitempty,string"`
+		Ratio   float64 `json:"ratio,string"`
+		Enabled bool    `json:"enabled,string"`
+		Name    string  `json:"name,string"`
+	}
+
+	s := stringTagS{
+		ID:      math.MaxInt64,
+		Count:   math.MaxUint8,
+		Ratio:   1e21,
+		Enabled: true,
+		Name:    `quote " and <html>`,
+	}
+	p := plain{s.ID, s.Count, s.Ratio, s.Enabled, s.Name}
+	assert.Equal(t, mustMarshal(t, p), mustMarshal(t, &s))
+
+	var got stringTagS
+	require.NoError(t, json.Unmarshal([]byte(mustMarshal(t, p)), &got))
+	s.raw = got.raw
+	assert.Equal(t, s, got)
+}
+
+func TestStringTag_Pointers(t *testing.T) {
+	type plain struct {
+		N *int    `json:"n,string"`
+		B *bool   `json:"b,omitempty,string"`
+		S *string `json:"s,string"`
+	}
+
+	t.Run("encode", func(t *testing.T) {
+		n, b := 5, true
+		for _, p := range []plain{{}, {N: &n, B: &b}} {
+			var r Retain
+			got, err := r.ToJSON(p)
+			require.NoError(t, err)
+			assert.Equal(t, mustMarshal(t, p), string(got))
+		}
+	})
+
+	t.Run("decode", func(t *testing.T) {
+		inputs := []string{
+			`{"n": "5", "b": "true", "s": "\"x\""}`,
+			`{"n": null, "b": "null"}`,
+			`{}`,
+		}
+		for _, input := range inputs {
+			n := 1
+			want := plain{N: &n}
+			require.NoError(t, json.Unmarshal([]byte(input), &want))
+
+			var r Retain
+			m := 1
+			got := plain{N: &m}
+			require.NoError(t, r.FromJSON([]byte(input), &got))
+			assert.Equal(t, want, got, "input: %v", input)
+		}
+	})
+}
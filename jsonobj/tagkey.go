@@ -0,0 +1,32 @@
+package jsonobj
+
+// defaultTagKey is the struct tag key used for JSON field names and options,
+// as with encoding/json.
+const defaultTagKey = "json"
+
+// WithTagKey makes FromJSON, ToJSON and Retainable read the names and options
+// (such as omitempty and "-") of known fields from the struct tag key, rather
+// than the `json` tag. For example, with WithTagKey("api"), the field:
+//
+//	Title string `api:"title,omitempty"`
+//
+// is decoded from and encoded as "title". This applies to the known fields of
+// the object (including promoted fields, prefix fields and Extensions fields),
+// but nested values are encoded using encoding/json, so they use `json` tags.
+func WithTagKey(key string) Option {
+	return func(o *options) {
+		o.tagKey = key
+	}
+}
+
+// tagKey returns the configured struct tag key.
+func (r *Retain) tagKey() string {
+	return r.opts.structTagKey()
+}
+
+func (o options) structTagKey() string {
+	if o.tagKey == "" {
+		return defaultTagKey
+	}
+	return o.tagKey
+}
@@ -0,0 +1,85 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type apiTagS struct {
+	raw Retain
+
+	Title   string `api:"title,omitempty" json:"jsonTitle"`
+	Count   int    `api:"count,omitzero"`
+	Ignored string `api:"-" json:"ignored"`
+	Untag   string `json:"untag"`
+}
+
+func (s *apiTagS) UnmarshalJSON(data []byte) error {
+	s.raw.Configure(WithTagKey("api"))
+	return s.raw.FromJSON(data, s)
+}
+
+func (s *apiTagS) MarshalJSON() ([]byte, error) {
+	return s.raw.ToJSON(s)
+}
+
+var _ = MustRetainable(&apiTagS{}, WithTagKey("api"))
+
+func TestWithTagKey(t *testing.T) {
+	var s apiTagS
+	require.NoError(t, json.Unmarshal([]byte(`{"title":"t","jsonTitle":"j","count":2,"ignored":"i","Untag":"u"}`), &s))
+	assert.Equal(t, apiTagS{raw: s.raw, Title: "t", Count: 2, Untag: "u"}, s)
+	assert.Equal(t, []string{"ignored", "jsonTitle"}, s.raw.UnknownKeys())
+	assert.Equal(t, `{"title":"t","jsonTitle":"j","count":2,"ignored":"i","Untag":"u"}`, mustMarshal(t, &s))
+
+	s = apiTagS{}
+	s.raw.Configure(WithTagKey("api"))
+	assert.Equal(t, `{"Untag":""}`, mustMarshal(t, &s), "omitempty and omitzero use the api tag")
+}
+
+func TestWithTagKey_Retainable(t *testing.T) {
+	tests := []struct {
+		name string
+		obj  interface {
+			json.Marshaler
+			json.Unmarshaler
+		}
+		opts    []Option
+		wantErr string
+	}{
+		{
+			name: "json tags",
+			obj:  &apiTagS{},
+		},
+		{
+			name:    "unsupported api tag",
+			obj:     &unsupportedAPITagS{},
+			opts:    []Option{WithTagKey("api")},
+			wantErr: `field "name" has unsupported tag "inline"`,
+		},
+		{
+			name: "unsupported api tag ignored without option",
+			obj:  &unsupportedAPITagS{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Retainable(tt.obj, tt.opts...)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+type unsupportedAPITagS struct {
+	S
+
+	Name string `api:"name,inline"`
+}
@@ -0,0 +1,62 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ToMap returns the fields that ToJSON would output for obj, both known and
+// retained, with each value encoded as JSON. It's intended for transforming
+// objects as maps without encoding and decoding the whole object, and the
+// result can be decoded using FromMap.
+//
+// Options that format the output (WithEnvelopeKey, WithOutputSchema and
+// WithIndent) are not used. The returned map and its values are owned by the
+// caller.
+func (r *Retain) ToMap(obj any) (map[string]json.RawMessage, error) {
+	keys, all, err := r.outputFields("ToMap", obj)
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]json.RawMessage, len(keys))
+	for _, k := range keys {
+		if raw, ok := all[k].(json.RawMessage); ok {
+			m[k] = slices.Clone(raw)
+			continue
+		}
+
+		data, err := r.marshal(all[k])
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k, err)
+		}
+		m[k] = data
+	}
+	return m, nil
+}
+
+// FromMap is similar to FromJSON, but decodes the fields of m, such as the
+// result of ToMap. Known fields are decoded from their values, and all other
+// fields are retained.
+//
+// Since m has no order, retained fields are output by ToJSON after known
+// fields, sorted. Options that apply to the whole input (WithEnvelopeKey,
+// WithWorkLimit and WithDisallowDuplicateKeys) are not used, and the rawinput
+// field is not set. m is not modified or retained.
+func (r *Retain) FromMap(m map[string]json.RawMessage, obj any) error {
+	if isNilObj(obj) {
+		return errors.New("FromMap requires a non-nil struct pointer")
+	}
+	rv, ok := ensureStruct(obj, true /* requirePtr */)
+	if !ok {
+		return fmt.Errorf("FromMap requires a struct pointer, got %T", obj)
+	}
+	defer r.resetConsumed()
+
+	prev := r.retained()
+	r.raw = cloneRawMap(m)
+	r.order = nil
+	return r.decodeRetained(rv, prev)
+}
@@ -0,0 +1,67 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetain_ToMap(t *testing.T) {
+	var s S
+	require.NoError(t, s.raw.FromJSON([]byte(`{"z": [1, 2], "name": "foo", "a": {"b": true}}`), &s))
+
+	m, err := s.raw.ToMap(&s)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]json.RawMessage{
+		"z":    json.RawMessage(`[1, 2]`),
+		"name": json.RawMessage(`"foo"`),
+		"a":    json.RawMessage(`{"b": true}`),
+	}, m)
+
+	// The map is owned by the caller.
+	m["z"][1] = '9'
+	z, ok := s.raw.GetUnknown("z")
+	require.True(t, ok)
+	assert.Equal(t, `[1, 2]`, string(z))
+}
+
+func TestRetain_FromMap(t *testing.T) {
+	m := map[string]json.RawMessage{
+		"name": json.RawMessage(`"foo"`),
+		"z":    json.RawMessage(`1`),
+		"a":    json.RawMessage(`{"b": true}`),
+	}
+
+	var s S
+	require.NoError(t, s.raw.FromMap(m, &s))
+	assert.Equal(t, "foo", s.Name)
+	assert.Equal(t, []string{"a", "z"}, s.raw.UnknownKeys())
+	assert.Len(t, m, 3, "FromMap should not modify the map")
+
+	got, err := s.raw.ToJSON(&s)
+	require.NoError(t, err)
+	assert.Equal(t, `{"name":"foo","a":{"b": true},"z":1}`, string(got))
+
+	// Round-trip through ToMap, transforming the map.
+	m2, err := s.raw.ToMap(&s)
+	require.NoError(t, err)
+	m2["name"] = json.RawMessage(`"bar"`)
+	delete(m2, "z")
+
+	var s2 S
+	require.NoError(t, s2.raw.FromMap(m2, &s2))
+	assert.Equal(t, "bar", s2.Name)
+	assert.Equal(t, []string{"a"}, s2.raw.UnknownKeys())
+}
+
+func TestRetain_FromMap_Errors(t *testing.T) {
+	var s S
+	assert.EqualError(t, s.raw.FromMap(nil, s), "FromMap requires a struct pointer, got jsonobj.S")
+	assert.EqualError(t, s.raw.FromMap(nil, (*S)(nil)), "FromMap requires a non-nil struct pointer")
+	assert.ErrorContains(t, s.raw.FromMap(map[string]json.RawMessage{"name": json.RawMessage(`1`)}, &s), `field "name" (Name): json: cannot unmarshal number`)
+
+	_, err := s.raw.ToMap("str")
+	assert.EqualError(t, err, "ToMap requires a struct, got string")
+}
@@ -0,0 +1,77 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// matchRule describes how an input key was matched to a known field.
+type matchRule string
+
+const (
+	matchExact  matchRule = "exact"
+	matchFold   matchRule = "case-insensitive"
+	matchPrefix matchRule = "prefix"
+	matchAlias  matchRule = "alias"
+)
+
+// WithDecodeTrace writes a trace of decode decisions made by FromJSON to w,
+// with a line per input key describing whether it matched a known field
+// (and the rule used to match it), or was retained.
+//
+// Tracing is intended for debugging, and is disabled by default.
+func WithDecodeTrace(w io.Writer) Option {
+	return func(o *options) {
+		o.trace = w
+	}
+}
+
+// decodeTrace records decode decisions for WithDecodeTrace.
+// A nil *decodeTrace is valid, and records nothing.
+type decodeTrace struct {
+	w       io.Writer
+	keys    []string
+	matches map[string]string
+}
+
+func newDecodeTrace(w io.Writer, input map[string]json.RawMessage) *decodeTrace {
+	if w == nil {
+		return nil
+	}
+
+	keys := make([]string, 0, len(input))
+	for k := range input {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return &decodeTrace{
+		w:       w,
+		keys:    keys,
+		matches: make(map[string]string),
+	}
+}
+
+func (t *decodeTrace) matched(key, field string, rule matchRule) {
+	if t == nil {
+		return
+	}
+	t.matches[key] = fmt.Sprintf("known field %v (%v)", field, rule)
+}
+
+func (t *decodeTrace) write() {
+	if t == nil {
+		return
+	}
+
+	// Tracing is best-effort, so write errors are ignored.
+	for _, k := range t.keys {
+		decision, ok := t.matches[k]
+		if !ok {
+			decision = "retained"
+		}
+		fmt.Fprintf(t.w, "jsonobj: key %q: %v\n", k, decision)
+	}
+}
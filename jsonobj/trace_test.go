@@ -0,0 +1,28 @@
+package jsonobj
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithDecodeTrace(t *testing.T) {
+	var (
+		buf strings.Builder
+		s   S
+	)
+	s.raw.Configure(WithDecodeTrace(&buf))
+
+	err := s.raw.FromJSON([]byte(`{"name": "foo", "num": 1, "Name": "bar"}`), &s)
+	require.NoError(t, err)
+
+	want := strings.Join([]string{
+		`jsonobj: key "Name": known field Name (case-insensitive)`,
+		`jsonobj: key "name": known field Name (exact)`,
+		`jsonobj: key "num": retained`,
+		``,
+	}, "\n")
+	assert.Equal(t, want, buf.String())
+}
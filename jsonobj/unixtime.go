@@ -0,0 +1,76 @@
+package jsonobj
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// WithUnixTime encodes known time.Time fields as Unix epoch numbers in the
+// given unit, which must be time.Second or time.Millisecond. Fractional
+// values are used for sub-unit precision.
+//
+// FromJSON accepts either epoch numbers or the default RFC 3339 strings for
+// time.Time fields, and decoded epoch times are in UTC.
+func WithUnixTime(unit time.Duration) Option {
+	if unit != time.Second && unit != time.Millisecond {
+		panic(fmt.Sprintf("jsonobj: WithUnixTime requires time.Second or time.Millisecond, got %v", unit))
+	}
+
+	return func(o *options) {
+		o.unixTime = unit
+	}
+}
+
+func isJSONNumber(data json.RawMessage) bool {
+	trimmed := bytes.TrimSpace(data)
+	return len(trimmed) > 0 && (trimmed[0] == '-' || (trimmed[0] >= '0' && trimmed[0] <= '9'))
+}
+
+// unixTimeFromJSON converts a JSON number of units since the epoch to a time.
+// big.Rat is used so fractional and large values are converted exactly.
+func unixTimeFromJSON(data json.RawMessage, unit time.Duration) (time.Time, error) {
+	var num json.Number
+	if err := json.Unmarshal(data, &num); err != nil {
+		return time.Time{}, err
+	}
+
+	epoch, ok := new(big.Rat).SetString(num.String())
+	if !ok {
+		return time.Time{}, fmt.Errorf("invalid epoch time %v", num)
+	}
+
+	// Convert to nanoseconds, rounding to the nearest nanosecond.
+	nanos := epoch.Mul(epoch, new(big.Rat).SetInt64(int64(unit)))
+	rounded := new(big.Int).Quo(nanos.Num(), nanos.Denom())
+	if rem := new(big.Rat).Sub(nanos, new(big.Rat).SetInt(rounded)); rem.Abs(rem).Cmp(big.NewRat(1, 2)) >= 0 {
+		rounded.Add(rounded, big.NewInt(int64(nanos.Sign())))
+	}
+
+	sec, nsec := new(big.Int).DivMod(rounded, big.NewInt(int64(time.Second)), new(big.Int))
+	if !sec.IsInt64() {
+		return time.Time{}, fmt.Errorf("epoch time %v out of range", num)
+	}
+	return time.Unix(sec.Int64(), nsec.Int64()).UTC(), nil
+}
+
+// unixTimeJSON returns t as a JSON number of units since the epoch.
+func unixTimeJSON(t time.Time, unit time.Duration) json.RawMessage {
+	nanos := new(big.Int).Mul(big.NewInt(t.Unix()), big.NewInt(int64(time.Second)))
+	nanos.Add(nanos, big.NewInt(int64(t.Nanosecond())))
+
+	epoch := new(big.Rat).SetFrac(nanos, big.NewInt(int64(unit)))
+	if epoch.IsInt() {
+		return json.RawMessage(epoch.Num().String())
+	}
+
+	// Units are at most 1e9ns, so 9 decimal places is exact.
+	s := strings.TrimRight(epoch.FloatString(9), "0")
+	return json.RawMessage(s)
+}
@@ -0,0 +1,116 @@
+package jsonobj
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithUnixTime(t *testing.T) {
+	type Event struct {
+		At   time.Time `json:"at"`
+		Name string    `json:"name"`
+	}
+
+	tests := []struct {
+		name    string
+		unit    time.Duration
+		json    string
+		want    time.Time
+		wantOut string
+	}{
+		{
+			name:    "seconds",
+			unit:    time.Second,
+			json:    `{"at": 1700000000}`,
+			want:    time.Unix(1700000000, 0),
+			wantOut: `{"at": 1700000000, "name": ""}`,
+		},
+		{
+			name:    "fractional seconds",
+			unit:    time.Second,
+			json:    `{"at": 1700000000.123456789}`,
+			want:    time.Unix(1700000000, 123456789),
+			wantOut: `{"at": 1700000000.123456789, "name": ""}`,
+		},
+		{
+			name:    "exponent seconds",
+			unit:    time.Second,
+			json:    `{"at": 1.7e9}`,
+			want:    time.Unix(1700000000, 0),
+			wantOut: `{"at": 1700000000, "name": ""}`,
+		},
+		{
+			name:    "negative fractional seconds",
+			unit:    time.Second,
+			json:    `{"at": -1.5}`,
+			want:    time.Unix(-2, 500000000),
+			wantOut: `{"at": -1.5, "name": ""}`,
+		},
+		{
+			name:    "milliseconds",
+			unit:    time.Millisecond,
+			json:    `{"at": 1700000000123}`,
+			want:    time.UnixMilli(1700000000123),
+			wantOut: `{"at": 1700000000123, "name": ""}`,
+		},
+		{
+			name:    "fractional milliseconds",
+			unit:    time.Millisecond,
+			json:    `{"at": 1700000000123.5}`,
+			want:    time.Unix(1700000000, 123500000),
+			wantOut: `{"at": 1700000000123.5, "name": ""}`,
+		},
+		{
+			name:    "RFC 3339 string",
+			unit:    time.Second,
+			json:    `{"at": "2023-11-14T22:13:20Z"}`,
+			want:    time.Unix(1700000000, 0),
+			wantOut: `{"at": 1700000000, "name": ""}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				e Event
+				r Retain
+			)
+			r.Configure(WithUnixTime(tt.unit))
+
+			require.NoError(t, r.FromJSON([]byte(tt.json), &e))
+			assert.True(t, tt.want.Equal(e.At), "got %v, want %v", e.At, tt.want)
+
+			got, err := r.ToJSON(e)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.wantOut, string(got))
+		})
+	}
+}
+
+func TestWithUnixTime_Errors(t *testing.T) {
+	type Event struct {
+		At time.Time `json:"at"`
+	}
+
+	t.Run("out of range", func(t *testing.T) {
+		var r Retain
+		r.Configure(WithUnixTime(time.Second))
+		err := r.FromJSON([]byte(`{"at": 1e30}`), &Event{})
+		assert.EqualError(t, err, `field "at" (At): epoch time 1e30 out of range`)
+	})
+
+	t.Run("invalid unit", func(t *testing.T) {
+		assert.PanicsWithValue(t, "jsonobj: WithUnixTime requires time.Second or time.Millisecond, got 1m0s", func() {
+			WithUnixTime(time.Minute)
+		})
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		var r Retain
+		err := r.FromJSON([]byte(`{"at": 1}`), &Event{})
+		assert.Error(t, err)
+	})
+}
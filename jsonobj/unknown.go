@@ -0,0 +1,120 @@
+package jsonobj
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// GetUnknown returns the value of the retained field name.
+func (r *Retain) GetUnknown(name string) (json.RawMessage, bool) {
+	v, ok := r.raw[name]
+	return v, ok
+}
+
+// GetPath returns the retained value at path, following the keys of nested
+// objects starting from the retained field path[0]. For example,
+// GetPath("metadata", "labels", "env") returns the value of "env" in
+// {"metadata": {"labels": {"env": "prod"}}}.
+//
+// GetPath returns false if any key in path is missing, or if a value along
+// the path isn't an object. It only returns an error if a value along the
+// path is invalid JSON. Only the values along the path are decoded.
+func (r *Retain) GetPath(path ...string) (json.RawMessage, bool, error) {
+	if len(path) == 0 {
+		return nil, false, nil
+	}
+
+	v, ok := r.raw[path[0]]
+	if !ok {
+		return nil, false, nil
+	}
+	for i, key := range path[1:] {
+		trimmed := bytes.TrimSpace(v)
+		if len(trimmed) == 0 || trimmed[0] != '{' {
+			if !json.Valid(trimmed) {
+				return nil, false, fmt.Errorf("path %q: invalid JSON", strings.Join(path[:i+1], "."))
+			}
+			return nil, false, nil
+		}
+
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(trimmed, &obj); err != nil {
+			return nil, false, fmt.Errorf("path %q: %w", strings.Join(path[:i+1], "."), err)
+		}
+		if v, ok = obj[key]; !ok {
+			return nil, false, nil
+		}
+	}
+	return v, true, nil
+}
+
+// HasUnknown returns whether any fields are retained.
+func (r *Retain) HasUnknown() bool {
+	return len(r.raw) > 0
+}
+
+// Len returns the number of retained fields. After FromJSON, known fields
+// have been consumed, so only the unknown fields of the input are counted.
+func (r *Retain) Len() int {
+	return len(r.raw)
+}
+
+// Unknown returns a copy of all retained fields, or nil if there are none.
+// Modifying the returned map doesn't affect r.
+func (r *Retain) Unknown() map[string]json.RawMessage {
+	if len(r.raw) == 0 {
+		return nil
+	}
+	return cloneRawMap(r.raw)
+}
+
+// UnknownKeys returns the sorted names of all retained fields. It returns an
+// empty, non-nil slice if there are none.
+func (r *Retain) UnknownKeys() []string {
+	keys := make([]string, 0, len(r.raw))
+	for k := range r.raw {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SetUnknown sets the retained field name to value, which must be valid JSON.
+// An existing field keeps its position in the output, while a new field is
+// output after the fields of the input (see ToJSON).
+func (r *Retain) SetUnknown(name string, value json.RawMessage) {
+	if r.raw == nil {
+		r.raw = make(map[string]json.RawMessage)
+	}
+	r.addOrder(name)
+	r.raw[name] = value
+}
+
+// DeleteUnknown removes the retained field name, and returns whether it
+// was present.
+func (r *Retain) DeleteUnknown(name string) bool {
+	_, ok := r.raw[name]
+	delete(r.raw, name)
+	r.deleteOrder(name)
+	delete(r.stripped, name)
+	return ok
+}
+
+// Consume registers keys that the next call to FromJSON drops from the input,
+// so they're neither decoded into known fields nor retained. This allows
+// obj.UnmarshalJSON to handle some keys manually before calling FromJSON.
+func (r *Retain) Consume(keys ...string) {
+	if r.consumed == nil {
+		r.consumed = make(map[string]struct{}, len(keys))
+	}
+	for _, k := range keys {
+		r.consumed[k] = struct{}{}
+	}
+}
+
+func (r *Retain) resetConsumed() {
+	r.consumed = nil
+}
@@ -0,0 +1,203 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetain_Unknown(t *testing.T) {
+	var s S
+	require.NoError(t, json.Unmarshal([]byte(`{"name": "foo", "icon": "email"}`), &s))
+
+	_, ok := s.raw.GetUnknown("name")
+	assert.False(t, ok, "known fields are not retained")
+
+	icon, ok := s.raw.GetUnknown("icon")
+	assert.True(t, ok)
+	assert.Equal(t, `"email"`, string(icon))
+
+	s.raw.SetUnknown("color", json.RawMessage(`"red"`))
+	assert.True(t, s.raw.DeleteUnknown("icon"))
+	assert.False(t, s.raw.DeleteUnknown("icon"))
+	assert.JSONEq(t, `{"name": "foo", "color": "red"}`, mustMarshal(t, &s))
+}
+
+func TestRetain_SetUnknown_Zero(t *testing.T) {
+	var s S
+	assert.False(t, s.raw.DeleteUnknown("missing"))
+
+	s.raw.SetUnknown("num", json.RawMessage(`1`))
+	assert.JSONEq(t, `{"num": 1}`, mustMarshal(t, &s))
+}
+
+func TestRetain_UnknownCopy(t *testing.T) {
+	var s S
+	assert.Nil(t, s.raw.Unknown())
+
+	require.NoError(t, json.Unmarshal([]byte(`{"name": "foo", "icon": "email", "n": 1}`), &s))
+	got := s.raw.Unknown()
+	assert.Equal(t, map[string]json.RawMessage{
+		"icon": json.RawMessage(`"email"`),
+		"n":    json.RawMessage(`1`),
+	}, got)
+
+	got["icon"][1] = 'X'
+	got["added"] = json.RawMessage(`true`)
+	delete(got, "n")
+	assert.JSONEq(t, `{"name": "foo", "icon": "email", "n": 1}`, mustMarshal(t, &s),
+		"modifying the copy should not affect the Retain")
+}
+
+func TestRetain_SetDeleteUnknown_Order(t *testing.T) {
+	var (
+		r Retain
+		s S
+	)
+	r.Configure(WithStripPrefix("ext_"))
+	require.NoError(t, r.FromJSON([]byte(`{"ext_legacy_icon": "i", "a": 1, "name": "n", "b": 2}`), &s))
+
+	assert.True(t, r.DeleteUnknown("legacy_icon"))
+	r.SetUnknown("c", json.RawMessage(`3`))
+	r.SetUnknown("a", json.RawMessage(`4`))
+	r.SetUnknown("d", json.RawMessage(`5`))
+	assert.Equal(t, []string{"a", "name", "b", "c", "d"}, r.order,
+		"deleted fields should be removed from the order")
+
+	out, err := r.ToJSON(&s)
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":4,"name":"n","b":2,"c":3,"d":5}`, string(out))
+}
+
+func TestRetain_UnknownKeys(t *testing.T) {
+	var s S
+	assert.Equal(t, []string{}, s.raw.UnknownKeys())
+
+	require.NoError(t, json.Unmarshal([]byte(`{"name": "foo", "z": 1, "icon": "email", "a": 2}`), &s))
+	keys := s.raw.UnknownKeys()
+	assert.Equal(t, []string{"a", "icon", "z"}, keys)
+
+	keys[0] = "modified"
+	_, ok := s.raw.GetUnknown("a")
+	assert.True(t, ok, "modifying the keys should not affect the Retain")
+
+	s.raw.DeleteUnknown("icon")
+	assert.Equal(t, []string{"a", "z"}, s.raw.UnknownKeys())
+}
+
+type consumeS struct {
+	raw Retain
+
+	Name   string `json:"name"`
+	Legacy string `json:"legacy,omitempty"`
+
+	legacyID int
+}
+
+func (s *consumeS) UnmarshalJSON(data []byte) error {
+	var manual struct {
+		LegacyID int `json:"legacy_id"`
+	}
+	if err := json.Unmarshal(data, &manual); err != nil {
+		return err
+	}
+	s.legacyID = manual.LegacyID
+
+	s.raw.Consume("legacy_id", "legacy")
+	return s.raw.FromJSON(data, s)
+}
+
+func (s *consumeS) MarshalJSON() ([]byte, error) {
+	return s.raw.ToJSON(s)
+}
+
+func TestRetain_HasUnknownLen(t *testing.T) {
+	var s S
+	assert.False(t, s.raw.HasUnknown())
+	assert.Equal(t, 0, s.raw.Len())
+
+	require.NoError(t, json.Unmarshal([]byte(`{"name": "foo"}`), &s))
+	assert.False(t, s.raw.HasUnknown(), "known fields are not retained")
+	assert.Equal(t, 0, s.raw.Len())
+
+	require.NoError(t, json.Unmarshal([]byte(`{"name": "foo", "a": 1, "b": 2}`), &s))
+	assert.True(t, s.raw.HasUnknown())
+	assert.Equal(t, 2, s.raw.Len())
+
+	s.raw.SetUnknown("c", json.RawMessage(`3`))
+	assert.Equal(t, 3, s.raw.Len())
+	s.raw.DeleteUnknown("a")
+	assert.Equal(t, 2, s.raw.Len())
+}
+
+func TestRetain_GetPath(t *testing.T) {
+	var s S
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"name": "foo",
+		"metadata": {"labels": {"env": "prod"}, "list": [1, 2]},
+		"count": 3
+	}`), &s))
+	s.raw.SetUnknown("broken", json.RawMessage(`{"a": `))
+	s.raw.SetUnknown("brokenScalar", json.RawMessage(`tru`))
+
+	tests := []struct {
+		name    string
+		path    []string
+		want    string
+		wantOK  bool
+		wantErr string
+	}{
+		{name: "empty path"},
+		{name: "top-level", path: []string{"count"}, want: "3", wantOK: true},
+		{name: "nested object", path: []string{"metadata", "labels"}, want: `{"env": "prod"}`, wantOK: true},
+		{name: "nested value", path: []string{"metadata", "labels", "env"}, want: `"prod"`, wantOK: true},
+		{name: "known field", path: []string{"name"}},
+		{name: "missing top-level", path: []string{"missing"}},
+		{name: "missing nested", path: []string{"metadata", "labels", "missing"}},
+		{name: "through array", path: []string{"metadata", "list", "0"}},
+		{name: "through scalar", path: []string{"count", "a"}},
+		{name: "invalid object", path: []string{"broken", "a"}, wantErr: `path "broken": unexpected end of JSON input`},
+		{name: "invalid scalar", path: []string{"brokenScalar", "a"}, wantErr: `path "brokenScalar": invalid JSON`},
+		{name: "invalid value not walked", path: []string{"broken"}, want: `{"a": `, wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := s.raw.GetPath(tt.path...)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantErr, err.Error())
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantOK, ok)
+			if tt.wantOK {
+				assert.Equal(t, tt.want, string(got))
+			}
+		})
+	}
+}
+
+func TestRetain_Consume(t *testing.T) {
+	var s consumeS
+	input := `{"name": "foo", "legacy_id": 5, "legacy": "old", "other": 1}`
+	require.NoError(t, json.Unmarshal([]byte(input), &s))
+
+	assert.Equal(t, 5, s.legacyID)
+	assert.Empty(t, s.Legacy, "consumed keys should not be decoded")
+	assert.JSONEq(t, `{"name": "foo", "other": 1}`, mustMarshal(t, &s))
+
+	t.Run("only applies to one decode", func(t *testing.T) {
+		var r Retain
+		r.Consume("other")
+		require.NoError(t, r.FromJSON([]byte(`{"other": 1}`), &S{}))
+		require.NoError(t, r.FromJSON([]byte(`{"other": 2}`), &S{}))
+
+		other, ok := r.GetUnknown("other")
+		assert.True(t, ok)
+		assert.Equal(t, "2", string(other))
+	})
+}
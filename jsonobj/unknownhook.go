@@ -0,0 +1,52 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// UnknownFieldHook is called by FromJSON for each unknown field key, with
+// its value raw. It returns the value to retain (raw, to retain the field
+// unchanged), or an error to fail FromJSON.
+type UnknownFieldHook func(key string, raw json.RawMessage) (json.RawMessage, error)
+
+// WithUnknownFieldHook configures fn to validate or transform unknown fields
+// before they're retained, such as to normalize the format of a timestamp
+// that's not modelled by a known field yet.
+//
+// fn is only called for the unknown fields that remain after known fields
+// are decoded, and after unknown fields are filtered (see WithRetainOnly and
+// WithUnknownSampleRate) and renamed (see WithStripPrefix and
+// WithRetainedKeyMapping), so key is the name the field is retained as.
+// Fields are passed in sorted key order. fn must return valid JSON.
+func WithUnknownFieldHook(fn UnknownFieldHook) Option {
+	return func(o *options) {
+		o.unknownFieldHook = fn
+	}
+}
+
+// runUnknownFieldHook replaces retained values using the configured hook.
+func (r *Retain) runUnknownFieldHook() error {
+	if r.opts.unknownFieldHook == nil {
+		return nil
+	}
+
+	keys := make([]string, 0, len(r.raw))
+	for k := range r.raw {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		v, err := r.opts.unknownFieldHook(k, r.raw[k])
+		if err != nil {
+			return fmt.Errorf("unknown field %q: %w", k, err)
+		}
+		if !json.Valid(v) {
+			return fmt.Errorf("unknown field %q: hook returned invalid JSON", k)
+		}
+		r.raw[k] = v
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithUnknownFieldHook(t *testing.T) {
+	// Normalize RFC 1123 timestamps to RFC 3339.
+	normalize := func(raw json.RawMessage) (json.RawMessage, error) {
+		var s string
+		if json.Unmarshal(raw, &s) != nil {
+			return raw, nil
+		}
+		ts, err := time.Parse(time.RFC1123, s)
+		if err != nil {
+			return raw, nil
+		}
+		return json.Marshal(ts.UTC().Format(time.RFC3339))
+	}
+
+	var (
+		s    S
+		keys []string
+	)
+	s.raw.Configure(WithUnknownFieldHook(func(key string, raw json.RawMessage) (json.RawMessage, error) {
+		keys = append(keys, key)
+		return normalize(raw)
+	}))
+	require.NoError(t, s.raw.FromJSON([]byte(`{"name": "Mon, 02 Jan 2006 15:04:05 UTC", "z": 1, "seen": "Mon, 02 Jan 2006 15:04:05 UTC"}`), &s))
+
+	assert.Equal(t, []string{"seen", "z"}, keys, "hook should only be called for unknown fields, in sorted order")
+	assert.Equal(t, "Mon, 02 Jan 2006 15:04:05 UTC", s.Name, "known fields should not be transformed")
+
+	got, err := s.raw.ToJSON(&s)
+	require.NoError(t, err)
+	assert.Equal(t, `{"name":"Mon, 02 Jan 2006 15:04:05 UTC","z":1,"seen":"2006-01-02T15:04:05Z"}`, string(got))
+}
+
+func TestWithUnknownFieldHook_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		hook    UnknownFieldHook
+		wantErr string
+	}{
+		{
+			name: "hook error",
+			hook: func(key string, raw json.RawMessage) (json.RawMessage, error) {
+				return nil, errors.New("not allowed")
+			},
+			wantErr: `unknown field "x": not allowed`,
+		},
+		{
+			name: "invalid JSON",
+			hook: func(key string, raw json.RawMessage) (json.RawMessage, error) {
+				return json.RawMessage(`{`), nil
+			},
+			wantErr: `unknown field "x": hook returned invalid JSON`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s S
+			err := s.raw.FromJSON([]byte(`{"name": "foo", "x": 1}`), &s, WithUnknownFieldHook(tt.hook))
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
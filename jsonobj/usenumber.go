@@ -0,0 +1,43 @@
+package jsonobj
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// WithUseNumber makes FromJSON decode numbers in known fields of interface
+// type (such as any or map[string]any) as json.Number rather than float64,
+// as json.Decoder.UseNumber does. This avoids precision loss for large
+// integers, such as 64-bit IDs.
+//
+// Retained fields are stored as the original JSON, so their numbers are
+// always output by ToJSON without loss, regardless of this option.
+func WithUseNumber() Option {
+	return func(o *options) {
+		o.useNumber = true
+	}
+}
+
+// unmarshal decodes the JSON value data into v using the configured codec,
+// respecting WithUseNumber.
+func (r *Retain) unmarshal(data []byte, v any) error {
+	if r.opts.codec != nil {
+		return r.opts.codec.Unmarshal(data, v)
+	}
+	if !r.opts.useNumber {
+		return json.Unmarshal(data, v)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		// Match json.Unmarshal, which rejects data after the value.
+		return errors.New("invalid data after top-level value")
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithUseNumber(t *testing.T) {
+	type Obj struct {
+		Name  string         `json:"name"`
+		Any   any            `json:"any"`
+		Attrs map[string]any `json:"attrs"`
+		Count int64          `json:"count"`
+	}
+
+	const input = `{"name":"n","any":1234567890123456789,"attrs":{"id":9223372036854775807},"count":1234567890123456789,"unknown_id":1234567890123456789}`
+
+	tests := []struct {
+		name      string
+		opts      []Option
+		wantAny   any
+		wantAttrs map[string]any
+	}{
+		{
+			name:      "default",
+			wantAny:   float64(1234567890123456789),
+			wantAttrs: map[string]any{"id": float64(9223372036854775807)},
+		},
+		{
+			name:      "use number",
+			opts:      []Option{WithUseNumber()},
+			wantAny:   json.Number("1234567890123456789"),
+			wantAttrs: map[string]any{"id": json.Number("9223372036854775807")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				r   Retain
+				obj Obj
+			)
+			r.Configure(tt.opts...)
+			require.NoError(t, r.FromJSON([]byte(input), &obj))
+			assert.Equal(t, tt.wantAny, obj.Any)
+			assert.Equal(t, tt.wantAttrs, obj.Attrs)
+			assert.Equal(t, int64(1234567890123456789), obj.Count)
+
+			unknown, ok := r.GetUnknown("unknown_id")
+			require.True(t, ok)
+			assert.Equal(t, "1234567890123456789", string(unknown))
+
+			out, err := r.ToJSON(obj)
+			require.NoError(t, err)
+			if tt.opts != nil {
+				assert.Equal(t, input, string(out), "should round-trip byte-for-byte")
+			} else {
+				assert.Contains(t, string(out), `"unknown_id":1234567890123456789`)
+			}
+		})
+	}
+}
+
+func TestWithUseNumber_TrailingData(t *testing.T) {
+	r := Retain{opts: options{useNumber: true}}
+
+	var v any
+	err := r.unmarshal([]byte(`1 2`), &v)
+	assert.EqualError(t, err, "invalid data after top-level value")
+}
@@ -0,0 +1,65 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// ValidationError is a failed validation for a single field.
+type ValidationError struct {
+	// Field is the JSON name of the field.
+	Field string
+
+	// Message describes why validation failed.
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("field %q: %v", e.Field, e.Message)
+}
+
+// RegisterValidator registers fn to validate the field jsonName, which may be
+// a known or retained field. fn is called with the field's JSON value, or nil
+// if the field is absent (so it can check required fields).
+// Multiple validators may be registered for the same field.
+func (r *Retain) RegisterValidator(jsonName string, fn func(value json.RawMessage) error) {
+	if r.opts.validators == nil {
+		r.opts.validators = make(map[string][]func(json.RawMessage) error)
+	}
+	r.opts.validators[jsonName] = append(r.opts.validators[jsonName], fn)
+}
+
+// Validate runs all registered validators against the fields of obj (as
+// marshalled by ToJSON), and returns all validation errors sorted by field.
+// Errors marshalling obj are reported as a ValidationError with no Field.
+func (r *Retain) Validate(obj any) []ValidationError {
+	data, err := r.ToJSON(obj)
+	if err != nil {
+		return []ValidationError{{Message: err.Error()}}
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return []ValidationError{{Message: err.Error()}}
+	}
+
+	names := make([]string, 0, len(r.opts.validators))
+	for name := range r.opts.validators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []ValidationError
+	for _, name := range names {
+		for _, fn := range r.opts.validators[name] {
+			if err := fn(fields[name]); err != nil {
+				errs = append(errs, ValidationError{
+					Field:   name,
+					Message: err.Error(),
+				})
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,79 @@
+package jsonobj
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetain_Validate(t *testing.T) {
+	required := func(v json.RawMessage) error {
+		if v == nil {
+			return errors.New("required")
+		}
+		return nil
+	}
+	nonEmptyString := func(v json.RawMessage) error {
+		var s string
+		if v != nil && (json.Unmarshal(v, &s) != nil || s == "") {
+			return errors.New("must be a non-empty string")
+		}
+		return nil
+	}
+
+	newS := func(input string) *S {
+		var s S
+		s.raw.RegisterValidator("name", required)
+		s.raw.RegisterValidator("name", nonEmptyString)
+		s.raw.RegisterValidator("email", required)
+		s.raw.RegisterValidator("email", nonEmptyString)
+		s.raw.RegisterValidator("nickname", nonEmptyString)
+		require.NoError(t, json.Unmarshal([]byte(input), &s))
+		return &s
+	}
+
+	tests := []struct {
+		name string
+		json string
+		want []ValidationError
+	}{
+		{
+			name: "valid",
+			json: `{"name": "foo", "email": "foo@example.com"}`,
+		},
+		{
+			name: "all errors reported",
+			json: `{"nickname": 1}`,
+			want: []ValidationError{
+				{Field: "email", Message: "required"},
+				{Field: "name", Message: "required"},
+				{Field: "nickname", Message: "must be a non-empty string"},
+			},
+		},
+		{
+			name: "retained field",
+			json: `{"name": "foo", "email": ""}`,
+			want: []ValidationError{
+				{Field: "email", Message: "must be a non-empty string"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newS(tt.json)
+			assert.Equal(t, tt.want, s.raw.Validate(s))
+		})
+	}
+}
+
+func TestRetain_Validate_MarshalError(t *testing.T) {
+	var r Retain
+	errs := r.Validate("str")
+	require.Len(t, errs, 1)
+	assert.Equal(t, "ToJSON requires a struct, got string", errs[0].Message)
+	assert.Equal(t, `field "": ToJSON requires a struct, got string`, errs[0].Error())
+}
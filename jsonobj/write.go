@@ -0,0 +1,50 @@
+package jsonobj
+
+import (
+	"bufio"
+	"io"
+)
+
+// WriteJSON writes the same output as ToJSON to w, without buffering the
+// whole output, such as for writing large objects to an http.ResponseWriter.
+// As with ToJSON, the output only ends with a newline if WithTrailingNewline
+// is used, unlike json.Encoder.Encode.
+//
+// Known fields are marshalled one at a time, and retained fields are written
+// as ToJSON writes them. Options that process the whole output (WithEnvelopeKey,
+// WithOutputSchema and WithIndent) require buffering, so the output of ToJSON
+// is written instead.
+//
+// If marshalling fails, partial output may have been written to w.
+//
+// opts override the configured options for this call only, see Configure.
+func (r *Retain) WriteJSON(w io.Writer, obj any, opts ...Option) error {
+	if len(opts) > 0 {
+		rc := *r
+		rc.opts = r.opts.with(opts)
+		return rc.WriteJSON(w, obj)
+	}
+
+	if r.opts.envelopeKey != "" || r.opts.outputSchema != nil || r.opts.outputSchemaErr != nil || r.opts.indent != nil {
+		data, err := r.ToJSON(obj)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+
+	keys, all, err := r.outputFields("WriteJSON", obj)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	if err := r.writeOrdered(bw, keys, all); err != nil {
+		return err
+	}
+	if r.opts.trailingNewline {
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
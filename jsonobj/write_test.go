@@ -0,0 +1,57 @@
+package jsonobj
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteJSON(t *testing.T) {
+	const input = `{"z": 1, "name": "foo", "obj": {"b": [1, 2], "a": "<&>"}}`
+
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "default"},
+		{name: "trailing newline", opts: []Option{WithTrailingNewline()}},
+		{name: "hash order", opts: []Option{WithHashOrder()}},
+		{name: "no HTML escaping", opts: []Option{WithEscapeHTML(false)}},
+		{name: "indent", opts: []Option{WithIndent("", "  ")}},
+		{name: "envelope", opts: []Option{WithEnvelopeKey("data")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s S
+			require.NoError(t, s.raw.FromJSON([]byte(input), &s))
+			s.Name = "<b>"
+
+			want, err := s.raw.ToJSON(&s, tt.opts...)
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			require.NoError(t, s.raw.WriteJSON(&buf, &s, tt.opts...))
+			assert.Equal(t, string(want), buf.String())
+		})
+	}
+}
+
+type errWriter struct{}
+
+func (errWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestWriteJSON_Errors(t *testing.T) {
+	var s S
+	require.NoError(t, s.raw.FromJSON([]byte(`{"name": "foo"}`), &s))
+
+	assert.EqualError(t, s.raw.WriteJSON(&bytes.Buffer{}, "str"), "WriteJSON requires a struct, got string")
+	assert.EqualError(t, s.raw.WriteJSON(&bytes.Buffer{}, (*S)(nil)), "WriteJSON requires a non-nil struct")
+	assert.EqualError(t, s.raw.WriteJSON(errWriter{}, &s), "write failed")
+	assert.EqualError(t, s.raw.WriteJSON(errWriter{}, &s, WithIndent("", " ")), "write failed")
+}
@@ -0,0 +1,300 @@
+//go:build jsonobj_yaml
+
+package jsonobj
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FromYAML is similar to FromJSON, but decodes a YAML mapping. Fields of obj
+// use the name in their `yaml` tag, falling back to their JSON name. The YAML
+// is converted to JSON and decoded using FromJSON, so unknown fields are
+// retained as JSON, and can be output by either ToJSON or ToYAML.
+//
+// Only the names of the fields of obj use `yaml` tags: nested values are
+// decoded using encoding/json, so they use `json` tags.
+//
+// FromYAML requires the jsonobj_yaml build tag, so users that only need JSON
+// don't depend on a YAML library.
+func (r *Retain) FromYAML(data []byte, obj any) error {
+	rv, ok := ensureStruct(obj, true /* requirePtr */)
+	if !ok {
+		return errors.New("FromYAML requires a struct pointer")
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+
+	node := &doc
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("FromYAML requires a YAML mapping, got %v", yamlKindName(node))
+	}
+
+	jsonNames := yamlToJSONNames(rv.Type(), r.tagKey())
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		key, err := yamlKey(node.Content[i])
+		if err != nil {
+			return err
+		}
+		if name, ok := jsonNames[key]; ok {
+			key = name
+		}
+		writeJSONString(&buf, key)
+		buf.WriteByte(':')
+
+		if err := appendYAMLAsJSON(&buf, node.Content[i+1]); err != nil {
+			return fmt.Errorf("YAML key %q: %w", key, err)
+		}
+	}
+	buf.WriteByte('}')
+
+	return r.FromJSON(buf.Bytes(), obj)
+}
+
+// ToYAML is similar to ToJSON, but outputs a YAML mapping. Fields of obj use
+// the name in their `yaml` tag, falling back to their JSON name, and keys are
+// in the same order as ToJSON.
+//
+// ToYAML requires the jsonobj_yaml build tag.
+func (r *Retain) ToYAML(obj any) ([]byte, error) {
+	rv, ok := ensureStruct(obj, false /* requirePtr */)
+	if !ok {
+		return nil, errors.New("ToYAML requires a struct")
+	}
+
+	// Indentation is ignored, since the JSON is only used to build the YAML.
+	rc := *r
+	rc.opts.indent = nil
+	rc.opts.trailingNewline = false
+	data, err := rc.ToJSON(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	node, err := jsonToYAMLNode(dec)
+	if err != nil {
+		return nil, err
+	}
+
+	yamlNames := jsonToYAMLNames(rv.Type(), r.tagKey())
+	for i := 0; i < len(node.Content); i += 2 {
+		if name, ok := yamlNames[node.Content[i].Value]; ok {
+			node.Content[i].Value = name
+		}
+	}
+
+	return yaml.Marshal(node)
+}
+
+// yamlName returns the name of the field in YAML, and whether it differs
+// from the JSON name.
+func yamlName(t jsonTag) (string, bool) {
+	name, _, _ := strings.Cut(t.field.Tag.Get("yaml"), ",")
+	if name == "" || name == "-" || name == t.name() {
+		return t.name(), false
+	}
+	return name, true
+}
+
+// yamlToJSONNames returns the JSON names of the fields of the struct type rt
+// that have different YAML names, keyed by the YAML name.
+func yamlToJSONNames(rt reflect.Type, tagKey string) map[string]string {
+	names := make(map[string]string)
+	for _, t := range cachedFields(rt, tagKey).dominant {
+		if name, ok := yamlName(t); ok {
+			names[name] = t.name()
+		}
+	}
+	return names
+}
+
+// jsonToYAMLNames is the inverse of yamlToJSONNames.
+func jsonToYAMLNames(rt reflect.Type, tagKey string) map[string]string {
+	names := make(map[string]string)
+	for _, t := range cachedFields(rt, tagKey).dominant {
+		if name, ok := yamlName(t); ok {
+			names[t.name()] = name
+		}
+	}
+	return names
+}
+
+// yamlKey returns the mapping key node as a string.
+func yamlKey(n *yaml.Node) (string, error) {
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	if n.Kind != yaml.ScalarNode {
+		return "", fmt.Errorf("YAML mapping key must be a scalar, got %v", yamlKindName(n))
+	}
+	return n.Value, nil
+}
+
+// appendYAMLAsJSON appends the YAML node n to buf as JSON, preserving the
+// order of mapping keys and the text of numbers.
+func appendYAMLAsJSON(buf *bytes.Buffer, n *yaml.Node) error {
+	switch n.Kind {
+	case yaml.AliasNode:
+		return appendYAMLAsJSON(buf, n.Alias)
+
+	case yaml.MappingNode:
+		buf.WriteByte('{')
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, err := yamlKey(n.Content[i])
+			if err != nil {
+				return err
+			}
+			writeJSONString(buf, key)
+			buf.WriteByte(':')
+			if err := appendYAMLAsJSON(buf, n.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+		return nil
+
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, c := range n.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := appendYAMLAsJSON(buf, c); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+
+	case yaml.ScalarNode:
+		return appendYAMLScalar(buf, n)
+
+	default:
+		return fmt.Errorf("unsupported YAML %v", yamlKindName(n))
+	}
+}
+
+func appendYAMLScalar(buf *bytes.Buffer, n *yaml.Node) error {
+	switch n.ShortTag() {
+	case "!!str":
+		writeJSONString(buf, n.Value)
+		return nil
+	case "!!null":
+		buf.WriteString("null")
+		return nil
+	case "!!int", "!!float":
+		if json.Valid([]byte(n.Value)) {
+			// Keep the original text, so large numbers aren't rounded.
+			buf.WriteString(n.Value)
+			return nil
+		}
+	}
+
+	// Other scalars (such as booleans and hex integers) are decoded by
+	// the YAML library, and marshalled as JSON.
+	var v any
+	if err := n.Decode(&v); err != nil {
+		return err
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	buf.Write(data)
+	return nil
+}
+
+// jsonToYAMLNode reads the next JSON value from dec as a YAML node,
+// preserving the order of object keys and the text of numbers.
+func jsonToYAMLNode(dec *json.Decoder) (*yaml.Node, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok := tok.(type) {
+	case json.Delim:
+		n := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		if tok == '[' {
+			n.Kind, n.Tag = yaml.SequenceNode, "!!seq"
+		}
+		for dec.More() {
+			if n.Kind == yaml.MappingNode {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: keyTok.(string)})
+			}
+			c, err := jsonToYAMLNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			n.Content = append(n.Content, c)
+		}
+		// Consume the closing delimiter.
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return n, nil
+	case string:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: tok}, nil
+	case json.Number:
+		// The tag is resolved from the value, so numbers that don't fit
+		// in an int64 aren't output with an explicit tag.
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: tok.String()}, nil
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(tok)}, nil
+	case nil:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	default:
+		return nil, fmt.Errorf("unexpected JSON token %v", tok)
+	}
+}
+
+func writeJSONString(buf *bytes.Buffer, s string) {
+	// Marshalling a string can't fail.
+	data, _ := json.Marshal(s)
+	buf.Write(data)
+}
+
+func yamlKindName(n *yaml.Node) string {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		return "document"
+	case yaml.SequenceNode:
+		return "sequence"
+	case yaml.MappingNode:
+		return "mapping"
+	case yaml.ScalarNode:
+		return "scalar"
+	case yaml.AliasNode:
+		return "alias"
+	default:
+		return "empty document"
+	}
+}
@@ -0,0 +1,137 @@
+//go:build jsonobj_yaml
+
+package jsonobj
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type yamlS struct {
+	raw Retain
+
+	Name   string `json:"name"`
+	UserID int64  `json:"user_id" yaml:"userId"`
+	Tags   []string
+}
+
+func TestFromYAML(t *testing.T) {
+	const input = `name: foo
+userId: 9007199254740993
+Tags: [a, b]
+extra:
+  nested: true
+  list:
+    - 1
+    - 1.5
+    - 0x10
+    - null
+    - "str"
+big: 123456789012345678901234567890
+`
+
+	var (
+		r   Retain
+		obj yamlS
+	)
+	require.NoError(t, r.FromYAML([]byte(input), &obj))
+	assert.Equal(t, "foo", obj.Name)
+	assert.Equal(t, int64(9007199254740993), obj.UserID)
+	assert.Equal(t, []string{"a", "b"}, obj.Tags)
+	assert.Equal(t, []string{"big", "extra"}, r.UnknownKeys())
+
+	gotJSON, err := r.ToJSON(obj)
+	require.NoError(t, err)
+	assert.Equal(t, `{"name":"foo","user_id":9007199254740993,"Tags":["a","b"],"extra":{"nested":true,"list":[1,1.5,16,null,"str"]},"big":123456789012345678901234567890}`, string(gotJSON))
+
+	gotYAML, err := r.ToYAML(obj)
+	require.NoError(t, err)
+	assert.Equal(t, `name: foo
+userId: 9007199254740993
+Tags:
+    - a
+    - b
+extra:
+    nested: true
+    list:
+        - 1
+        - 1.5
+        - 16
+        - null
+        - str
+big: 123456789012345678901234567890
+`, string(gotYAML))
+}
+
+func TestYAML_JSONRoundTrip(t *testing.T) {
+	var (
+		r   Retain
+		obj yamlS
+	)
+	require.NoError(t, r.FromJSON([]byte(`{"user_id": 1, "name": "foo", "x": {"y": [true]}}`), &obj))
+
+	gotYAML, err := r.ToYAML(obj)
+	require.NoError(t, err)
+	assert.Equal(t, "userId: 1\nname: foo\nx:\n    y:\n        - true\nTags: null\n", string(gotYAML))
+
+	var (
+		r2   Retain
+		obj2 yamlS
+	)
+	require.NoError(t, r2.FromYAML(gotYAML, &obj2))
+	gotJSON, err := r2.ToJSON(obj2)
+	require.NoError(t, err)
+	assert.Equal(t, `{"user_id":1,"name":"foo","x":{"y":[true]},"Tags":null}`, string(gotJSON))
+}
+
+func TestFromYAML_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{
+			name:    "invalid YAML",
+			yaml:    "a: [",
+			wantErr: "yaml:",
+		},
+		{
+			name:    "not a mapping",
+			yaml:    "- a",
+			wantErr: "FromYAML requires a YAML mapping, got sequence",
+		},
+		{
+			name:    "empty",
+			yaml:    "",
+			wantErr: "FromYAML requires a YAML mapping, got empty document",
+		},
+		{
+			name:    "non-scalar key",
+			yaml:    "? [a]\n: b",
+			wantErr: "YAML mapping key must be a scalar, got sequence",
+		},
+		{
+			name:    "invalid JSON value",
+			yaml:    "a: .inf",
+			wantErr: `YAML key "a": json: unsupported value: +Inf`,
+		},
+		{
+			name:    "known field type mismatch",
+			yaml:    "userId: foo",
+			wantErr: "cannot unmarshal string",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				r   Retain
+				obj yamlS
+			)
+			err := r.FromYAML([]byte(tt.yaml), &obj)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}